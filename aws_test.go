@@ -8,13 +8,22 @@ import (
 
 	"github.com/karuppiah7890/tce-e2e-test/testutils/aws"
 	"github.com/karuppiah7890/tce-e2e-test/testutils/log"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/scenario"
 )
 
 func TestAwsManagementAndWorkloadCluster(t *testing.T) {
-	log.InitLogger("aws-mgmt-wkld-e2e")
+	logger := log.NewTestLogger(t, "aws-mgmt-wkld-e2e")
 
-	r := utils.DefaultClusterTestRunner{}
-	err := utils.RunProviderTest(aws.PROVIDER, r, tce.Package{})
+	ctx, cancel := utils.ContextForTest(t, utils.DefaultCleanupMargin)
+	defer cancel()
+
+	testScenario, err := scenario.FromEnv()
+	if err != nil {
+		t.Fatalf("Error while reading the test scenario: %v", err)
+	}
+
+	r := utils.DefaultClusterTestRunner{Logger: logger, Scenario: testScenario}
+	err = utils.RunProviderTest(ctx, aws.PROVIDER, r, tce.Package{})
 	if err != nil {
 		t.Errorf("Error while running E2E test for managed and workload cluster on AWS: %v", err)
 	}
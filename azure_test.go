@@ -5,15 +5,24 @@ import (
 
 	"github.com/karuppiah7890/tce-e2e-test/testutils/azure"
 	"github.com/karuppiah7890/tce-e2e-test/testutils/log"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/scenario"
 	"github.com/karuppiah7890/tce-e2e-test/testutils/tce"
 	"github.com/karuppiah7890/tce-e2e-test/testutils/utils"
 )
 
 func TestAzureManagementAndWorkloadCluster(t *testing.T) {
-	log.InitLogger("azure-mgmt-wkld-e2e")
+	logger := log.NewTestLogger(t, "azure-mgmt-wkld-e2e")
 
-	r := utils.DefaultClusterTestRunner{}
-	err := utils.RunProviderTest(azure.PROVIDER, r, tce.Package{})
+	ctx, cancel := utils.ContextForTest(t, utils.DefaultCleanupMargin)
+	defer cancel()
+
+	testScenario, err := scenario.FromEnv()
+	if err != nil {
+		t.Fatalf("Error while reading the test scenario: %v", err)
+	}
+
+	r := utils.DefaultClusterTestRunner{Logger: logger, Scenario: testScenario}
+	err = utils.RunProviderTest(ctx, azure.PROVIDER, r, tce.Package{})
 	if err != nil {
 		t.Errorf("Error while running E2E test for managed and workload cluster on Azure: %v", err)
 	}
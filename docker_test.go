@@ -8,11 +8,23 @@ import (
 	"github.com/karuppiah7890/tce-e2e-test/testutils/utils"
 
 	"github.com/karuppiah7890/tce-e2e-test/testutils/log"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/scenario"
 )
 
 func TestDockerManagementAndWorkloadCluster(t *testing.T) {
-	log.InitLogger("docker-mgmt-wkld-e2e")
+	logger := log.NewTestLogger(t, "docker-mgmt-wkld-e2e")
 
-	r := utils.DefaultClusterTestRunner{}
-	utils.RunProviderTest(dockerprovider.PROVIDER, r, tce.Package{})
+	ctx, cancel := utils.ContextForTest(t, utils.DefaultCleanupMargin)
+	defer cancel()
+
+	testScenario, err := scenario.FromEnv()
+	if err != nil {
+		t.Fatalf("Error while reading the test scenario: %v", err)
+	}
+
+	r := utils.DefaultClusterTestRunner{Logger: logger, Scenario: testScenario}
+	err = utils.RunProviderTest(ctx, dockerprovider.PROVIDER, r, tce.Package{})
+	if err != nil {
+		t.Errorf("Error while running E2E test for managed and workload cluster on Docker: %v", err)
+	}
 }
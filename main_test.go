@@ -0,0 +1,15 @@
+package e2e
+
+import (
+	"os"
+	"testing"
+
+	"github.com/karuppiah7890/tce-e2e-test/testutils/log"
+)
+
+// TestMain initializes the global logger for the code that's not given a test's logger,
+// as each test logs to its own log file using a logger created for the test
+func TestMain(m *testing.M) {
+	log.InitLogger("e2e")
+	os.Exit(m.Run())
+}
@@ -0,0 +1,71 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+)
+
+// CloudFormationStackName is the name of the CloudFormation stack with the IAM resources needed by
+// the clusters, which is created by `tanzu management-cluster permissions aws set`
+const CloudFormationStackName = "tkg-cloud-formation"
+
+func newCloudFormationClient(region string) (*cloudformation.CloudFormation, error) {
+	awsSession, err := session.NewSession(&aws.Config{
+		Region: aws.String(region),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error while creating AWS session: %v", err)
+	}
+	return cloudformation.New(awsSession), nil
+}
+
+// cloudFormationStackExists checks if the CloudFormation stack exists in the region
+func cloudFormationStackExists(ctx context.Context, region string, stackName string) (bool, error) {
+	client, err := newCloudFormationClient(region)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = client.DescribeStacksWithContext(ctx, &cloudformation.DescribeStacksInput{
+		StackName: aws.String(stackName),
+	})
+	if err != nil {
+		// CloudFormation returns a validation error for stacks that don't exist
+		if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == "ValidationError" && strings.Contains(awsErr.Message(), "does not exist") {
+			return false, nil
+		}
+		return false, fmt.Errorf("error while describing CloudFormation stack %s: %v", stackName, err)
+	}
+
+	return true, nil
+}
+
+// deleteCloudFormationStack deletes the CloudFormation stack in the region and waits for the deletion to complete
+func deleteCloudFormationStack(ctx context.Context, region string, stackName string) error {
+	client, err := newCloudFormationClient(region)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.DeleteStackWithContext(ctx, &cloudformation.DeleteStackInput{
+		StackName: aws.String(stackName),
+	})
+	if err != nil {
+		return fmt.Errorf("error while deleting CloudFormation stack %s: %v", stackName, err)
+	}
+
+	err = client.WaitUntilStackDeleteCompleteWithContext(ctx, &cloudformation.DescribeStacksInput{
+		StackName: aws.String(stackName),
+	})
+	if err != nil {
+		return fmt.Errorf("error while waiting for CloudFormation stack %s to be deleted: %v", stackName, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,467 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/aws/aws-sdk-go/service/elb"
+	"github.com/aws/aws-sdk-go/service/elb/elbiface"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/log"
+)
+
+// ownedTagValue is the value of the cluster ownership tag on the resources created by CAPA (Cluster API Provider
+// AWS) for the cluster. Resources brought by the user, like an existing VPC, have the "shared" value instead
+const ownedTagValue = "owned"
+
+// Defaults for the retries of the deletion of a resource
+const (
+	defaultDeleteAttempts      = 20
+	defaultDeleteRetryInterval = 15 * time.Second
+)
+
+// describeTagsBatchSize is the maximum number of load balancers whose tags can be described at once
+const describeTagsBatchSize = 20
+
+// ClusterOwnershipTagKey returns the key of the tag CAPA puts on the AWS resources of the cluster
+func ClusterOwnershipTagKey(clusterName string) string {
+	return fmt.Sprintf("sigs.k8s.io/cluster-api-provider-aws/cluster/%s", clusterName)
+}
+
+// ClusterResource is an AWS resource of a cluster, like the instance i-0123456789abcdef0
+type ClusterResource struct {
+	Kind string
+	ID   string
+}
+
+func (resource ClusterResource) String() string {
+	return fmt.Sprintf("%s %s", resource.Kind, resource.ID)
+}
+
+// ClusterCleaner deletes the AWS resources created by CAPA for a cluster - the ones with the cluster ownership tag
+// set to owned - like the ones left behind by a failed cluster creation or deletion
+type ClusterCleaner struct {
+	EC2    ec2iface.EC2API
+	ELB    elbiface.ELBAPI
+	Logger *log.Logger
+	// DryRun only lists the resources which would be deleted
+	DryRun bool
+	// DeleteAttempts is the number of times the deletion of a resource is tried. Resources can't be deleted
+	// until the resources which depend on them are gone, which AWS does asynchronously for some of them, like
+	// the network interfaces of a NAT gateway
+	DeleteAttempts int
+	// DeleteRetryInterval is the time waited between the attempts to delete a resource
+	DeleteRetryInterval time.Duration
+}
+
+// NewClusterCleaner returns a cluster cleaner for the region
+func NewClusterCleaner(region string, logger *log.Logger) (*ClusterCleaner, error) {
+	awsSession, err := session.NewSession(&aws.Config{
+		Region: aws.String(region),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error while creating AWS session: %v", err)
+	}
+	return &ClusterCleaner{
+		EC2:    ec2.New(awsSession),
+		ELB:    elb.New(awsSession),
+		Logger: logger,
+	}, nil
+}
+
+// resourceKind is a kind of AWS resource of a cluster, along with how to find and delete the resources of the kind
+type resourceKind struct {
+	name string
+	// list returns the IDs of the resources of the kind owned by the cluster
+	list func(ctx context.Context, clusterName string) ([]string, error)
+	// prepare, when set, runs once with all the resources of the kind before they are deleted, like terminating
+	// all the instances together instead of one after the other
+	prepare func(ctx context.Context, ids []string) error
+	// delete deletes a resource of the kind
+	delete func(ctx context.Context, id string) error
+}
+
+// resourceKinds returns the kinds of AWS resources of a cluster in the order they are deleted, which is the
+// reverse of the order they depend on each other
+func (cleaner *ClusterCleaner) resourceKinds() []resourceKind {
+	return []resourceKind{
+		{name: "load balancer", list: cleaner.listLoadBalancers, delete: cleaner.deleteLoadBalancer},
+		{name: "instance", list: cleaner.listInstances, prepare: cleaner.terminateInstances, delete: cleaner.waitForInstanceTermination},
+		{name: "NAT gateway", list: cleaner.listNatGateways, delete: cleaner.deleteNatGateway},
+		{name: "elastic IP", list: cleaner.listAddresses, delete: cleaner.releaseAddress},
+		{name: "internet gateway", list: cleaner.listInternetGateways, delete: cleaner.deleteInternetGateway},
+		{name: "security group", list: cleaner.listSecurityGroups, prepare: cleaner.revokeSecurityGroupIngress, delete: cleaner.deleteSecurityGroup},
+		{name: "subnet", list: cleaner.listSubnets, delete: cleaner.deleteSubnet},
+		{name: "route table", list: cleaner.listRouteTables, delete: cleaner.deleteRouteTable},
+		{name: "VPC", list: cleaner.listVpcs, delete: cleaner.deleteVpc},
+	}
+}
+
+// Cleanup deletes the AWS resources owned by the cluster in dependency order and returns the deleted resources.
+// In dry run mode it only returns the resources which would be deleted. It keeps going when a resource can't be
+// deleted and returns an error with all the resources it could not delete
+func (cleaner *ClusterCleaner) Cleanup(ctx context.Context, clusterName string) ([]ClusterResource, error) {
+	resources := []ClusterResource{}
+	errs := []string{}
+
+	for _, kind := range cleaner.resourceKinds() {
+		ids, err := kind.list(ctx, clusterName)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("error while listing %ss: %v", kind.name, err))
+			continue
+		}
+		if len(ids) == 0 {
+			continue
+		}
+
+		if cleaner.DryRun {
+			for _, id := range ids {
+				cleaner.Logger.Infof("Would delete %s %s of cluster %s", kind.name, id, clusterName)
+				resources = append(resources, ClusterResource{Kind: kind.name, ID: id})
+			}
+			continue
+		}
+
+		if kind.prepare != nil {
+			err := kind.prepare(ctx, ids)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("error while deleting %ss %s: %v", kind.name, strings.Join(ids, ", "), err))
+				continue
+			}
+		}
+
+		for _, id := range ids {
+			cleaner.Logger.Infof("Deleting %s %s of cluster %s", kind.name, id, clusterName)
+			err := cleaner.retry(ctx, func() error {
+				return kind.delete(ctx, id)
+			})
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("error while deleting %s %s: %v", kind.name, id, err))
+				continue
+			}
+			resources = append(resources, ClusterResource{Kind: kind.name, ID: id})
+		}
+	}
+
+	if len(errs) != 0 {
+		return resources, fmt.Errorf("errors while deleting AWS resources of cluster %s: %s", clusterName, strings.Join(errs, "; "))
+	}
+	return resources, nil
+}
+
+// retry runs the function until it succeeds, the attempts run out or the context is done
+func (cleaner *ClusterCleaner) retry(ctx context.Context, f func() error) error {
+	attempts := cleaner.DeleteAttempts
+	if attempts <= 0 {
+		attempts = defaultDeleteAttempts
+	}
+	interval := cleaner.DeleteRetryInterval
+	if interval <= 0 {
+		interval = defaultDeleteRetryInterval
+	}
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		err = f()
+		if err == nil {
+			return nil
+		}
+		if attempt == attempts {
+			break
+		}
+
+		cleaner.Logger.Infof("Attempt %d of %d failed, retrying in %s: %v", attempt, attempts, interval, err)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%v, and stopped retrying: %v", err, ctx.Err())
+		case <-time.After(interval):
+		}
+	}
+	return err
+}
+
+func ownedFilters(clusterName string, filters ...*ec2.Filter) []*ec2.Filter {
+	return append([]*ec2.Filter{
+		{
+			Name:   aws.String("tag:" + ClusterOwnershipTagKey(clusterName)),
+			Values: aws.StringSlice([]string{ownedTagValue}),
+		},
+	}, filters...)
+}
+
+func (cleaner *ClusterCleaner) listLoadBalancers(ctx context.Context, clusterName string) ([]string, error) {
+	names := []string{}
+	err := cleaner.ELB.DescribeLoadBalancersPagesWithContext(ctx, &elb.DescribeLoadBalancersInput{}, func(output *elb.DescribeLoadBalancersOutput, lastPage bool) bool {
+		for _, loadBalancer := range output.LoadBalancerDescriptions {
+			names = append(names, aws.StringValue(loadBalancer.LoadBalancerName))
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	owned := []string{}
+	for start := 0; start < len(names); start += describeTagsBatchSize {
+		end := start + describeTagsBatchSize
+		if end > len(names) {
+			end = len(names)
+		}
+		output, err := cleaner.ELB.DescribeTagsWithContext(ctx, &elb.DescribeTagsInput{
+			LoadBalancerNames: aws.StringSlice(names[start:end]),
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, description := range output.TagDescriptions {
+			for _, tag := range description.Tags {
+				if aws.StringValue(tag.Key) == ClusterOwnershipTagKey(clusterName) && aws.StringValue(tag.Value) == ownedTagValue {
+					owned = append(owned, aws.StringValue(description.LoadBalancerName))
+				}
+			}
+		}
+	}
+	return owned, nil
+}
+
+func (cleaner *ClusterCleaner) deleteLoadBalancer(ctx context.Context, name string) error {
+	_, err := cleaner.ELB.DeleteLoadBalancerWithContext(ctx, &elb.DeleteLoadBalancerInput{
+		LoadBalancerName: aws.String(name),
+	})
+	return err
+}
+
+func (cleaner *ClusterCleaner) listInstances(ctx context.Context, clusterName string) ([]string, error) {
+	ids := []string{}
+	err := cleaner.EC2.DescribeInstancesPagesWithContext(ctx, &ec2.DescribeInstancesInput{
+		Filters: ownedFilters(clusterName, &ec2.Filter{
+			Name:   aws.String("instance-state-name"),
+			Values: aws.StringSlice([]string{"pending", "running", "shutting-down", "stopping", "stopped"}),
+		}),
+	}, func(output *ec2.DescribeInstancesOutput, lastPage bool) bool {
+		for _, reservation := range output.Reservations {
+			for _, instance := range reservation.Instances {
+				ids = append(ids, aws.StringValue(instance.InstanceId))
+			}
+		}
+		return true
+	})
+	return ids, err
+}
+
+func (cleaner *ClusterCleaner) terminateInstances(ctx context.Context, ids []string) error {
+	_, err := cleaner.EC2.TerminateInstancesWithContext(ctx, &ec2.TerminateInstancesInput{
+		InstanceIds: aws.StringSlice(ids),
+	})
+	return err
+}
+
+func (cleaner *ClusterCleaner) waitForInstanceTermination(ctx context.Context, id string) error {
+	return cleaner.EC2.WaitUntilInstanceTerminatedWithContext(ctx, &ec2.DescribeInstancesInput{
+		InstanceIds: aws.StringSlice([]string{id}),
+	})
+}
+
+func (cleaner *ClusterCleaner) listNatGateways(ctx context.Context, clusterName string) ([]string, error) {
+	ids := []string{}
+	err := cleaner.EC2.DescribeNatGatewaysPagesWithContext(ctx, &ec2.DescribeNatGatewaysInput{
+		Filter: ownedFilters(clusterName, &ec2.Filter{
+			Name:   aws.String("state"),
+			Values: aws.StringSlice([]string{"pending", "available", "failed"}),
+		}),
+	}, func(output *ec2.DescribeNatGatewaysOutput, lastPage bool) bool {
+		for _, natGateway := range output.NatGateways {
+			ids = append(ids, aws.StringValue(natGateway.NatGatewayId))
+		}
+		return true
+	})
+	return ids, err
+}
+
+func (cleaner *ClusterCleaner) deleteNatGateway(ctx context.Context, id string) error {
+	_, err := cleaner.EC2.DeleteNatGatewayWithContext(ctx, &ec2.DeleteNatGatewayInput{
+		NatGatewayId: aws.String(id),
+	})
+	return err
+}
+
+func (cleaner *ClusterCleaner) listAddresses(ctx context.Context, clusterName string) ([]string, error) {
+	output, err := cleaner.EC2.DescribeAddressesWithContext(ctx, &ec2.DescribeAddressesInput{
+		Filters: ownedFilters(clusterName),
+	})
+	if err != nil {
+		return nil, err
+	}
+	ids := []string{}
+	for _, address := range output.Addresses {
+		ids = append(ids, aws.StringValue(address.AllocationId))
+	}
+	return ids, nil
+}
+
+// releaseAddress releases the elastic IP. The elastic IPs of the NAT gateways and the bastion instance can be
+// released only once they are deleted, which is retried
+func (cleaner *ClusterCleaner) releaseAddress(ctx context.Context, id string) error {
+	_, err := cleaner.EC2.ReleaseAddressWithContext(ctx, &ec2.ReleaseAddressInput{
+		AllocationId: aws.String(id),
+	})
+	return err
+}
+
+func (cleaner *ClusterCleaner) listInternetGateways(ctx context.Context, clusterName string) ([]string, error) {
+	ids := []string{}
+	err := cleaner.EC2.DescribeInternetGatewaysPagesWithContext(ctx, &ec2.DescribeInternetGatewaysInput{
+		Filters: ownedFilters(clusterName),
+	}, func(output *ec2.DescribeInternetGatewaysOutput, lastPage bool) bool {
+		for _, internetGateway := range output.InternetGateways {
+			ids = append(ids, aws.StringValue(internetGateway.InternetGatewayId))
+		}
+		return true
+	})
+	return ids, err
+}
+
+// deleteInternetGateway detaches the internet gateway from its VPCs and deletes it
+func (cleaner *ClusterCleaner) deleteInternetGateway(ctx context.Context, id string) error {
+	output, err := cleaner.EC2.DescribeInternetGatewaysWithContext(ctx, &ec2.DescribeInternetGatewaysInput{
+		InternetGatewayIds: aws.StringSlice([]string{id}),
+	})
+	if err != nil {
+		return err
+	}
+	for _, internetGateway := range output.InternetGateways {
+		for _, attachment := range internetGateway.Attachments {
+			_, err := cleaner.EC2.DetachInternetGatewayWithContext(ctx, &ec2.DetachInternetGatewayInput{
+				InternetGatewayId: aws.String(id),
+				VpcId:             attachment.VpcId,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err = cleaner.EC2.DeleteInternetGatewayWithContext(ctx, &ec2.DeleteInternetGatewayInput{
+		InternetGatewayId: aws.String(id),
+	})
+	return err
+}
+
+func (cleaner *ClusterCleaner) listSecurityGroups(ctx context.Context, clusterName string) ([]string, error) {
+	ids := []string{}
+	err := cleaner.EC2.DescribeSecurityGroupsPagesWithContext(ctx, &ec2.DescribeSecurityGroupsInput{
+		Filters: ownedFilters(clusterName),
+	}, func(output *ec2.DescribeSecurityGroupsOutput, lastPage bool) bool {
+		for _, securityGroup := range output.SecurityGroups {
+			ids = append(ids, aws.StringValue(securityGroup.GroupId))
+		}
+		return true
+	})
+	return ids, err
+}
+
+// revokeSecurityGroupIngress revokes the ingress rules of the security groups, as the security groups of a
+// cluster allow traffic from each other and a security group can't be deleted while another one refers to it
+func (cleaner *ClusterCleaner) revokeSecurityGroupIngress(ctx context.Context, ids []string) error {
+	output, err := cleaner.EC2.DescribeSecurityGroupsWithContext(ctx, &ec2.DescribeSecurityGroupsInput{
+		GroupIds: aws.StringSlice(ids),
+	})
+	if err != nil {
+		return err
+	}
+	for _, securityGroup := range output.SecurityGroups {
+		if len(securityGroup.IpPermissions) == 0 {
+			continue
+		}
+		_, err := cleaner.EC2.RevokeSecurityGroupIngressWithContext(ctx, &ec2.RevokeSecurityGroupIngressInput{
+			GroupId:       securityGroup.GroupId,
+			IpPermissions: securityGroup.IpPermissions,
+		})
+		if err != nil {
+			return fmt.Errorf("error while revoking ingress rules of security group %s: %v", aws.StringValue(securityGroup.GroupId), err)
+		}
+	}
+	return nil
+}
+
+func (cleaner *ClusterCleaner) deleteSecurityGroup(ctx context.Context, id string) error {
+	_, err := cleaner.EC2.DeleteSecurityGroupWithContext(ctx, &ec2.DeleteSecurityGroupInput{
+		GroupId: aws.String(id),
+	})
+	return err
+}
+
+func (cleaner *ClusterCleaner) listSubnets(ctx context.Context, clusterName string) ([]string, error) {
+	ids := []string{}
+	err := cleaner.EC2.DescribeSubnetsPagesWithContext(ctx, &ec2.DescribeSubnetsInput{
+		Filters: ownedFilters(clusterName),
+	}, func(output *ec2.DescribeSubnetsOutput, lastPage bool) bool {
+		for _, subnet := range output.Subnets {
+			ids = append(ids, aws.StringValue(subnet.SubnetId))
+		}
+		return true
+	})
+	return ids, err
+}
+
+func (cleaner *ClusterCleaner) deleteSubnet(ctx context.Context, id string) error {
+	_, err := cleaner.EC2.DeleteSubnetWithContext(ctx, &ec2.DeleteSubnetInput{
+		SubnetId: aws.String(id),
+	})
+	return err
+}
+
+// listRouteTables lists the route tables of the cluster, except the main route tables of the VPCs, which are
+// deleted along with the VPCs. The associations of the other route tables are deleted along with the subnets
+func (cleaner *ClusterCleaner) listRouteTables(ctx context.Context, clusterName string) ([]string, error) {
+	ids := []string{}
+	err := cleaner.EC2.DescribeRouteTablesPagesWithContext(ctx, &ec2.DescribeRouteTablesInput{
+		Filters: ownedFilters(clusterName),
+	}, func(output *ec2.DescribeRouteTablesOutput, lastPage bool) bool {
+		for _, routeTable := range output.RouteTables {
+			main := false
+			for _, association := range routeTable.Associations {
+				if aws.BoolValue(association.Main) {
+					main = true
+				}
+			}
+			if !main {
+				ids = append(ids, aws.StringValue(routeTable.RouteTableId))
+			}
+		}
+		return true
+	})
+	return ids, err
+}
+
+func (cleaner *ClusterCleaner) deleteRouteTable(ctx context.Context, id string) error {
+	_, err := cleaner.EC2.DeleteRouteTableWithContext(ctx, &ec2.DeleteRouteTableInput{
+		RouteTableId: aws.String(id),
+	})
+	return err
+}
+
+func (cleaner *ClusterCleaner) listVpcs(ctx context.Context, clusterName string) ([]string, error) {
+	ids := []string{}
+	err := cleaner.EC2.DescribeVpcsPagesWithContext(ctx, &ec2.DescribeVpcsInput{
+		Filters: ownedFilters(clusterName),
+	}, func(output *ec2.DescribeVpcsOutput, lastPage bool) bool {
+		for _, vpc := range output.Vpcs {
+			ids = append(ids, aws.StringValue(vpc.VpcId))
+		}
+		return true
+	})
+	return ids, err
+}
+
+func (cleaner *ClusterCleaner) deleteVpc(ctx context.Context, id string) error {
+	_, err := cleaner.EC2.DeleteVpcWithContext(ctx, &ec2.DeleteVpcInput{
+		VpcId: aws.String(id),
+	})
+	return err
+}
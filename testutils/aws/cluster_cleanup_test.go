@@ -0,0 +1,302 @@
+package aws_test
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/aws/aws-sdk-go/service/elb"
+	"github.com/aws/aws-sdk-go/service/elb/elbiface"
+	awsutils "github.com/karuppiah7890/tce-e2e-test/testutils/aws"
+)
+
+const testClusterName = "test-mgmt-1"
+
+// fakeAPI records the calls to the fake EC2 and ELB APIs which change resources, and fails them as set
+type fakeAPI struct {
+	calls []string
+	// failures has the number of times each call fails before it succeeds
+	failures map[string]int
+}
+
+func (api *fakeAPI) call(name string, id string) error {
+	call := fmt.Sprintf("%s %s", name, id)
+	api.calls = append(api.calls, call)
+	if api.failures[call] > 0 {
+		api.failures[call]--
+		return awserr.New("DependencyViolation", fmt.Sprintf("%s has a dependent object", id), nil)
+	}
+	return nil
+}
+
+// fakeEC2 fakes the EC2 API with the resources of the test-mgmt-1 cluster
+type fakeEC2 struct {
+	ec2iface.EC2API
+	*fakeAPI
+}
+
+// fakeELB fakes the ELB API with the load balancers of the test-mgmt-1 and test-mgmt-10 clusters
+type fakeELB struct {
+	elbiface.ELBAPI
+	*fakeAPI
+}
+
+// owned returns whether the filters are for the resources owned by the test cluster
+func owned(filters []*ec2.Filter) bool {
+	for _, filter := range filters {
+		if aws.StringValue(filter.Name) == "tag:"+awsutils.ClusterOwnershipTagKey(testClusterName) {
+			return reflect.DeepEqual(aws.StringValueSlice(filter.Values), []string{"owned"})
+		}
+	}
+	return false
+}
+
+func (api *fakeELB) DescribeLoadBalancersPagesWithContext(ctx aws.Context, input *elb.DescribeLoadBalancersInput, fn func(*elb.DescribeLoadBalancersOutput, bool) bool, options ...request.Option) error {
+	fn(&elb.DescribeLoadBalancersOutput{LoadBalancerDescriptions: []*elb.LoadBalancerDescription{
+		{LoadBalancerName: aws.String("test-mgmt-1-apiserver")},
+		{LoadBalancerName: aws.String("test-mgmt-10-apiserver")},
+	}}, true)
+	return nil
+}
+
+func (api *fakeELB) DescribeTagsWithContext(ctx aws.Context, input *elb.DescribeTagsInput, options ...request.Option) (*elb.DescribeTagsOutput, error) {
+	output := &elb.DescribeTagsOutput{}
+	for _, name := range aws.StringValueSlice(input.LoadBalancerNames) {
+		clusterName := strings.TrimSuffix(name, "-apiserver")
+		output.TagDescriptions = append(output.TagDescriptions, &elb.TagDescription{
+			LoadBalancerName: aws.String(name),
+			Tags:             []*elb.Tag{{Key: aws.String(awsutils.ClusterOwnershipTagKey(clusterName)), Value: aws.String("owned")}},
+		})
+	}
+	return output, nil
+}
+
+func (api *fakeELB) DeleteLoadBalancerWithContext(ctx aws.Context, input *elb.DeleteLoadBalancerInput, options ...request.Option) (*elb.DeleteLoadBalancerOutput, error) {
+	return &elb.DeleteLoadBalancerOutput{}, api.call("DeleteLoadBalancer", aws.StringValue(input.LoadBalancerName))
+}
+
+func (api *fakeEC2) DescribeInstancesPagesWithContext(ctx aws.Context, input *ec2.DescribeInstancesInput, fn func(*ec2.DescribeInstancesOutput, bool) bool, options ...request.Option) error {
+	if owned(input.Filters) {
+		fn(&ec2.DescribeInstancesOutput{Reservations: []*ec2.Reservation{{Instances: []*ec2.Instance{
+			{InstanceId: aws.String("i-control-plane")},
+			{InstanceId: aws.String("i-bastion")},
+		}}}}, true)
+	}
+	return nil
+}
+
+func (api *fakeEC2) TerminateInstancesWithContext(ctx aws.Context, input *ec2.TerminateInstancesInput, options ...request.Option) (*ec2.TerminateInstancesOutput, error) {
+	return &ec2.TerminateInstancesOutput{}, api.call("TerminateInstances", strings.Join(aws.StringValueSlice(input.InstanceIds), ","))
+}
+
+func (api *fakeEC2) WaitUntilInstanceTerminatedWithContext(ctx aws.Context, input *ec2.DescribeInstancesInput, options ...request.WaiterOption) error {
+	return api.call("WaitUntilInstanceTerminated", strings.Join(aws.StringValueSlice(input.InstanceIds), ","))
+}
+
+func (api *fakeEC2) DescribeNatGatewaysPagesWithContext(ctx aws.Context, input *ec2.DescribeNatGatewaysInput, fn func(*ec2.DescribeNatGatewaysOutput, bool) bool, options ...request.Option) error {
+	if owned(input.Filter) {
+		fn(&ec2.DescribeNatGatewaysOutput{NatGateways: []*ec2.NatGateway{{NatGatewayId: aws.String("nat-1")}}}, true)
+	}
+	return nil
+}
+
+func (api *fakeEC2) DeleteNatGatewayWithContext(ctx aws.Context, input *ec2.DeleteNatGatewayInput, options ...request.Option) (*ec2.DeleteNatGatewayOutput, error) {
+	return &ec2.DeleteNatGatewayOutput{}, api.call("DeleteNatGateway", aws.StringValue(input.NatGatewayId))
+}
+
+func (api *fakeEC2) DescribeAddressesWithContext(ctx aws.Context, input *ec2.DescribeAddressesInput, options ...request.Option) (*ec2.DescribeAddressesOutput, error) {
+	if !owned(input.Filters) {
+		return &ec2.DescribeAddressesOutput{}, nil
+	}
+	return &ec2.DescribeAddressesOutput{Addresses: []*ec2.Address{{AllocationId: aws.String("eipalloc-1")}}}, nil
+}
+
+func (api *fakeEC2) ReleaseAddressWithContext(ctx aws.Context, input *ec2.ReleaseAddressInput, options ...request.Option) (*ec2.ReleaseAddressOutput, error) {
+	return &ec2.ReleaseAddressOutput{}, api.call("ReleaseAddress", aws.StringValue(input.AllocationId))
+}
+
+func (api *fakeEC2) DescribeInternetGatewaysPagesWithContext(ctx aws.Context, input *ec2.DescribeInternetGatewaysInput, fn func(*ec2.DescribeInternetGatewaysOutput, bool) bool, options ...request.Option) error {
+	if owned(input.Filters) {
+		fn(&ec2.DescribeInternetGatewaysOutput{InternetGateways: []*ec2.InternetGateway{{InternetGatewayId: aws.String("igw-1")}}}, true)
+	}
+	return nil
+}
+
+func (api *fakeEC2) DescribeInternetGatewaysWithContext(ctx aws.Context, input *ec2.DescribeInternetGatewaysInput, options ...request.Option) (*ec2.DescribeInternetGatewaysOutput, error) {
+	return &ec2.DescribeInternetGatewaysOutput{InternetGateways: []*ec2.InternetGateway{{
+		InternetGatewayId: aws.String("igw-1"),
+		Attachments:       []*ec2.InternetGatewayAttachment{{VpcId: aws.String("vpc-1")}},
+	}}}, nil
+}
+
+func (api *fakeEC2) DetachInternetGatewayWithContext(ctx aws.Context, input *ec2.DetachInternetGatewayInput, options ...request.Option) (*ec2.DetachInternetGatewayOutput, error) {
+	return &ec2.DetachInternetGatewayOutput{}, api.call("DetachInternetGateway", aws.StringValue(input.InternetGatewayId)+"/"+aws.StringValue(input.VpcId))
+}
+
+func (api *fakeEC2) DeleteInternetGatewayWithContext(ctx aws.Context, input *ec2.DeleteInternetGatewayInput, options ...request.Option) (*ec2.DeleteInternetGatewayOutput, error) {
+	return &ec2.DeleteInternetGatewayOutput{}, api.call("DeleteInternetGateway", aws.StringValue(input.InternetGatewayId))
+}
+
+func (api *fakeEC2) DescribeSecurityGroupsPagesWithContext(ctx aws.Context, input *ec2.DescribeSecurityGroupsInput, fn func(*ec2.DescribeSecurityGroupsOutput, bool) bool, options ...request.Option) error {
+	if owned(input.Filters) {
+		fn(&ec2.DescribeSecurityGroupsOutput{SecurityGroups: []*ec2.SecurityGroup{{GroupId: aws.String("sg-node")}, {GroupId: aws.String("sg-lb")}}}, true)
+	}
+	return nil
+}
+
+func (api *fakeEC2) DescribeSecurityGroupsWithContext(ctx aws.Context, input *ec2.DescribeSecurityGroupsInput, options ...request.Option) (*ec2.DescribeSecurityGroupsOutput, error) {
+	return &ec2.DescribeSecurityGroupsOutput{SecurityGroups: []*ec2.SecurityGroup{
+		{GroupId: aws.String("sg-node"), IpPermissions: []*ec2.IpPermission{{UserIdGroupPairs: []*ec2.UserIdGroupPair{{GroupId: aws.String("sg-lb")}}}}},
+		{GroupId: aws.String("sg-lb")},
+	}}, nil
+}
+
+func (api *fakeEC2) RevokeSecurityGroupIngressWithContext(ctx aws.Context, input *ec2.RevokeSecurityGroupIngressInput, options ...request.Option) (*ec2.RevokeSecurityGroupIngressOutput, error) {
+	return &ec2.RevokeSecurityGroupIngressOutput{}, api.call("RevokeSecurityGroupIngress", aws.StringValue(input.GroupId))
+}
+
+func (api *fakeEC2) DeleteSecurityGroupWithContext(ctx aws.Context, input *ec2.DeleteSecurityGroupInput, options ...request.Option) (*ec2.DeleteSecurityGroupOutput, error) {
+	return &ec2.DeleteSecurityGroupOutput{}, api.call("DeleteSecurityGroup", aws.StringValue(input.GroupId))
+}
+
+func (api *fakeEC2) DescribeSubnetsPagesWithContext(ctx aws.Context, input *ec2.DescribeSubnetsInput, fn func(*ec2.DescribeSubnetsOutput, bool) bool, options ...request.Option) error {
+	if owned(input.Filters) {
+		fn(&ec2.DescribeSubnetsOutput{Subnets: []*ec2.Subnet{{SubnetId: aws.String("subnet-public")}, {SubnetId: aws.String("subnet-private")}}}, true)
+	}
+	return nil
+}
+
+func (api *fakeEC2) DeleteSubnetWithContext(ctx aws.Context, input *ec2.DeleteSubnetInput, options ...request.Option) (*ec2.DeleteSubnetOutput, error) {
+	return &ec2.DeleteSubnetOutput{}, api.call("DeleteSubnet", aws.StringValue(input.SubnetId))
+}
+
+func (api *fakeEC2) DescribeRouteTablesPagesWithContext(ctx aws.Context, input *ec2.DescribeRouteTablesInput, fn func(*ec2.DescribeRouteTablesOutput, bool) bool, options ...request.Option) error {
+	if owned(input.Filters) {
+		fn(&ec2.DescribeRouteTablesOutput{RouteTables: []*ec2.RouteTable{
+			{RouteTableId: aws.String("rtb-main"), Associations: []*ec2.RouteTableAssociation{{Main: aws.Bool(true)}}},
+			{RouteTableId: aws.String("rtb-public")},
+		}}, true)
+	}
+	return nil
+}
+
+func (api *fakeEC2) DeleteRouteTableWithContext(ctx aws.Context, input *ec2.DeleteRouteTableInput, options ...request.Option) (*ec2.DeleteRouteTableOutput, error) {
+	return &ec2.DeleteRouteTableOutput{}, api.call("DeleteRouteTable", aws.StringValue(input.RouteTableId))
+}
+
+func (api *fakeEC2) DescribeVpcsPagesWithContext(ctx aws.Context, input *ec2.DescribeVpcsInput, fn func(*ec2.DescribeVpcsOutput, bool) bool, options ...request.Option) error {
+	if owned(input.Filters) {
+		fn(&ec2.DescribeVpcsOutput{Vpcs: []*ec2.Vpc{{VpcId: aws.String("vpc-1")}}}, true)
+	}
+	return nil
+}
+
+func (api *fakeEC2) DeleteVpcWithContext(ctx aws.Context, input *ec2.DeleteVpcInput, options ...request.Option) (*ec2.DeleteVpcOutput, error) {
+	return &ec2.DeleteVpcOutput{}, api.call("DeleteVpc", aws.StringValue(input.VpcId))
+}
+
+func newCleaner(api *fakeAPI) *awsutils.ClusterCleaner {
+	return &awsutils.ClusterCleaner{
+		EC2:                 &fakeEC2{fakeAPI: api},
+		ELB:                 &fakeELB{fakeAPI: api},
+		DeleteAttempts:      3,
+		DeleteRetryInterval: 1,
+	}
+}
+
+func TestClusterCleaner(t *testing.T) {
+	expectedResources := []awsutils.ClusterResource{
+		{Kind: "load balancer", ID: "test-mgmt-1-apiserver"},
+		{Kind: "instance", ID: "i-control-plane"},
+		{Kind: "instance", ID: "i-bastion"},
+		{Kind: "NAT gateway", ID: "nat-1"},
+		{Kind: "elastic IP", ID: "eipalloc-1"},
+		{Kind: "internet gateway", ID: "igw-1"},
+		{Kind: "security group", ID: "sg-node"},
+		{Kind: "security group", ID: "sg-lb"},
+		{Kind: "subnet", ID: "subnet-public"},
+		{Kind: "subnet", ID: "subnet-private"},
+		{Kind: "route table", ID: "rtb-public"},
+		{Kind: "VPC", ID: "vpc-1"},
+	}
+
+	t.Run("when in dry run mode it should list the resources owned by the cluster without deleting them", func(t *testing.T) {
+		api := &fakeAPI{}
+		cleaner := newCleaner(api)
+		cleaner.DryRun = true
+
+		resources, err := cleaner.Cleanup(context.Background(), testClusterName)
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		if !reflect.DeepEqual(resources, expectedResources) {
+			t.Errorf("expected resources to be %v but got %v", expectedResources, resources)
+		}
+		if len(api.calls) != 0 {
+			t.Errorf("expected no changes in dry run mode but got calls: %v", api.calls)
+		}
+	})
+
+	t.Run("when resources depend on each other it should delete them in dependency order with retries", func(t *testing.T) {
+		api := &fakeAPI{failures: map[string]int{
+			"ReleaseAddress eipalloc-1":  1,
+			"DeleteSubnet subnet-public": 2,
+		}}
+
+		resources, err := newCleaner(api).Cleanup(context.Background(), testClusterName)
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		if !reflect.DeepEqual(resources, expectedResources) {
+			t.Errorf("expected deleted resources to be %v but got %v", expectedResources, resources)
+		}
+
+		expectedCalls := []string{
+			"DeleteLoadBalancer test-mgmt-1-apiserver",
+			"TerminateInstances i-control-plane,i-bastion",
+			"WaitUntilInstanceTerminated i-control-plane",
+			"WaitUntilInstanceTerminated i-bastion",
+			"DeleteNatGateway nat-1",
+			"ReleaseAddress eipalloc-1",
+			"ReleaseAddress eipalloc-1",
+			"DetachInternetGateway igw-1/vpc-1",
+			"DeleteInternetGateway igw-1",
+			"RevokeSecurityGroupIngress sg-node",
+			"DeleteSecurityGroup sg-node",
+			"DeleteSecurityGroup sg-lb",
+			"DeleteSubnet subnet-public",
+			"DeleteSubnet subnet-public",
+			"DeleteSubnet subnet-public",
+			"DeleteSubnet subnet-private",
+			"DeleteRouteTable rtb-public",
+			"DeleteVpc vpc-1",
+		}
+		if !reflect.DeepEqual(api.calls, expectedCalls) {
+			t.Errorf("expected calls to be\n%s\nbut got\n%s", strings.Join(expectedCalls, "\n"), strings.Join(api.calls, "\n"))
+		}
+	})
+
+	t.Run("when a resource can't be deleted it should delete the others and return an error", func(t *testing.T) {
+		api := &fakeAPI{failures: map[string]int{
+			"DeleteSubnet subnet-private": 3,
+		}}
+
+		resources, err := newCleaner(api).Cleanup(context.Background(), testClusterName)
+		if err == nil {
+			t.Fatalf("expected an error but got none")
+		}
+		if !strings.Contains(err.Error(), "error while deleting subnet subnet-private") {
+			t.Errorf("expected error to mention the subnet but got: %v", err)
+		}
+		if len(resources) != len(expectedResources)-1 {
+			t.Errorf("expected %d deleted resources but got %d: %v", len(expectedResources)-1, len(resources), resources)
+		}
+	})
+}
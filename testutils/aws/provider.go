@@ -2,17 +2,39 @@ package aws
 
 import (
 	"context"
+	"fmt"
 	"os"
+	"strconv"
+	"time"
 
+	"github.com/karuppiah7890/tce-e2e-test/testutils/cleanup"
 	"github.com/karuppiah7890/tce-e2e-test/testutils/clirunner"
 	"github.com/karuppiah7890/tce-e2e-test/testutils/log"
 	"github.com/karuppiah7890/tce-e2e-test/testutils/tanzu"
 	"github.com/karuppiah7890/tce-e2e-test/testutils/utils"
 )
 
+// cloudFormationStackCleanupTimeout is the maximum time given to delete the CloudFormation stack during cleanup
+const cloudFormationStackCleanupTimeout = 15 * time.Minute
+
+// ResourceKindCloudFormationStack is the kind of the CloudFormation stack in the state of a test run
+const ResourceKindCloudFormationStack = "cloudformation-stack"
+
+// DeleteCloudFormationStackEnvVarName is the environment variable which, when set to true, makes the test run
+// delete the CloudFormation stack during cleanup when the stack did not exist before the test run. The stack has
+// the IAM resources of all the clusters in the AWS account, so it's never deleted otherwise
+const DeleteCloudFormationStackEnvVarName = "AWS_DELETE_CLOUDFORMATION_STACK"
+
 // TODO: Change name?
 type Provider struct {
 	testSecrets TestSecrets
+	logger      *log.Logger
+	cleanups    *cleanup.Stack
+	// cloudFormationStackCleanup is the cleanup step of the CloudFormation stack, when it's created by the test run
+	// and deleting it is opted in
+	cloudFormationStackCleanup *cleanup.Step
+	// deleteCloudFormationStack tells if the CloudFormation stack created by the test run is to be deleted
+	deleteCloudFormationStack bool
 }
 
 func (provider *Provider) RequiredEnvVars() []string {
@@ -26,26 +48,80 @@ func (provider *Provider) RequiredEnvVars() []string {
 	}
 }
 
+// SecretEnvVars returns the AWS credentials
+func (provider *Provider) SecretEnvVars() []string {
+	return []string{
+		AccessKey,
+		SecretKey,
+		B64Creds,
+	}
+}
+
 func (provider *Provider) Name() string {
 	return "aws"
 }
 
-func (provider *Provider) Init() error {
+func (provider *Provider) Init(logger *log.Logger, cleanups *cleanup.Stack, tanzuConfig utils.TanzuConfigFunc) error {
+	provider.logger = logger
+	provider.cleanups = cleanups
+	provider.cloudFormationStackCleanup = nil
+	provider.deleteCloudFormationStack, _ = strconv.ParseBool(os.Getenv(DeleteCloudFormationStackEnvVarName))
 	provider.testSecrets = ExtractAwsTestSecretsFromEnvVars()
 	return nil
 }
 
 func (provider *Provider) PreClusterCreationTasks(clusterName string, clusterType utils.ClusterType) error {
-	createCloudFormationStack()
+	stackExists, err := cloudFormationStackExists(context.Background(), provider.testSecrets.Region, CloudFormationStackName)
+	if err != nil {
+		return fmt.Errorf("error while checking if CloudFormation stack exists: %v", err)
+	}
+
+	err = createCloudFormationStack(provider.logger)
+	if err != nil {
+		return err
+	}
+
+	// The CloudFormation stack is shared by all the clusters in the AWS account, and other test runs can create
+	// it at the same time, so it's deleted during cleanup only when that's opted in and it did not exist before
+	// the test run
+	if provider.deleteCloudFormationStack && !stackExists && provider.cloudFormationStackCleanup == nil {
+		region := provider.testSecrets.Region
+		stack := cleanup.Resource{Kind: ResourceKindCloudFormationStack, Name: CloudFormationStackName}
+		provider.cloudFormationStackCleanup = provider.cleanups.PushResource(fmt.Sprintf("delete CloudFormation stack %s", CloudFormationStackName), stack, cloudFormationStackCleanupTimeout, func(ctx context.Context) error {
+			return deleteCloudFormationStack(ctx, region, CloudFormationStackName)
+		})
+	}
+
 	return nil
 }
 
+// CleanupCluster deletes the AWS resources CAPA created for the cluster and left behind, like its instances,
+// load balancers, NAT gateways, elastic IPs and VPC, and logs what it deleted
 func (provider *Provider) CleanupCluster(ctx context.Context, clusterName string) error {
-	// TODO: Implement using aws-nuke library
-	return nil
+	cleaner, err := NewClusterCleaner(provider.testSecrets.Region, provider.logger)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := cleaner.Cleanup(ctx, clusterName)
+	if len(deleted) == 0 {
+		provider.logger.Infof("No AWS resources found for cluster %s", clusterName)
+	} else {
+		provider.logger.Infof("Deleted %d AWS resources of cluster %s", len(deleted), clusterName)
+	}
+	return err
 }
 
-//TODO: Maybe make use of https://github.com/spf13/viper to set env vars and make some values as default and parameterised.
+// TeardownResource deletes the CloudFormation stack created by a test run, using only the state of the test run
+func (provider *Provider) TeardownResource(ctx context.Context, resource cleanup.Resource) error {
+	if resource.Kind != ResourceKindCloudFormationStack {
+		return fmt.Errorf("%s provider can't tear down %s", provider.Name(), resource)
+	}
+	return deleteCloudFormationStack(ctx, provider.testSecrets.Region, resource.Name)
+}
+
+// GetTanzuConfig returns the default tanzu config of the clusters. A scenario and environment variables can
+// override any of the values, see utils.ClusterTanzuConfig
 func (provider *Provider) GetTanzuConfig(clusterName string) tanzu.TanzuConfig {
 	return tanzu.TanzuConfig{
 		"CLUSTER_NAME":               clusterName,
@@ -70,9 +146,9 @@ func (provider *Provider) GetTanzuConfig(clusterName string) tanzu.TanzuConfig {
 	}
 }
 
-func createCloudFormationStack() {
-	log.Info("Creating Cloud formation stack ")
-	exitCode, err := clirunner.Run(clirunner.Cmd{
+func createCloudFormationStack(logger *log.Logger) error {
+	logger.Info("Creating Cloud formation stack ")
+	result, err := clirunner.Run(clirunner.Cmd{
 		Name: "tanzu",
 		Args: []string{
 			"management-cluster",
@@ -80,16 +156,24 @@ func createCloudFormationStack() {
 			"aws",
 			"set",
 		},
-		Stdout: log.InfoWriter,
-		Stderr: log.ErrorWriter,
-		Env:    os.Environ(),
+		Stdout:           logger.InfoWriter(),
+		StderrClassifier: log.DefaultStreamClassifier(),
+		Env:              os.Environ(),
+		Logger:           logger,
 	})
 
 	if err != nil {
-		log.Fatalf("Error occurred while creating Cloud formation stack  Exit code: %v. Error: %v", exitCode, err)
+		return fmt.Errorf("error occurred while creating Cloud formation stack. Exit code: %v. Error: %v", result.ExitCode, err)
 	}
 
+	return nil
 }
 
 // TODO: Change name?
 var PROVIDER utils.Provider = &Provider{}
+
+func init() {
+	utils.RegisterProvider("Amazon Web Services (AWS) EC2", func() utils.Provider {
+		return &Provider{}
+	})
+}
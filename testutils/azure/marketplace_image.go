@@ -24,9 +24,9 @@ import (
 )
 
 // TODO: Should we just use one function acceptAzureImageLicenses with the whole implementation? There will be a for loop with a big body though
-func AcceptAzureImageLicenses(subscriptionID string, cred *azidentity.ClientSecretCredential, azureMarketplaceImages ...*capzv1beta1.AzureMarketplaceImage) error {
+func AcceptAzureImageLicenses(logger *log.Logger, subscriptionID string, cred *azidentity.ClientSecretCredential, azureMarketplaceImages ...*capzv1beta1.AzureMarketplaceImage) error {
 	for _, azureMarketplaceImage := range azureMarketplaceImages {
-		err := AcceptAzureImageLicense(subscriptionID, cred, azureMarketplaceImage)
+		err := AcceptAzureImageLicense(logger, subscriptionID, cred, azureMarketplaceImage)
 		if err != nil {
 			return fmt.Errorf("failed to azure image license: %v", err)
 		}
@@ -38,7 +38,7 @@ func AcceptAzureImageLicenses(subscriptionID string, cred *azidentity.ClientSecr
 // This naming is for clarity until we move the function to some azure specific
 // package then we can remove the reference to azure from it and rename
 // it back to acceptImageLicense
-func AcceptAzureImageLicense(subscriptionID string, cred *azidentity.ClientSecretCredential, azureMarketplaceImage *capzv1beta1.AzureMarketplaceImage) error {
+func AcceptAzureImageLicense(logger *log.Logger, subscriptionID string, cred *azidentity.ClientSecretCredential, azureMarketplaceImage *capzv1beta1.AzureMarketplaceImage) error {
 	azureVmImagePublisher := azureMarketplaceImage.Publisher
 	azureVmImageBillingPlanSku := azureMarketplaceImage.SKU
 	azureVmImageOffer := azureMarketplaceImage.Offer
@@ -46,7 +46,7 @@ func AcceptAzureImageLicense(subscriptionID string, cred *azidentity.ClientSecre
 	ctx := context.Background()
 	client := armmarketplaceordering.NewMarketplaceAgreementsClient(subscriptionID, cred, nil)
 
-	log.Info("Getting marketplace terms for Azure VM image")
+	logger.Info("Getting marketplace terms for Azure VM image")
 	res, err := client.Get(ctx,
 		armmarketplaceordering.OfferType(armmarketplaceordering.OfferTypeVirtualmachine),
 		azureVmImagePublisher,
@@ -68,9 +68,9 @@ func AcceptAzureImageLicense(subscriptionID string, cred *azidentity.ClientSecre
 	}
 
 	if isTermsAccepted := *agreementTerms.Properties.Accepted; isTermsAccepted {
-		log.Info("Azure VM image agreement terms are already accepted")
+		logger.Info("Azure VM image agreement terms are already accepted")
 	} else {
-		log.Info("Azure VM image agreement terms is not already accepted. Accepting the Azure VM image agreement terms now")
+		logger.Info("Azure VM image agreement terms is not already accepted. Accepting the Azure VM image agreement terms now")
 
 		*agreementTerms.Properties.Accepted = true
 		// Note: We sign using a PUT request to change the `accepted` property in the agreement. This is how Azure CLI does it too.
@@ -93,7 +93,7 @@ func AcceptAzureImageLicense(subscriptionID string, cred *azidentity.ClientSecre
 		if isTermsSignedAndAccepted := *signedAgreementTerms.Properties.Accepted; !isTermsSignedAndAccepted {
 			return fmt.Errorf("error while signing and accepting the agreement terms for Azure VM image: Azure VM image agreement terms was not signed and accepted")
 		} else {
-			log.Info("Accepted the Azure VM image agreement terms!")
+			logger.Info("Accepted the Azure VM image agreement terms!")
 		}
 	}
 
@@ -136,11 +136,11 @@ func ParseK8sYamlAndFetchAzureMachineTemplates(fileR []byte) ([]kubeRuntime.Obje
 	return retVal, nil
 }
 
-func GetAzureMarketplaceImageInfoForCluster(clusterName string, clusterType utils.ClusterType) ([]*capzv1beta1.AzureMarketplaceImage, error) {
+func GetAzureMarketplaceImageInfoForCluster(logger *log.Logger, tanzuConfig tanzu.TanzuConfig, clusterName string, clusterType utils.ClusterType) ([]*capzv1beta1.AzureMarketplaceImage, error) {
 	var clusterCreateDryRunOutputBuffer bytes.Buffer
 
-	envVars := tanzu.TanzuConfigToEnvVars(PROVIDER.GetTanzuConfig(clusterName))
-	exitCode, err := clirunner.Run(clirunner.Cmd{
+	envVars := tanzu.TanzuConfigToEnvVars(tanzuConfig)
+	result, err := clirunner.Run(clirunner.Cmd{
 		Name: "tanzu",
 		Args: []string{
 			clusterType.TanzuCommand(),
@@ -154,26 +154,21 @@ func GetAzureMarketplaceImageInfoForCluster(clusterName string, clusterType util
 		},
 		Env: append(os.Environ(), envVars...),
 		// TODO: Do we really want to output to log.InfoWriter ? Is this
-		// data necessary in the logs? This data will contain secrets but for now we haven't masked secrets
-		// in logs, also, even if we mask secrets, is this data useful and necessary?
+		// data necessary in the logs? This data will contain secrets, which are masked in the logs,
+		// but even then, is this data useful and necessary?
 		// The data in log can help development but that's all
 		Stdout: &clusterCreateDryRunOutputBuffer,
-		// TODO: Should we log standard errors as errors in the log? Because tanzu prints other information also
-		// to standard error, which are kind of like information, apart from actual errors, so showing
-		// everything as error is misleading. Gotta think what to do about this. The main problem is
-		// console has only standard output and standard error, and tanzu is using standard output only for
-		// giving output for things like --dry-run when it needs to print yaml content, but everything else
-		// is printed to standard error
-		// TODO: Do we really want to output to log.ErrorWriter ? Is this
-		// data necessary in the logs? This data will contain secrets but for now we haven't masked secrets
-		// in logs, also, even if we mask secrets, is this data useful and necessary?
+		// TODO: Do we really want to log the standard error? Is this
+		// data necessary in the logs? This data will contain secrets, which are masked in the logs,
+		// but even then, is this data useful and necessary?
 		// The data in log can help development and also
 		// during actual runs to check if there are any errors from the command, hmm
-		Stderr: log.ErrorWriter,
+		StderrClassifier: log.DefaultStreamClassifier(),
+		Logger:           logger,
 	})
 
 	if err != nil {
-		return nil, fmt.Errorf("error occurred while running %v dry run. Exit code: %v. Error: %v", clusterName, exitCode, err)
+		return nil, fmt.Errorf("error occurred while running %v dry run. Exit code: %v. Error: %v", clusterName, result.ExitCode, err)
 	}
 
 	clusterCreateDryRunOutput, err := io.ReadAll(&clusterCreateDryRunOutputBuffer)
@@ -184,7 +179,7 @@ func GetAzureMarketplaceImageInfoForCluster(clusterName string, clusterType util
 
 	objects, err := ParseK8sYamlAndFetchAzureMachineTemplates(clusterCreateDryRunOutput)
 	if err != nil {
-		return nil, fmt.Errorf("error occurred while parsing K8s yaml from %v dry run to fetch azure machine template. Exit code: %v. Error: %v", clusterName, exitCode, err)
+		return nil, fmt.Errorf("error occurred while parsing K8s yaml from %v dry run to fetch azure machine template. Exit code: %v. Error: %v", clusterName, result.ExitCode, err)
 	}
 
 	marketplaces := []*capzv1beta1.AzureMarketplaceImage{}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/cleanup"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/log"
 	"github.com/karuppiah7890/tce-e2e-test/testutils/tanzu"
 	"github.com/karuppiah7890/tce-e2e-test/testutils/utils"
 )
@@ -13,6 +15,8 @@ import (
 type Provider struct {
 	cred        *azidentity.ClientSecretCredential
 	testSecrets TestSecrets
+	logger      *log.Logger
+	tanzuConfig utils.TanzuConfigFunc
 }
 
 func (provider *Provider) RequiredEnvVars() []string {
@@ -25,11 +29,20 @@ func (provider *Provider) RequiredEnvVars() []string {
 	}
 }
 
+// SecretEnvVars returns the secret of the Azure service principal
+func (provider *Provider) SecretEnvVars() []string {
+	return []string{
+		ClientSecretEnvVarName,
+	}
+}
+
 func (provider *Provider) Name() string {
 	return "azure"
 }
 
-func (provider *Provider) Init() error {
+func (provider *Provider) Init(logger *log.Logger, cleanups *cleanup.Stack, tanzuConfig utils.TanzuConfigFunc) error {
+	provider.logger = logger
+	provider.tanzuConfig = tanzuConfig
 	provider.testSecrets = ExtractAzureTestSecretsFromEnvVars()
 
 	cred, err := Login()
@@ -43,9 +56,13 @@ func (provider *Provider) Init() error {
 }
 
 func (provider *Provider) PreClusterCreationTasks(clusterName string, clusterType utils.ClusterType) error {
-	azureMarketplaceImageInfoForCluster, err := GetAzureMarketplaceImageInfoForCluster(clusterName, clusterType)
+	tanzuConfig := provider.tanzuConfig(clusterName, clusterType)
+	azureMarketplaceImageInfoForCluster, err := GetAzureMarketplaceImageInfoForCluster(provider.logger, tanzuConfig, clusterName, clusterType)
+	if err != nil {
+		return fmt.Errorf("failed to get azure marketplace images of the cluster: %v", err)
+	}
 
-	err = AcceptAzureImageLicenses(provider.testSecrets.SubscriptionID, provider.cred, azureMarketplaceImageInfoForCluster...)
+	err = AcceptAzureImageLicenses(provider.logger, provider.testSecrets.SubscriptionID, provider.cred, azureMarketplaceImageInfoForCluster...)
 	if err != nil {
 		return fmt.Errorf("failed to azure image licenses: %v", err)
 	}
@@ -61,7 +78,8 @@ func (provider *Provider) CleanupCluster(ctx context.Context, clusterName string
 	return nil
 }
 
-//TODO: Maybe make use of https://github.com/spf13/viper to set env vars and make some values as default and parameterised.
+// GetTanzuConfig returns the default tanzu config of the clusters. A scenario and environment variables can
+// override any of the values, see utils.ClusterTanzuConfig
 func (provider *Provider) GetTanzuConfig(clusterName string) tanzu.TanzuConfig {
 	return tanzu.TanzuConfig{
 		"CLUSTER_NAME":                     clusterName,
@@ -86,3 +104,9 @@ func (provider *Provider) GetTanzuConfig(clusterName string) tanzu.TanzuConfig {
 
 // TODO: Change name?
 var PROVIDER utils.Provider = &Provider{}
+
+func init() {
+	utils.RegisterProvider("Microsoft Azure", func() utils.Provider {
+		return &Provider{}
+	})
+}
@@ -0,0 +1,228 @@
+package capi
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// WorkerMachines returns the worker Machines of the cluster, which are the Machines of its MachineDeployments,
+// sorted by name
+func WorkerMachines(ctx context.Context, c client.Reader, clusterName string) ([]clusterv1.Machine, error) {
+	cluster, err := FindCluster(ctx, c, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	return workerMachines(ctx, c, cluster)
+}
+
+func workerMachines(ctx context.Context, c client.Reader, cluster *clusterv1.Cluster) ([]clusterv1.Machine, error) {
+	machines := &clusterv1.MachineList{}
+	err := c.List(ctx, machines,
+		client.InNamespace(cluster.Namespace),
+		client.MatchingLabels{clusterv1.ClusterLabelName: cluster.Name},
+		client.HasLabels{clusterv1.MachineDeploymentLabelName},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error while listing worker Machines of CAPI cluster %s: %v", cluster.Name, err)
+	}
+
+	sort.Slice(machines.Items, func(i, j int) bool {
+		return machines.Items[i].Name < machines.Items[j].Name
+	})
+	return machines.Items, nil
+}
+
+// CheckWorkerMachines checks that the MachineDeployments of the cluster have the worker count of replicas in all,
+// all of them ready and up to date, and that the cluster has the worker count of worker Machines, all of them
+// running with a node. It returns an error with everything that doesn't match
+func CheckWorkerMachines(ctx context.Context, c client.Reader, clusterName string, workerCount int32) error {
+	cluster, err := FindCluster(ctx, c, clusterName)
+	if err != nil {
+		return err
+	}
+
+	machineDeployments := &clusterv1.MachineDeploymentList{}
+	err = c.List(ctx, machineDeployments,
+		client.InNamespace(cluster.Namespace),
+		client.MatchingLabels{clusterv1.ClusterLabelName: cluster.Name},
+	)
+	if err != nil {
+		return fmt.Errorf("error while listing MachineDeployments of CAPI cluster %s: %v", clusterName, err)
+	}
+
+	problems := []string{}
+	totalReplicas := int32(0)
+	for _, machineDeployment := range machineDeployments.Items {
+		replicas := replicasOrDefault(machineDeployment.Spec.Replicas)
+		totalReplicas += replicas
+		if machineDeployment.Status.ReadyReplicas != replicas {
+			problems = append(problems, fmt.Sprintf("MachineDeployment %s has %d of %d replicas ready", machineDeployment.Name, machineDeployment.Status.ReadyReplicas, replicas))
+		}
+		if machineDeployment.Status.UpdatedReplicas != replicas {
+			problems = append(problems, fmt.Sprintf("MachineDeployment %s has %d of %d replicas up to date", machineDeployment.Name, machineDeployment.Status.UpdatedReplicas, replicas))
+		}
+	}
+	if totalReplicas != workerCount {
+		problems = append(problems, fmt.Sprintf("MachineDeployments have %d replicas, expected %d", totalReplicas, workerCount))
+	}
+
+	machines, err := workerMachines(ctx, c, cluster)
+	if err != nil {
+		return err
+	}
+	if int32(len(machines)) != workerCount {
+		problems = append(problems, fmt.Sprintf("cluster has %d worker Machines, expected %d", len(machines), workerCount))
+	}
+	for _, machine := range machines {
+		problems = append(problems, machineProblems(machine)...)
+	}
+
+	if len(problems) != 0 {
+		return fmt.Errorf("worker Machines of CAPI cluster %s are not as expected: %s", clusterName, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// CheckClusterVersion checks that the cluster is ready according to CheckClusterReady, and that its control plane,
+// MachineDeployments and all its Machines are at the Kubernetes version, like v1.22.8+vmware.1
+func CheckClusterVersion(ctx context.Context, c client.Reader, clusterName string, version string) error {
+	err := CheckClusterReady(ctx, c, clusterName)
+	if err != nil {
+		return err
+	}
+
+	cluster, err := FindCluster(ctx, c, clusterName)
+	if err != nil {
+		return err
+	}
+
+	problems := []string{}
+
+	controlPlane, err := getKubeadmControlPlane(ctx, c, cluster)
+	if err != nil {
+		return err
+	}
+	if controlPlane != nil {
+		if controlPlane.Spec.Version != version {
+			problems = append(problems, fmt.Sprintf("KubeadmControlPlane %s is at Kubernetes version %s, expected %s", controlPlane.Name, controlPlane.Spec.Version, version))
+		}
+		if controlPlane.Status.Version == nil || *controlPlane.Status.Version != version {
+			problems = append(problems, fmt.Sprintf("KubeadmControlPlane %s has machines not at Kubernetes version %s yet", controlPlane.Name, version))
+		}
+	}
+
+	listOptions := []client.ListOption{
+		client.InNamespace(cluster.Namespace),
+		client.MatchingLabels{clusterv1.ClusterLabelName: cluster.Name},
+	}
+
+	machineDeployments := &clusterv1.MachineDeploymentList{}
+	err = c.List(ctx, machineDeployments, listOptions...)
+	if err != nil {
+		return fmt.Errorf("error while listing MachineDeployments of CAPI cluster %s: %v", clusterName, err)
+	}
+	for _, machineDeployment := range machineDeployments.Items {
+		machineDeploymentVersion := versionOrEmpty(machineDeployment.Spec.Template.Spec.Version)
+		if machineDeploymentVersion != version {
+			problems = append(problems, fmt.Sprintf("MachineDeployment %s is at Kubernetes version %s, expected %s", machineDeployment.Name, machineDeploymentVersion, version))
+		}
+	}
+
+	machines := &clusterv1.MachineList{}
+	err = c.List(ctx, machines, listOptions...)
+	if err != nil {
+		return fmt.Errorf("error while listing Machines of CAPI cluster %s: %v", clusterName, err)
+	}
+	for _, machine := range machines.Items {
+		machineVersion := versionOrEmpty(machine.Spec.Version)
+		if machineVersion != version {
+			problems = append(problems, fmt.Sprintf("Machine %s is at Kubernetes version %s, expected %s", machine.Name, machineVersion, version))
+		}
+	}
+
+	if len(problems) != 0 {
+		return fmt.Errorf("CAPI cluster %s is not at Kubernetes version %s: %s", clusterName, version, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// ClusterVersion returns the Kubernetes version of the control plane of the cluster, like v1.22.8+vmware.1
+func ClusterVersion(ctx context.Context, c client.Reader, clusterName string) (string, error) {
+	cluster, err := FindCluster(ctx, c, clusterName)
+	if err != nil {
+		return "", err
+	}
+
+	controlPlane, err := getKubeadmControlPlane(ctx, c, cluster)
+	if err != nil {
+		return "", err
+	}
+	if controlPlane == nil {
+		return "", fmt.Errorf("CAPI cluster %s has no KubeadmControlPlane", clusterName)
+	}
+	return controlPlane.Spec.Version, nil
+}
+
+// CheckMachineHealthCheck checks that the cluster has a MachineHealthCheck, which remediates its unhealthy Machines
+func CheckMachineHealthCheck(ctx context.Context, c client.Reader, clusterName string) error {
+	cluster, err := FindCluster(ctx, c, clusterName)
+	if err != nil {
+		return err
+	}
+
+	machineHealthChecks := &clusterv1.MachineHealthCheckList{}
+	err = c.List(ctx, machineHealthChecks, client.InNamespace(cluster.Namespace))
+	if err != nil {
+		return fmt.Errorf("error while listing MachineHealthChecks of CAPI cluster %s: %v", clusterName, err)
+	}
+	for _, machineHealthCheck := range machineHealthChecks.Items {
+		if machineHealthCheck.Spec.ClusterName == clusterName {
+			return nil
+		}
+	}
+	return fmt.Errorf("CAPI cluster %s has no MachineHealthCheck", clusterName)
+}
+
+// CheckMachineReplaced checks that the worker Machine is gone, and that the cluster has the worker count of worker
+// Machines again according to CheckWorkerMachines
+func CheckMachineReplaced(ctx context.Context, c client.Reader, clusterName string, machineName string, workerCount int32) error {
+	machines, err := WorkerMachines(ctx, c, clusterName)
+	if err != nil {
+		return err
+	}
+	for _, machine := range machines {
+		if machine.Name == machineName {
+			return fmt.Errorf("Machine %s of CAPI cluster %s is not replaced yet, it's in %s phase", machineName, clusterName, machine.Status.Phase)
+		}
+	}
+
+	return CheckWorkerMachines(ctx, c, clusterName, workerCount)
+}
+
+// machineProblems returns the problems of the Machine, when it's being deleted, not running or has no node
+func machineProblems(machine clusterv1.Machine) []string {
+	problems := []string{}
+	if !machine.DeletionTimestamp.IsZero() {
+		problems = append(problems, fmt.Sprintf("Machine %s is being deleted", machine.Name))
+	}
+	if machine.Status.Phase != string(clusterv1.MachinePhaseRunning) {
+		problems = append(problems, fmt.Sprintf("Machine %s is in %s phase, expected %s", machine.Name, machine.Status.Phase, clusterv1.MachinePhaseRunning))
+	}
+	if machine.Status.NodeRef == nil {
+		problems = append(problems, fmt.Sprintf("Machine %s has no node", machine.Name))
+	}
+	return problems
+}
+
+// versionOrEmpty returns the version, which is empty when not set
+func versionOrEmpty(version *string) string {
+	if version == nil {
+		return ""
+	}
+	return *version
+}
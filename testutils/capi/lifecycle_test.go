@@ -0,0 +1,131 @@
+package capi_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiControlplaneKubeadmv1beta "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/karuppiah7890/tce-e2e-test/testutils/capi"
+)
+
+// readyClusterObjectsAtVersion returns the objects of a ready cluster with one worker Machine, all of them at the
+// Kubernetes version
+func readyClusterObjectsAtVersion(version string) []client.Object {
+	objects := readyClusterObjects()
+
+	controlPlane := objects[1].(*capiControlplaneKubeadmv1beta.KubeadmControlPlane)
+	controlPlane.Spec.Version = version
+	controlPlane.Status.Version = &version
+
+	machineDeployment := objects[2].(*clusterv1.MachineDeployment)
+	machineDeployment.Spec.Template.Spec.Version = &version
+	machineDeployment.Status.UpdatedReplicas = 1
+
+	machine := objects[3].(*clusterv1.Machine)
+	machine.Labels[clusterv1.MachineDeploymentLabelName] = "test-wkld-md-0"
+	machine.Spec.Version = &version
+
+	return objects
+}
+
+func TestCheckWorkerMachines(t *testing.T) {
+	t.Run("when the cluster has the worker count of ready workers it should return no error", func(t *testing.T) {
+		err := capi.CheckWorkerMachines(context.Background(), newClient(readyClusterObjectsAtVersion("v1.22.8+vmware.1")...), "test-wkld", 1)
+		if err != nil {
+			t.Errorf("expected no error but got: %v", err)
+		}
+	})
+
+	t.Run("when the cluster is not scaled to the worker count yet it should return an error with the problems", func(t *testing.T) {
+		err := capi.CheckWorkerMachines(context.Background(), newClient(readyClusterObjectsAtVersion("v1.22.8+vmware.1")...), "test-wkld", 3)
+		if err == nil {
+			t.Fatalf("expected an error but got none")
+		}
+		for _, problem := range []string{
+			"MachineDeployments have 1 replicas, expected 3",
+			"cluster has 1 worker Machines, expected 3",
+		} {
+			if !strings.Contains(err.Error(), problem) {
+				t.Errorf("expected error to contain %q but got: %v", problem, err)
+			}
+		}
+	})
+}
+
+func TestCheckClusterVersion(t *testing.T) {
+	t.Run("when the cluster is at the version it should return no error", func(t *testing.T) {
+		err := capi.CheckClusterVersion(context.Background(), newClient(readyClusterObjectsAtVersion("v1.22.8+vmware.1")...), "test-wkld", "v1.22.8+vmware.1")
+		if err != nil {
+			t.Errorf("expected no error but got: %v", err)
+		}
+	})
+
+	t.Run("when the workers are not upgraded yet it should return an error with the problems", func(t *testing.T) {
+		objects := readyClusterObjectsAtVersion("v1.22.8+vmware.1")
+		oldVersion := "v1.21.11+vmware.1"
+		objects[2].(*clusterv1.MachineDeployment).Spec.Template.Spec.Version = &oldVersion
+		objects[3].(*clusterv1.Machine).Spec.Version = &oldVersion
+
+		err := capi.CheckClusterVersion(context.Background(), newClient(objects...), "test-wkld", "v1.22.8+vmware.1")
+		if err == nil {
+			t.Fatalf("expected an error but got none")
+		}
+		for _, problem := range []string{
+			"MachineDeployment test-wkld-md-0 is at Kubernetes version v1.21.11+vmware.1, expected v1.22.8+vmware.1",
+			"Machine test-wkld-md-0-abcde is at Kubernetes version v1.21.11+vmware.1, expected v1.22.8+vmware.1",
+		} {
+			if !strings.Contains(err.Error(), problem) {
+				t.Errorf("expected error to contain %q but got: %v", problem, err)
+			}
+		}
+	})
+}
+
+func TestCheckMachineReplaced(t *testing.T) {
+	t.Run("when the machine is still there it should return an error", func(t *testing.T) {
+		err := capi.CheckMachineReplaced(context.Background(), newClient(readyClusterObjectsAtVersion("v1.22.8+vmware.1")...), "test-wkld", "test-wkld-md-0-abcde", 1)
+		if err == nil || !strings.Contains(err.Error(), "Machine test-wkld-md-0-abcde of CAPI cluster test-wkld is not replaced yet") {
+			t.Errorf("expected error about the machine not being replaced but got: %v", err)
+		}
+	})
+
+	t.Run("when another machine replaced the machine it should return no error", func(t *testing.T) {
+		objects := readyClusterObjectsAtVersion("v1.22.8+vmware.1")
+		objects[3].(*clusterv1.Machine).ObjectMeta = metav1.ObjectMeta{
+			Name:      "test-wkld-md-0-fghij",
+			Namespace: "default",
+			Labels:    objects[3].GetLabels(),
+		}
+
+		err := capi.CheckMachineReplaced(context.Background(), newClient(objects...), "test-wkld", "test-wkld-md-0-abcde", 1)
+		if err != nil {
+			t.Errorf("expected no error but got: %v", err)
+		}
+	})
+}
+
+func TestCheckMachineHealthCheck(t *testing.T) {
+	t.Run("when the cluster has no MachineHealthCheck it should return an error", func(t *testing.T) {
+		err := capi.CheckMachineHealthCheck(context.Background(), newClient(readyClusterObjects()...), "test-wkld")
+		if err == nil {
+			t.Errorf("expected an error but got none")
+		}
+	})
+
+	t.Run("when the cluster has a MachineHealthCheck it should return no error", func(t *testing.T) {
+		objects := append(readyClusterObjects(), &clusterv1.MachineHealthCheck{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-wkld", Namespace: "default"},
+			Spec:       clusterv1.MachineHealthCheckSpec{ClusterName: "test-wkld"},
+		})
+
+		err := capi.CheckMachineHealthCheck(context.Background(), newClient(objects...), "test-wkld")
+		if err != nil {
+			t.Errorf("expected no error but got: %v", err)
+		}
+	})
+}
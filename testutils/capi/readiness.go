@@ -0,0 +1,196 @@
+package capi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiControlplaneKubeadmv1beta "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/karuppiah7890/tce-e2e-test/testutils/poll"
+)
+
+// ErrClusterNotFound is returned, wrapped, when there's no CAPI Cluster with the name of the cluster
+var ErrClusterNotFound = errors.New("cluster not found")
+
+// FindCluster finds the CAPI Cluster with the name of the cluster in any namespace of the management cluster, as
+// workload clusters can be created in any namespace
+func FindCluster(ctx context.Context, c client.Reader, clusterName string) (*clusterv1.Cluster, error) {
+	clusters := &clusterv1.ClusterList{}
+	err := c.List(ctx, clusters)
+	if err != nil {
+		return nil, fmt.Errorf("error while listing CAPI clusters: %v", err)
+	}
+
+	for i := range clusters.Items {
+		if clusters.Items[i].Name == clusterName {
+			return &clusters.Items[i], nil
+		}
+	}
+	return nil, fmt.Errorf("CAPI cluster %s: %w", clusterName, ErrClusterNotFound)
+}
+
+// CheckClusterReady checks that the cluster, its KubeadmControlPlane, MachineDeployments and Machines are all
+// ready according to their CAPI status and conditions, and returns an error with everything that is not ready.
+// It returns an error wrapped using poll.Stop when the cluster is being deleted, as waiting won't make it ready
+func CheckClusterReady(ctx context.Context, c client.Reader, clusterName string) error {
+	cluster, err := FindCluster(ctx, c, clusterName)
+	if err != nil {
+		return err
+	}
+	if !cluster.DeletionTimestamp.IsZero() {
+		return poll.Stop(fmt.Errorf("CAPI cluster %s is being deleted", clusterName))
+	}
+
+	problems := []string{}
+
+	if cluster.Status.Phase != string(clusterv1.ClusterPhaseProvisioned) {
+		problems = append(problems, fmt.Sprintf("Cluster %s is in %s phase, expected %s", cluster.Name, cluster.Status.Phase, clusterv1.ClusterPhaseProvisioned))
+	}
+	problems = appendConditionProblem(problems, "Cluster", cluster.Name, cluster.Status.Conditions, clusterv1.ReadyCondition)
+
+	controlPlaneProblems, err := checkControlPlaneReady(ctx, c, cluster)
+	if err != nil {
+		return err
+	}
+	problems = append(problems, controlPlaneProblems...)
+
+	listOptions := []client.ListOption{
+		client.InNamespace(cluster.Namespace),
+		client.MatchingLabels{clusterv1.ClusterLabelName: cluster.Name},
+	}
+
+	machineDeployments := &clusterv1.MachineDeploymentList{}
+	err = c.List(ctx, machineDeployments, listOptions...)
+	if err != nil {
+		return fmt.Errorf("error while listing MachineDeployments of CAPI cluster %s: %v", clusterName, err)
+	}
+	for _, machineDeployment := range machineDeployments.Items {
+		replicas := replicasOrDefault(machineDeployment.Spec.Replicas)
+		if machineDeployment.Status.ReadyReplicas != replicas {
+			problems = append(problems, fmt.Sprintf("MachineDeployment %s has %d of %d replicas ready", machineDeployment.Name, machineDeployment.Status.ReadyReplicas, replicas))
+		}
+		problems = appendConditionProblem(problems, "MachineDeployment", machineDeployment.Name, machineDeployment.Status.Conditions, clusterv1.MachineDeploymentAvailableCondition)
+	}
+
+	machines := &clusterv1.MachineList{}
+	err = c.List(ctx, machines, listOptions...)
+	if err != nil {
+		return fmt.Errorf("error while listing Machines of CAPI cluster %s: %v", clusterName, err)
+	}
+	if len(machines.Items) == 0 {
+		problems = append(problems, "no Machines found")
+	}
+	for _, machine := range machines.Items {
+		if machine.Status.Phase != string(clusterv1.MachinePhaseRunning) {
+			problems = append(problems, fmt.Sprintf("Machine %s is in %s phase, expected %s", machine.Name, machine.Status.Phase, clusterv1.MachinePhaseRunning))
+		}
+		if machine.Status.NodeRef == nil {
+			problems = append(problems, fmt.Sprintf("Machine %s has no node", machine.Name))
+		}
+		problems = appendConditionProblem(problems, "Machine", machine.Name, machine.Status.Conditions, clusterv1.ReadyCondition)
+	}
+
+	if len(problems) != 0 {
+		return fmt.Errorf("CAPI cluster %s is not ready: %s", clusterName, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// checkControlPlaneReady returns the problems of the control plane of the cluster, when it's a KubeadmControlPlane
+func checkControlPlaneReady(ctx context.Context, c client.Reader, cluster *clusterv1.Cluster) ([]string, error) {
+	if cluster.Spec.ControlPlaneRef == nil {
+		return []string{fmt.Sprintf("Cluster %s has no control plane", cluster.Name)}, nil
+	}
+	controlPlane, err := getKubeadmControlPlane(ctx, c, cluster)
+	if err != nil || controlPlane == nil {
+		return nil, err
+	}
+
+	problems := []string{}
+	replicas := replicasOrDefault(controlPlane.Spec.Replicas)
+	if controlPlane.Status.ReadyReplicas != replicas {
+		problems = append(problems, fmt.Sprintf("KubeadmControlPlane %s has %d of %d replicas ready", controlPlane.Name, controlPlane.Status.ReadyReplicas, replicas))
+	}
+	problems = appendConditionProblem(problems, "KubeadmControlPlane", controlPlane.Name, controlPlane.Status.Conditions, clusterv1.ReadyCondition)
+	return problems, nil
+}
+
+// getKubeadmControlPlane returns the KubeadmControlPlane of the cluster, or nil when the cluster has another kind
+// of control plane or no control plane
+func getKubeadmControlPlane(ctx context.Context, c client.Reader, cluster *clusterv1.Cluster) (*capiControlplaneKubeadmv1beta.KubeadmControlPlane, error) {
+	ref := cluster.Spec.ControlPlaneRef
+	if ref == nil || ref.Kind != "KubeadmControlPlane" {
+		return nil, nil
+	}
+
+	namespace := ref.Namespace
+	if namespace == "" {
+		namespace = cluster.Namespace
+	}
+	controlPlane := &capiControlplaneKubeadmv1beta.KubeadmControlPlane{}
+	err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ref.Name}, controlPlane)
+	if err != nil {
+		return nil, fmt.Errorf("error while getting KubeadmControlPlane %s of CAPI cluster %s: %v", ref.Name, cluster.Name, err)
+	}
+	return controlPlane, nil
+}
+
+// CheckClusterDeleted checks that the CAPI Cluster of the cluster no longer exists
+func CheckClusterDeleted(ctx context.Context, c client.Reader, clusterName string) error {
+	cluster, err := FindCluster(ctx, c, clusterName)
+	if errors.Is(err, ErrClusterNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("CAPI cluster %s still exists in %s phase", clusterName, cluster.Status.Phase)
+}
+
+// WaitForClusterReady polls until the cluster is ready according to CheckClusterReady
+func WaitForClusterReady(ctx context.Context, c client.Reader, clusterName string, options poll.Options) error {
+	return poll.Until(ctx, options, func(ctx context.Context) error {
+		return CheckClusterReady(ctx, c, clusterName)
+	})
+}
+
+// WaitForClusterDeletion polls until the cluster is deleted according to CheckClusterDeleted
+func WaitForClusterDeletion(ctx context.Context, c client.Reader, clusterName string, options poll.Options) error {
+	return poll.Until(ctx, options, func(ctx context.Context) error {
+		return CheckClusterDeleted(ctx, c, clusterName)
+	})
+}
+
+// appendConditionProblem appends the problem with the condition of the object, if it's not true
+func appendConditionProblem(problems []string, kind, name string, conditions clusterv1.Conditions, conditionType clusterv1.ConditionType) []string {
+	for _, condition := range conditions {
+		if condition.Type != conditionType {
+			continue
+		}
+		if condition.Status == corev1.ConditionTrue {
+			return problems
+		}
+		problem := fmt.Sprintf("%s %s is not %s", kind, name, conditionType)
+		if condition.Reason != "" {
+			problem = fmt.Sprintf("%s, reason: %s", problem, condition.Reason)
+		}
+		if condition.Message != "" {
+			problem = fmt.Sprintf("%s, message: %s", problem, condition.Message)
+		}
+		return append(problems, problem)
+	}
+	return append(problems, fmt.Sprintf("%s %s has no %s condition", kind, name, conditionType))
+}
+
+// replicasOrDefault returns the replicas, which default to 1 when not set
+func replicasOrDefault(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
@@ -0,0 +1,121 @@
+package capi_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiControlplaneKubeadmv1beta "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/karuppiah7890/tce-e2e-test/testutils/capi"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/kubescheme"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/poll"
+)
+
+var ready = clusterv1.Conditions{{Type: clusterv1.ReadyCondition, Status: corev1.ConditionTrue}}
+
+func readyClusterObjects() []client.Object {
+	replicas := int32(1)
+	labels := map[string]string{clusterv1.ClusterLabelName: "test-wkld"}
+
+	return []client.Object{
+		&clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-wkld", Namespace: "default"},
+			Spec: clusterv1.ClusterSpec{
+				ControlPlaneRef: &corev1.ObjectReference{Kind: "KubeadmControlPlane", Name: "test-wkld-control-plane"},
+			},
+			Status: clusterv1.ClusterStatus{Phase: string(clusterv1.ClusterPhaseProvisioned), Conditions: ready},
+		},
+		&capiControlplaneKubeadmv1beta.KubeadmControlPlane{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-wkld-control-plane", Namespace: "default", Labels: labels},
+			Spec:       capiControlplaneKubeadmv1beta.KubeadmControlPlaneSpec{Replicas: &replicas},
+			Status:     capiControlplaneKubeadmv1beta.KubeadmControlPlaneStatus{ReadyReplicas: 1, Conditions: ready},
+		},
+		&clusterv1.MachineDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-wkld-md-0", Namespace: "default", Labels: labels},
+			Spec:       clusterv1.MachineDeploymentSpec{Replicas: &replicas},
+			Status: clusterv1.MachineDeploymentStatus{
+				ReadyReplicas: 1,
+				Conditions:    clusterv1.Conditions{{Type: clusterv1.MachineDeploymentAvailableCondition, Status: corev1.ConditionTrue}},
+			},
+		},
+		&clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-wkld-md-0-abcde", Namespace: "default", Labels: labels},
+			Status: clusterv1.MachineStatus{
+				Phase:      string(clusterv1.MachinePhaseRunning),
+				NodeRef:    &corev1.ObjectReference{Name: "test-wkld-md-0-abcde"},
+				Conditions: ready,
+			},
+		},
+	}
+}
+
+func newClient(objects ...client.Object) client.Client {
+	return fake.NewClientBuilder().WithScheme(kubescheme.GetScheme()).WithObjects(objects...).Build()
+}
+
+func TestCheckClusterReady(t *testing.T) {
+	t.Run("when the cluster and all its machines are ready it should return no error", func(t *testing.T) {
+		err := capi.CheckClusterReady(context.Background(), newClient(readyClusterObjects()...), "test-wkld")
+		if err != nil {
+			t.Errorf("expected no error but got: %v", err)
+		}
+	})
+
+	t.Run("when the control plane and a machine are not ready it should return an error with the problems", func(t *testing.T) {
+		objects := readyClusterObjects()
+		controlPlane := objects[1].(*capiControlplaneKubeadmv1beta.KubeadmControlPlane)
+		controlPlane.Status.ReadyReplicas = 0
+		controlPlane.Status.Conditions = clusterv1.Conditions{{Type: clusterv1.ReadyCondition, Status: corev1.ConditionFalse, Reason: "ScalingUp"}}
+		machine := objects[3].(*clusterv1.Machine)
+		machine.Status.Phase = string(clusterv1.MachinePhaseProvisioning)
+		machine.Status.NodeRef = nil
+
+		err := capi.CheckClusterReady(context.Background(), newClient(objects...), "test-wkld")
+		if err == nil {
+			t.Fatalf("expected an error but got none")
+		}
+		for _, problem := range []string{
+			"KubeadmControlPlane test-wkld-control-plane has 0 of 1 replicas ready",
+			"KubeadmControlPlane test-wkld-control-plane is not Ready, reason: ScalingUp",
+			"Machine test-wkld-md-0-abcde is in Provisioning phase, expected Running",
+			"Machine test-wkld-md-0-abcde has no node",
+		} {
+			if !strings.Contains(err.Error(), problem) {
+				t.Errorf("expected error to contain %q but got: %v", problem, err)
+			}
+		}
+	})
+
+	t.Run("when the cluster does not exist it should return a cluster not found error", func(t *testing.T) {
+		err := capi.CheckClusterReady(context.Background(), newClient(), "test-wkld")
+		if !errors.Is(err, capi.ErrClusterNotFound) {
+			t.Errorf("expected a cluster not found error but got: %v", err)
+		}
+	})
+}
+
+func TestWaitForClusterDeletion(t *testing.T) {
+	options := poll.Options{Interval: time.Millisecond, Timeout: 20 * time.Millisecond}
+
+	t.Run("when the cluster does not exist it should return no error", func(t *testing.T) {
+		err := capi.WaitForClusterDeletion(context.Background(), newClient(), "test-wkld", options)
+		if err != nil {
+			t.Errorf("expected no error but got: %v", err)
+		}
+	})
+
+	t.Run("when the cluster is not deleted before the timeout it should return a timeout error", func(t *testing.T) {
+		err := capi.WaitForClusterDeletion(context.Background(), newClient(readyClusterObjects()...), "test-wkld", options)
+		if !errors.Is(err, poll.ErrTimeout) {
+			t.Errorf("expected a timeout error but got: %v", err)
+		}
+	})
+}
@@ -0,0 +1,10 @@
+package cleanup
+
+import "strings"
+
+// IsNamedForCluster returns whether the name of a resource is the cluster name or starts with the cluster name
+// followed by a dash, like the resources providers name after the cluster. The dash ensures that test-mgmt-1 does
+// not match the resources of test-mgmt-10
+func IsNamedForCluster(name, clusterName string) bool {
+	return name == clusterName || strings.HasPrefix(name, clusterName+"-")
+}
@@ -0,0 +1,29 @@
+package cleanup_test
+
+import (
+	"testing"
+
+	"github.com/karuppiah7890/tce-e2e-test/testutils/cleanup"
+)
+
+func TestIsNamedForCluster(t *testing.T) {
+	testCases := []struct {
+		name          string
+		resourceName  string
+		expectedMatch bool
+	}{
+		{name: "when the name is the cluster name it should match", resourceName: "test-mgmt-1", expectedMatch: true},
+		{name: "when the name starts with the cluster name and a dash it should match", resourceName: "test-mgmt-1-control-plane-x7rtz", expectedMatch: true},
+		{name: "when the name starts with a longer cluster name it should not match", resourceName: "test-mgmt-10-control-plane-x7rtz", expectedMatch: false},
+		{name: "when the name is of another cluster it should not match", resourceName: "test-wkld-1", expectedMatch: false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			match := cleanup.IsNamedForCluster(testCase.resourceName, "test-mgmt-1")
+			if match != testCase.expectedMatch {
+				t.Errorf("expected %s to match the cluster to be %v but got %v", testCase.resourceName, testCase.expectedMatch, match)
+			}
+		})
+	}
+}
@@ -0,0 +1,272 @@
+package cleanup
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/karuppiah7890/tce-e2e-test/testutils/log"
+)
+
+// DefaultTimeout is the maximum time given to a cleanup step when it's pushed with no timeout
+const DefaultTimeout = 10 * time.Minute
+
+// Resource identifies a resource created during a test run, like a cluster or a kube context, by its
+// kind and name, so that it can be cleaned up by another process using only its kind and name
+type Resource struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+func (resource Resource) String() string {
+	return fmt.Sprintf("%s %s", resource.Kind, resource.Name)
+}
+
+// Tracker keeps track of the resources of the cleanup steps pushed using PushResource, like the state file
+// of a test run, so that the resources which are not cleaned up can be found after the test run dies
+type Tracker interface {
+	// Created is called when the cleanup step of the resource is pushed
+	Created(resource Resource) error
+	// Deleted is called when the cleanup step of the resource runs successfully or is released
+	Deleted(resource Resource) error
+}
+
+// Step is a cleanup step which undoes the creation of a resource, like deleting a kube context
+// or a cloud resource. Each step runs with its own timeout
+type Step struct {
+	Name    string
+	Timeout time.Duration
+	undo    func(ctx context.Context) error
+	// resource is the resource of the step, when it's pushed using PushResource
+	resource *Resource
+	// deleted is called once the resource of the step is deleted, when the step has a resource
+	deleted func()
+
+	mutex    sync.Mutex
+	released bool
+}
+
+// Release marks the step as not needed anymore, for example when the test run itself deleted the
+// resource. A released step does not run when the stack is unwound
+func (step *Step) Release() {
+	if step == nil {
+		return
+	}
+
+	step.mutex.Lock()
+	alreadyReleased := step.released
+	step.released = true
+	step.mutex.Unlock()
+
+	if !alreadyReleased && step.deleted != nil {
+		step.deleted()
+	}
+}
+
+func (step *Step) isReleased() bool {
+	step.mutex.Lock()
+	defer step.mutex.Unlock()
+
+	return step.released
+}
+
+// run runs the undo of the step and waits for it at most for the step's timeout, even when the undo
+// doesn't stop once its context is done. A panic in the undo is returned as an error so that it
+// doesn't stop the rest of the steps from running
+func (step *Step) run() error {
+	ctx, cancel := context.WithTimeout(context.Background(), step.Timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("panic while running cleanup step: %v", r)
+			}
+		}()
+		done <- step.undo(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("cleanup step did not finish within %v", step.Timeout)
+	}
+}
+
+// Recorder runs a cleanup step and records its outcome, like report.Report's RunPhase method
+type Recorder func(name string, run func() error) error
+
+// Stack is a LIFO registry of cleanup steps for the resources created during a test run, so that
+// resources are cleaned up in the reverse order of their creation. It's safe for concurrent use
+type Stack struct {
+	mutex   sync.Mutex
+	steps   []*Step
+	logger  *log.Logger
+	tracker Tracker
+}
+
+// NewStack creates an empty cleanup stack which logs using the logger
+func NewStack(logger *log.Logger) *Stack {
+	return &Stack{
+		steps:  []*Step{},
+		logger: logger,
+	}
+}
+
+// Push registers a cleanup step to undo the creation of a resource. The step runs with the timeout,
+// or with DefaultTimeout if the timeout is zero, when the stack is unwound
+func (stack *Stack) Push(name string, timeout time.Duration, undo func(ctx context.Context) error) *Step {
+	return stack.push(name, timeout, undo, nil)
+}
+
+func (stack *Stack) push(name string, timeout time.Duration, undo func(ctx context.Context) error, resource *Resource) *Step {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	step := &Step{
+		Name:     name,
+		Timeout:  timeout,
+		undo:     undo,
+		resource: resource,
+	}
+	if resource != nil {
+		step.deleted = func() {
+			stack.track("deletion", *resource, stack.getTracker().Deleted)
+		}
+	}
+
+	stack.mutex.Lock()
+	defer stack.mutex.Unlock()
+
+	stack.steps = append(stack.steps, step)
+	stack.logger.Infof("Registered cleanup step: %s", name)
+
+	return step
+}
+
+// PushResource registers a cleanup step to undo the creation of the resource, like Push, and tells the
+// tracker of the stack, if any, that the resource is created. When the stack already has a step for the
+// resource which is not released, like one restored from the state of a test run being resumed, that step
+// is returned instead
+func (stack *Stack) PushResource(name string, resource Resource, timeout time.Duration, undo func(ctx context.Context) error) *Step {
+	if step := stack.findStep(resource); step != nil {
+		return step
+	}
+
+	step := stack.push(name, timeout, undo, &resource)
+	stack.track("creation", resource, stack.getTracker().Created)
+	return step
+}
+
+func (stack *Stack) findStep(resource Resource) *Step {
+	stack.mutex.Lock()
+	defer stack.mutex.Unlock()
+
+	for _, step := range stack.steps {
+		if step.resource != nil && *step.resource == resource && !step.isReleased() {
+			return step
+		}
+	}
+	return nil
+}
+
+// SetTracker sets the tracker of the resources of the cleanup steps pushed from now on
+func (stack *Stack) SetTracker(tracker Tracker) {
+	stack.mutex.Lock()
+	defer stack.mutex.Unlock()
+
+	stack.tracker = tracker
+}
+
+func (stack *Stack) getTracker() Tracker {
+	stack.mutex.Lock()
+	defer stack.mutex.Unlock()
+
+	if stack.tracker == nil {
+		return noTracker{}
+	}
+	return stack.tracker
+}
+
+// track tells the tracker about the resource, and only logs any error as tracking the resources must not stop
+// the test run or its cleanup
+func (stack *Stack) track(event string, resource Resource, track func(resource Resource) error) {
+	err := track(resource)
+	if err != nil {
+		stack.logger.Errorf("error while tracking %s of %s: %v", event, resource, err)
+	}
+}
+
+type noTracker struct{}
+
+func (noTracker) Created(resource Resource) error { return nil }
+func (noTracker) Deleted(resource Resource) error { return nil }
+
+// Len returns the number of steps in the stack, including the released ones
+func (stack *Stack) Len() int {
+	stack.mutex.Lock()
+	defer stack.mutex.Unlock()
+
+	return len(stack.steps)
+}
+
+func (stack *Stack) pop() (*Step, bool) {
+	stack.mutex.Lock()
+	defer stack.mutex.Unlock()
+
+	if len(stack.steps) == 0 {
+		return nil, false
+	}
+
+	step := stack.steps[len(stack.steps)-1]
+	stack.steps = stack.steps[:len(stack.steps)-1]
+	return step, true
+}
+
+// Unwind runs all the steps that are not released, in the reverse order of their registration, and
+// empties the stack. Every step runs even if the steps before it fail. Each step is run using the
+// recorder when it's not nil, so that the outcome of every step can be recorded. The resource of a step
+// which runs successfully is tracked as deleted. Unwind returns an error with the errors of all the
+// failed steps, or nil if no step failed
+func (stack *Stack) Unwind(record Recorder) error {
+	errs := []string{}
+
+	for {
+		step, ok := stack.pop()
+		if !ok {
+			break
+		}
+
+		if step.isReleased() {
+			continue
+		}
+
+		stack.logger.Infof("Running cleanup step: %s", step.Name)
+
+		var err error
+		if record != nil {
+			err = record(step.Name, step.run)
+		} else {
+			err = step.run()
+		}
+
+		if err != nil {
+			stack.logger.Errorf("error while running cleanup step %s: %v", step.Name, err)
+			errs = append(errs, fmt.Sprintf("%s: %v", step.Name, err))
+			continue
+		}
+		if step.deleted != nil {
+			step.deleted()
+		}
+	}
+
+	if len(errs) != 0 {
+		return fmt.Errorf("errors while running cleanup steps: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package cleanup_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/karuppiah7890/tce-e2e-test/testutils/cleanup"
+)
+
+func TestStack(t *testing.T) {
+	t.Run("it should run the steps that are not released in the reverse order of registration", func(t *testing.T) {
+		stack := cleanup.NewStack(nil)
+		ran := []string{}
+		undo := func(name string) func(ctx context.Context) error {
+			return func(ctx context.Context) error {
+				ran = append(ran, name)
+				return nil
+			}
+		}
+
+		stack.Push("delete management cluster", time.Minute, undo("delete management cluster"))
+		stack.Push("delete bootstrap cluster", time.Minute, undo("delete bootstrap cluster")).Release()
+		stack.Push("delete workload cluster", time.Minute, undo("delete workload cluster"))
+
+		err := stack.Unwind(nil)
+		if err != nil {
+			t.Fatalf("expected no error while unwinding but got: %v", err)
+		}
+
+		expectedRan := []string{"delete workload cluster", "delete management cluster"}
+		if fmt.Sprint(ran) != fmt.Sprint(expectedRan) {
+			t.Errorf("expected steps %v to run but got %v", expectedRan, ran)
+		}
+		if stack.Len() != 0 {
+			t.Errorf("expected stack to be empty after unwinding but it has %d steps", stack.Len())
+		}
+	})
+
+	t.Run("when steps fail, panic or time out it should still run all the steps and record every outcome", func(t *testing.T) {
+		stack := cleanup.NewStack(nil)
+		lastStepRan := false
+
+		stack.Push("last step", time.Minute, func(ctx context.Context) error {
+			lastStepRan = true
+			return nil
+		})
+		stack.Push("hanging step", 10*time.Millisecond, func(ctx context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		})
+		stack.Push("panicking step", time.Minute, func(ctx context.Context) error {
+			panic("some panic in cleanup")
+		})
+		stack.Push("failing step", time.Minute, func(ctx context.Context) error {
+			return fmt.Errorf("some error in cleanup")
+		})
+
+		outcomes := map[string]error{}
+		err := stack.Unwind(func(name string, run func() error) error {
+			err := run()
+			outcomes[name] = err
+			return err
+		})
+
+		if !lastStepRan {
+			t.Errorf("expected the last step to run after the other steps failed")
+		}
+		if len(outcomes) != 4 {
+			t.Errorf("expected outcomes of 4 steps to be recorded but got %v", outcomes)
+		}
+		if outcomes["failing step"] == nil || outcomes["panicking step"] == nil || outcomes["hanging step"] == nil || outcomes["last step"] != nil {
+			t.Errorf("expected the failing, panicking and hanging steps to fail but got %v", outcomes)
+		}
+		if err == nil || !strings.Contains(err.Error(), "some panic in cleanup") || !strings.Contains(err.Error(), "did not finish within") {
+			t.Errorf("expected error with the errors of all the failed steps but got: %v", err)
+		}
+	})
+
+	t.Run("when resources are tracked it should track their creation and their deletion by a successful or released step", func(t *testing.T) {
+		stack := cleanup.NewStack(nil)
+		tracker := &fakeTracker{}
+		stack.SetTracker(tracker)
+
+		stack.PushResource("cleanup cluster test-mgmt", cleanup.Resource{Kind: "cluster", Name: "test-mgmt"}, time.Minute, func(ctx context.Context) error {
+			return fmt.Errorf("some error in cleanup")
+		})
+		stack.PushResource("cleanup cluster test-wkld", cleanup.Resource{Kind: "cluster", Name: "test-wkld"}, time.Minute, func(ctx context.Context) error {
+			return nil
+		}).Release()
+		stack.PushResource("delete kube context test-wkld-admin@test-wkld", cleanup.Resource{Kind: "kube-context", Name: "test-wkld-admin@test-wkld"}, time.Minute, func(ctx context.Context) error {
+			return nil
+		})
+
+		_ = stack.Unwind(nil)
+
+		expectedEvents := []string{
+			"created cluster test-mgmt",
+			"created cluster test-wkld",
+			"deleted cluster test-wkld",
+			"created kube-context test-wkld-admin@test-wkld",
+			"deleted kube-context test-wkld-admin@test-wkld",
+		}
+		if fmt.Sprint(tracker.events) != fmt.Sprint(expectedEvents) {
+			t.Errorf("expected events %v but got %v", expectedEvents, tracker.events)
+		}
+	})
+}
+
+type fakeTracker struct {
+	events []string
+}
+
+func (tracker *fakeTracker) Created(resource cleanup.Resource) error {
+	tracker.events = append(tracker.events, fmt.Sprintf("created %s", resource))
+	return nil
+}
+
+func (tracker *fakeTracker) Deleted(resource cleanup.Resource) error {
+	tracker.events = append(tracker.events, fmt.Sprintf("deleted %s", resource))
+	return nil
+}
@@ -1,6 +1,15 @@
 package clirunner
 
-import "io"
+import (
+	"io"
+	"time"
+
+	"github.com/karuppiah7890/tce-e2e-test/testutils/log"
+)
+
+// DefaultGracePeriod is the time given to a command to exit after it has been
+// asked to terminate, before it's forcefully killed, when Cmd.GracePeriod is not set
+const DefaultGracePeriod = 30 * time.Second
 
 type Cmd struct {
 	// Name is the Name of the command to run.
@@ -23,6 +32,10 @@ type Cmd struct {
 	// missing and not explicitly set to the empty string.
 	Env []string
 
+	// Dir specifies the working directory of the command.
+	// If Dir is empty, the command runs in the current process's working directory.
+	Dir string
+
 	// Stdout and Stderr specify the process's standard output and error.
 	//
 	// If either is nil, Run connects the corresponding file descriptor
@@ -40,4 +53,37 @@ type Cmd struct {
 	// be compared with ==, at most one goroutine at a time will call Write.
 	Stdout io.Writer
 	Stderr io.Writer
+
+	// Timeout is the maximum time the command is allowed to run. It's applied
+	// on top of any deadline of the context passed to RunContext.
+	// If Timeout is zero, the command runs until it exits or the context is done.
+	Timeout time.Duration
+
+	// GracePeriod is the time given to the command's process group to exit after
+	// it has been sent SIGTERM, when the timeout elapses or the context is done,
+	// before the whole process group is sent SIGKILL.
+	// If GracePeriod is zero, DefaultGracePeriod is used.
+	GracePeriod time.Duration
+
+	// TailLines is the number of last lines of standard output and standard error
+	// kept in the Result. The output is still written to Stdout and Stderr in full.
+	// If TailLines is zero, DefaultTailLines is used.
+	TailLines int
+
+	// StderrClassifier, when set, makes the command's standard error get logged line by line
+	// using Logger, with each line tagged with the stream name and logged at the level given by
+	// the classifier, instead of logging all of it as errors. The lines classified as errors
+	// are kept in the Result's ErrorLines. Stderr, if set, still gets all of the standard error.
+	StderrClassifier *log.StreamClassifier
+
+	// Logger is used to log the command being run and its outcome.
+	// If Logger is nil, the global logger is used.
+	Logger *log.Logger
+}
+
+func (command Cmd) gracePeriod() time.Duration {
+	if command.GracePeriod <= 0 {
+		return DefaultGracePeriod
+	}
+	return command.GracePeriod
 }
@@ -0,0 +1,44 @@
+package clirunner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// TimeoutError is returned by RunContext when a command was stopped because its
+// timeout elapsed or the deadline of the context passed to RunContext was exceeded
+type TimeoutError struct {
+	// Command is the command that timed out, as shown in the logs
+	Command string
+	// Timeout is the command's own timeout. It's zero when the deadline came from the context
+	Timeout time.Duration
+	// Killed tells if the process group had to be killed with SIGKILL as it did not
+	// exit within the grace period after SIGTERM
+	Killed bool
+}
+
+func (e *TimeoutError) Error() string {
+	stopMethod := "terminated"
+	if e.Killed {
+		stopMethod = "killed"
+	}
+
+	if e.Timeout > 0 {
+		return fmt.Sprintf("command `%s` timed out after %v and was %s", e.Command, e.Timeout, stopMethod)
+	}
+
+	return fmt.Sprintf("command `%s` exceeded its deadline and was %s", e.Command, stopMethod)
+}
+
+// Unwrap makes errors.Is(err, context.DeadlineExceeded) work for timeouts
+func (e *TimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// IsTimeout tells if the error, or any error it wraps, is a TimeoutError
+func IsTimeout(err error) bool {
+	var timeoutErr *TimeoutError
+	return errors.As(err, &timeoutErr)
+}
@@ -0,0 +1,29 @@
+//go:build windows
+// +build windows
+
+package clirunner
+
+import (
+	"os"
+	"os/exec"
+)
+
+// TODO: Use job objects to stop the whole process tree on Windows. For now only the
+// command's process is stopped and any processes it spawns are left behind
+
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// terminateProcessGroup kills the command's process as Windows does not support SIGTERM
+func terminateProcessGroup(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
+
+// killProcessGroup kills the command's process
+func killProcessGroup(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
+
+// signalOf always returns false as there are no signals on Windows
+func signalOf(state *os.ProcessState) (string, bool) {
+	return "", false
+}
@@ -0,0 +1,133 @@
+package clirunner
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/karuppiah7890/tce-e2e-test/testutils/log"
+)
+
+// DefaultTailLines is the number of last lines of standard output and standard error
+// kept in the Result, when Cmd.TailLines is not set
+const DefaultTailLines = 20
+
+// maxTailLineLength is the maximum length of a line kept in the tail. Longer lines are truncated
+const maxTailLineLength = 4096
+
+// Result holds the details of a command run
+type Result struct {
+	// ExitCode is the exit code of the command. It's -1 when the command could not be
+	// started or when it was stopped by a signal
+	ExitCode int
+	// StartTime is the time at which the command was started
+	StartTime time.Time
+	// EndTime is the time at which the command exited
+	EndTime time.Time
+	// Duration is the wall-clock time the command ran for
+	Duration time.Duration
+	// Signalled tells if the command was stopped by a signal, for example when it timed out
+	Signalled bool
+	// Signal is the name of the signal that stopped the command, if any
+	Signal string
+	// StdoutTail holds the last lines of the command's standard output
+	StdoutTail []string
+	// StderrTail holds the last lines of the command's standard error
+	StderrTail []string
+	// ErrorLines holds the last lines of the command's standard error that were classified as errors,
+	// when the command has a StderrClassifier
+	ErrorLines []string
+}
+
+// OutputTail returns the last lines of standard output and standard error of the command,
+// to show them in error messages. Registered secrets are scrubbed from the output
+func (result *Result) OutputTail() string {
+	var output strings.Builder
+	if len(result.StdoutTail) != 0 {
+		output.WriteString("standard output:\n")
+		output.WriteString(strings.Join(result.StdoutTail, "\n"))
+	}
+	if len(result.StderrTail) != 0 {
+		if output.Len() != 0 {
+			output.WriteString("\n")
+		}
+		output.WriteString("standard error:\n")
+		output.WriteString(strings.Join(result.StderrTail, "\n"))
+	}
+	return log.Redact(output.String())
+}
+
+// FailureSummary returns the lines of standard error that were classified as errors, to show them
+// in error messages. When there are no such lines, it returns the OutputTail instead.
+// Registered secrets are scrubbed from the output
+func (result *Result) FailureSummary() string {
+	if len(result.ErrorLines) == 0 {
+		return result.OutputTail()
+	}
+	return log.Redact(strings.Join(result.ErrorLines, "\n"))
+}
+
+// tailWriter implements the io.Writer interface and keeps the last maxLines lines written to it
+type tailWriter struct {
+	mutex    sync.Mutex
+	maxLines int
+	lines    []string
+	partial  []byte
+}
+
+func newTailWriter(maxLines int) *tailWriter {
+	if maxLines <= 0 {
+		maxLines = DefaultTailLines
+	}
+	return &tailWriter{maxLines: maxLines}
+}
+
+func (w *tailWriter) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	data := append(w.partial, p...)
+	for {
+		index := bytes.IndexByte(data, '\n')
+		if index < 0 {
+			break
+		}
+		w.addLine(string(data[:index]))
+		data = data[index+1:]
+	}
+
+	if len(data) > maxTailLineLength {
+		data = data[:maxTailLineLength]
+	}
+	w.partial = append([]byte(nil), data...)
+
+	return len(p), nil
+}
+
+func (w *tailWriter) addLine(line string) {
+	line = strings.TrimSuffix(line, "\r")
+	if len(line) > maxTailLineLength {
+		line = line[:maxTailLineLength]
+	}
+
+	w.lines = append(w.lines, line)
+	if len(w.lines) > w.maxLines {
+		w.lines = w.lines[len(w.lines)-w.maxLines:]
+	}
+}
+
+// Lines returns the last lines written, including any last line without a trailing newline
+func (w *tailWriter) Lines() []string {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	lines := append([]string(nil), w.lines...)
+	if len(w.partial) != 0 {
+		lines = append(lines, string(w.partial))
+		if len(lines) > w.maxLines {
+			lines = lines[len(lines)-w.maxLines:]
+		}
+	}
+	return lines
+}
@@ -1,16 +1,52 @@
 package clirunner
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"os/exec"
+	"time"
 
 	"github.com/karuppiah7890/tce-e2e-test/testutils/log"
 )
 
-func Run(command Cmd) (int, error) {
+// Run runs the command and waits for it to exit. It's the same as RunContext
+// with a background context, so only the command's own Timeout applies
+func Run(command Cmd) (*Result, error) {
+	return RunContext(context.Background(), command)
+}
+
+// RunContext runs the command and waits for it to exit. When the command's Timeout
+// elapses or the context is done before the command exits, the command's process group
+// is sent SIGTERM and then SIGKILL if it's still running after the grace period.
+// A *TimeoutError is returned when the command was stopped due to a timeout or deadline.
+// The returned Result is never nil, even when an error is returned
+func RunContext(ctx context.Context, command Cmd) (*Result, error) {
+	parentCtx := ctx
+	if command.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, command.Timeout)
+		defer cancel()
+	}
+
+	stdoutTail := newTailWriter(command.TailLines)
+	stderrTail := newTailWriter(command.TailLines)
+
+	stderr := command.Stderr
+	var stderrStream *log.StreamWriter
+	if command.StderrClassifier != nil {
+		stderrStream = command.Logger.StreamWriter(command.Name+" stderr", command.StderrClassifier)
+		stderr = teeWriter(stderr, stderrStream)
+	}
+
 	cmd := exec.Command(command.Name, command.Args...)
-	cmd.Stdout = command.Stdout
-	cmd.Stderr = command.Stderr
+	cmd.Stdout = teeWriter(command.Stdout, stdoutTail)
+	cmd.Stderr = teeWriter(stderr, stderrTail)
 	cmd.Env = command.Env
+	cmd.Dir = command.Dir
+	setProcessGroup(cmd)
+
+	result := &Result{ExitCode: -1}
 
 	// TODO: Maybe set cmd.Env explicitly to a narrow set of env vars to just inject the secrets
 	// that we want to inject and nothing else. But system level env vars maybe needed for the CLI.
@@ -21,14 +57,109 @@ func Run(command Cmd) (int, error) {
 	// Tanzu({ env: []string{"key=value", "key2=value2"}, command: "management-cluster version" })
 	// But the above is not exactly readable, hmm
 
-	log.Infof("Running the command `%v`", cmd.String())
+	if err := ctx.Err(); err != nil {
+		return result, fmt.Errorf("not running the command `%v` as the context is already done: %w", cmd.String(), err)
+	}
+
+	logger := command.Logger
+	logger.Infof("Running the command `%v`", cmd.String())
+
+	result.StartTime = time.Now()
+	err := cmd.Start()
+	if err != nil {
+		result.EndTime = time.Now()
+		logger.Infof("Error occurred while starting the command `%v`: %v", cmd.String(), err)
+		return result, err
+	}
+
+	waitResult := make(chan error, 1)
+	go func() {
+		waitResult <- cmd.Wait()
+	}()
+
+	select {
+	case err = <-waitResult:
+	case <-ctx.Done():
+		killed := stop(logger, cmd, command.gracePeriod(), waitResult)
+		err = stoppedCommandError(parentCtx, ctx, cmd, command, killed)
+	}
+
+	result.EndTime = time.Now()
+	result.Duration = result.EndTime.Sub(result.StartTime)
+	result.ExitCode = cmd.ProcessState.ExitCode()
+	result.Signal, result.Signalled = signalOf(cmd.ProcessState)
+	result.StdoutTail = stdoutTail.Lines()
+	result.StderrTail = stderrTail.Lines()
+	if stderrStream != nil {
+		stderrStream.Flush()
+		result.ErrorLines = stderrStream.ErrorLines()
+	}
 
-	err := cmd.Run()
 	if err != nil {
 		// TODO: Handle the error by returning it?
-		log.Infof("Error occurred while running the command `%v`: %v", cmd.String(), err)
-		return cmd.ProcessState.ExitCode(), err
+		logger.Infof("Error occurred while running the command `%v` (ran for %v): %v", cmd.String(), result.Duration, err)
+		return result, err
+	}
+
+	logger.Infof("The command `%v` exited successfully after %v", cmd.String(), result.Duration)
+
+	return result, nil
+}
+
+// teeWriter returns a writer that writes to both the given writer and the other writer,
+// or only to the other writer when the given writer is nil
+func teeWriter(writer io.Writer, other io.Writer) io.Writer {
+	if writer == nil {
+		return other
+	}
+	return io.MultiWriter(writer, other)
+}
+
+// stop sends SIGTERM to the command's process group and waits for the command to exit.
+// If the command does not exit within the grace period, the process group is sent SIGKILL.
+// It returns true if the process group had to be killed
+func stop(logger *log.Logger, cmd *exec.Cmd, gracePeriod time.Duration, waitResult <-chan error) bool {
+	logger.Warnf("Terminating the command `%v`, it will be killed if it does not exit within %v", cmd.String(), gracePeriod)
+
+	err := terminateProcessGroup(cmd)
+	if err != nil {
+		logger.Warnf("Error occurred while terminating the command `%v`: %v", cmd.String(), err)
+	}
+
+	gracePeriodTimer := time.NewTimer(gracePeriod)
+	defer gracePeriodTimer.Stop()
+
+	select {
+	case <-waitResult:
+		return false
+	case <-gracePeriodTimer.C:
+	}
+
+	logger.Warnf("Killing the command `%v` as it did not exit within %v", cmd.String(), gracePeriod)
+
+	err = killProcessGroup(cmd)
+	if err != nil {
+		logger.Warnf("Error occurred while killing the command `%v`: %v", cmd.String(), err)
+	}
+
+	<-waitResult
+	return true
+}
+
+func stoppedCommandError(parentCtx context.Context, ctx context.Context, cmd *exec.Cmd, command Cmd, killed bool) error {
+	if ctx.Err() != context.DeadlineExceeded {
+		return fmt.Errorf("command `%v` was stopped as it was cancelled: %w", cmd.String(), ctx.Err())
+	}
+
+	timeoutErr := &TimeoutError{
+		Command: cmd.String(),
+		Killed:  killed,
+	}
+
+	// The deadline came from the command's own timeout only when the parent context is still alive
+	if parentCtx.Err() == nil {
+		timeoutErr.Timeout = command.Timeout
 	}
 
-	return cmd.ProcessState.ExitCode(), nil
+	return timeoutErr
 }
@@ -0,0 +1,187 @@
+//go:build !windows
+// +build !windows
+
+package clirunner_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/karuppiah7890/tce-e2e-test/testutils/clirunner"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/log"
+)
+
+func TestRunContext(t *testing.T) {
+	log.InitLogger("clirunner-run-context")
+
+	t.Run("when the command exits before the timeout it should return no error", func(t *testing.T) {
+		result, err := clirunner.RunContext(context.Background(), clirunner.Cmd{
+			Name:    "sh",
+			Args:    []string{"-c", "exit 0"},
+			Timeout: 10 * time.Second,
+		})
+		if err != nil {
+			t.Fatalf("expected no error but got error: %v", err)
+		}
+		if result.ExitCode != 0 {
+			t.Fatalf("expected exit code 0 but got %d", result.ExitCode)
+		}
+	})
+
+	t.Run("when the command times out it should be terminated and return a timeout error", func(t *testing.T) {
+		start := time.Now()
+		result, err := clirunner.RunContext(context.Background(), clirunner.Cmd{
+			Name:        "sh",
+			Args:        []string{"-c", "sleep 30 & wait"},
+			Timeout:     500 * time.Millisecond,
+			GracePeriod: 5 * time.Second,
+		})
+
+		var timeoutErr *clirunner.TimeoutError
+		if !errors.As(err, &timeoutErr) {
+			t.Fatalf("expected timeout error but got: %v", err)
+		}
+		if timeoutErr.Killed {
+			t.Fatalf("expected command to exit on SIGTERM without getting killed")
+		}
+		if timeoutErr.Timeout != 500*time.Millisecond {
+			t.Fatalf("expected timeout of the error to be the command timeout but got %v", timeoutErr.Timeout)
+		}
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected timeout error to be a context deadline exceeded error")
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Fatalf("expected command to be stopped soon after the timeout but it took %v", elapsed)
+		}
+		if !result.Signalled || result.Signal != "terminated" {
+			t.Fatalf("expected command to be stopped by SIGTERM but got signalled: %v, signal: %q", result.Signalled, result.Signal)
+		}
+	})
+
+	t.Run("when the command ignores SIGTERM it should be killed after the grace period", func(t *testing.T) {
+		result, err := clirunner.RunContext(context.Background(), clirunner.Cmd{
+			Name:        "sh",
+			Args:        []string{"-c", "trap '' TERM; sleep 30"},
+			Timeout:     500 * time.Millisecond,
+			GracePeriod: 500 * time.Millisecond,
+		})
+
+		var timeoutErr *clirunner.TimeoutError
+		if !errors.As(err, &timeoutErr) {
+			t.Fatalf("expected timeout error but got: %v", err)
+		}
+		if !timeoutErr.Killed {
+			t.Fatalf("expected command to get killed after the grace period")
+		}
+		if result.Signal != "killed" {
+			t.Fatalf("expected command to be stopped by SIGKILL but got signal %q", result.Signal)
+		}
+	})
+
+	t.Run("when the context deadline is exceeded it should return a timeout error without the command timeout", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+		defer cancel()
+
+		_, err := clirunner.RunContext(ctx, clirunner.Cmd{
+			Name:    "sleep",
+			Args:    []string{"30"},
+			Timeout: time.Hour,
+		})
+
+		var timeoutErr *clirunner.TimeoutError
+		if !errors.As(err, &timeoutErr) {
+			t.Fatalf("expected timeout error but got: %v", err)
+		}
+		if timeoutErr.Timeout != 0 {
+			t.Fatalf("expected no command timeout in the error as the deadline came from the context but got %v", timeoutErr.Timeout)
+		}
+	})
+
+	t.Run("when the context is cancelled it should return a cancellation error", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(500*time.Millisecond, cancel)
+
+		_, err := clirunner.RunContext(ctx, clirunner.Cmd{
+			Name: "sleep",
+			Args: []string{"30"},
+		})
+
+		if clirunner.IsTimeout(err) {
+			t.Fatalf("expected cancellation error but got timeout error: %v", err)
+		}
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected cancellation error but got: %v", err)
+		}
+	})
+}
+
+func TestRunResult(t *testing.T) {
+	log.InitLogger("clirunner-run-result")
+
+	t.Run("it should keep only the last lines of the output and still write all of the output", func(t *testing.T) {
+		var stdout bytes.Buffer
+		result, err := clirunner.Run(clirunner.Cmd{
+			Name:      "sh",
+			Args:      []string{"-c", "for i in 1 2 3 4 5; do echo out-$i; done; echo err-1 >&2; printf err-2 >&2; exit 3"},
+			Stdout:    &stdout,
+			TailLines: 2,
+		})
+
+		if err == nil {
+			t.Fatalf("expected error as the command exits with non-zero exit code")
+		}
+		if result.ExitCode != 3 {
+			t.Fatalf("expected exit code 3 but got %d", result.ExitCode)
+		}
+		if result.Signalled {
+			t.Fatalf("expected command to not be signalled")
+		}
+		if got := strings.Join(result.StdoutTail, ","); got != "out-4,out-5" {
+			t.Fatalf("expected last two lines of standard output but got %q", got)
+		}
+		if got := strings.Join(result.StderrTail, ","); got != "err-1,err-2" {
+			t.Fatalf("expected last two lines of standard error but got %q", got)
+		}
+		if strings.Count(stdout.String(), "\n") != 5 {
+			t.Fatalf("expected all of the standard output to be written but got %q", stdout.String())
+		}
+		if result.StartTime.IsZero() || result.EndTime.Before(result.StartTime) || result.Duration != result.EndTime.Sub(result.StartTime) {
+			t.Fatalf("expected valid timing data but got start: %v, end: %v, duration: %v", result.StartTime, result.EndTime, result.Duration)
+		}
+	})
+
+	t.Run("when the command has a standard error classifier it should keep the error lines", func(t *testing.T) {
+		result, err := clirunner.Run(clirunner.Cmd{
+			Name:             "sh",
+			Args:             []string{"-c", "echo 'Creating cluster' >&2; echo 'Error: cluster creation failed' >&2; echo 'Cleaning up' >&2; exit 1"},
+			StderrClassifier: log.DefaultStreamClassifier(),
+		})
+
+		if err == nil {
+			t.Fatalf("expected error as the command exits with non-zero exit code")
+		}
+		if got := strings.Join(result.ErrorLines, ","); got != "Error: cluster creation failed" {
+			t.Fatalf("expected only the error line to be kept but got %q", got)
+		}
+		if summary := result.FailureSummary(); summary != "Error: cluster creation failed" {
+			t.Fatalf("expected failure summary to have the error line but got %q", summary)
+		}
+		if len(result.StderrTail) != 3 {
+			t.Fatalf("expected all of standard error in the tail but got %q", result.StderrTail)
+		}
+	})
+
+	t.Run("when the command does not exist it should return an error and a result", func(t *testing.T) {
+		result, err := clirunner.Run(clirunner.Cmd{Name: "command-that-does-not-exist"})
+		if err == nil {
+			t.Fatalf("expected error as the command does not exist")
+		}
+		if result == nil || result.ExitCode != -1 {
+			t.Fatalf("expected result with exit code -1 but got %+v", result)
+		}
+	})
+}
@@ -26,18 +26,18 @@ func Verify(binPath string) error {
 	// For MacOS binary, use spctl and check it
 	if runtime.GOOS == "darwin" {
 		cmd := spctlCommandForBinary(binPath)
-		exitCode, err := clirunner.Run(cmd)
+		result, err := clirunner.Run(cmd)
 		if err != nil {
-			return fmt.Errorf("error occurred while verifying %s binary signature. Exit code: %v. Error: %v", binPath, exitCode, err)
+			return fmt.Errorf("error occurred while verifying %s binary signature. Exit code: %v. Error: %v", binPath, result.ExitCode, err)
 		}
 	}
 
 	// For WindowsOS binary, use SignTool https://docs.microsoft.com/en-us/windows/win32/seccrypto/signtool
 	if runtime.GOOS == "windows" {
 		cmd := signtoolCommandForBinary(binPath)
-		exitCode, err := clirunner.Run(cmd)
+		result, err := clirunner.Run(cmd)
 		if err != nil {
-			return fmt.Errorf("error occurred while verifying %s binary signature. Exit code: %v. Error: %v", binPath, exitCode, err)
+			return fmt.Errorf("error occurred while verifying %s binary signature. Exit code: %v. Error: %v", binPath, result.ExitCode, err)
 		}
 	}
 
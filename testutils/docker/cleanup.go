@@ -0,0 +1,120 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	volumetypes "github.com/docker/docker/api/types/volume"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/cleanup"
+)
+
+// ClusterLabel is the label kind and CAPD (Cluster API Provider Docker) put on the containers of a cluster,
+// with the cluster name as the value
+const ClusterLabel = "io.x-k8s.kind.cluster"
+
+// Client is the part of the Docker Engine API client used to clean up the Docker resources of a cluster.
+// It's implemented by *client.Client
+type Client interface {
+	ContainerList(ctx context.Context, options types.ContainerListOptions) ([]types.Container, error)
+	ContainerRemove(ctx context.Context, containerID string, options types.ContainerRemoveOptions) error
+	NetworkList(ctx context.Context, options types.NetworkListOptions) ([]types.NetworkResource, error)
+	NetworkRemove(ctx context.Context, networkID string) error
+	VolumeList(ctx context.Context, filter filters.Args) (volumetypes.VolumeListOKBody, error)
+	VolumeRemove(ctx context.Context, volumeID string, force bool) error
+}
+
+// RemovedResources has the names of the Docker resources removed for a cluster
+type RemovedResources struct {
+	Containers []string
+	Networks   []string
+	Volumes    []string
+}
+
+// Count returns the number of removed resources
+func (removed RemovedResources) Count() int {
+	return len(removed.Containers) + len(removed.Networks) + len(removed.Volumes)
+}
+
+func (removed RemovedResources) String() string {
+	return fmt.Sprintf("containers: [%s], networks: [%s], volumes: [%s]",
+		strings.Join(removed.Containers, ", "), strings.Join(removed.Networks, ", "), strings.Join(removed.Volumes, ", "))
+}
+
+// RemoveClusterResources force removes the containers, networks and volumes of the cluster - the ones with the
+// cluster label set to the cluster name, or named after the cluster, like test-mgmt-1652000000-control-plane-x7rtz
+// for the test-mgmt-1652000000 cluster. Containers are removed first, along with their anonymous volumes, so
+// that the networks and volumes are not in use anymore. It keeps going when a resource can't be removed and
+// returns the resources it removed along with an error for the ones it could not remove. An empty cluster name is
+// an error, as every resource without the cluster label would match it
+func RemoveClusterResources(ctx context.Context, cli Client, clusterName string) (RemovedResources, error) {
+	removed := RemovedResources{}
+	if clusterName == "" {
+		return removed, fmt.Errorf("cluster name is required to remove the docker resources of a cluster")
+	}
+	errs := []string{}
+
+	containers, err := cli.ContainerList(ctx, types.ContainerListOptions{All: true})
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("error while listing containers: %v", err))
+	}
+	for _, container := range containers {
+		name := containerName(container)
+		if container.Labels[ClusterLabel] != clusterName && !cleanup.IsNamedForCluster(name, clusterName) {
+			continue
+		}
+		err := cli.ContainerRemove(ctx, container.ID, types.ContainerRemoveOptions{Force: true, RemoveVolumes: true})
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("error while removing container %s: %v", name, err))
+			continue
+		}
+		removed.Containers = append(removed.Containers, name)
+	}
+
+	networks, err := cli.NetworkList(ctx, types.NetworkListOptions{})
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("error while listing networks: %v", err))
+	}
+	for _, network := range networks {
+		if network.Labels[ClusterLabel] != clusterName && !cleanup.IsNamedForCluster(network.Name, clusterName) {
+			continue
+		}
+		err := cli.NetworkRemove(ctx, network.ID)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("error while removing network %s: %v", network.Name, err))
+			continue
+		}
+		removed.Networks = append(removed.Networks, network.Name)
+	}
+
+	volumes, err := cli.VolumeList(ctx, filters.NewArgs())
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("error while listing volumes: %v", err))
+	}
+	for _, volume := range volumes.Volumes {
+		if volume.Labels[ClusterLabel] != clusterName && !cleanup.IsNamedForCluster(volume.Name, clusterName) {
+			continue
+		}
+		err := cli.VolumeRemove(ctx, volume.Name, true)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("error while removing volume %s: %v", volume.Name, err))
+			continue
+		}
+		removed.Volumes = append(removed.Volumes, volume.Name)
+	}
+
+	if len(errs) != 0 {
+		return removed, fmt.Errorf("errors while removing docker resources of cluster %s: %s", clusterName, strings.Join(errs, "; "))
+	}
+	return removed, nil
+}
+
+// containerName returns the name of the container without the leading slash the Docker Engine API gives
+func containerName(container types.Container) string {
+	if len(container.Names) == 0 {
+		return container.ID
+	}
+	return strings.TrimPrefix(container.Names[0], "/")
+}
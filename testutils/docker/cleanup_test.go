@@ -0,0 +1,146 @@
+package docker_test
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	volumetypes "github.com/docker/docker/api/types/volume"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/docker"
+)
+
+type fakeClient struct {
+	containers []types.Container
+	networks   []types.NetworkResource
+	volumes    []*types.Volume
+	failRemove map[string]bool
+	removed    []string
+}
+
+func (client *fakeClient) ContainerList(ctx context.Context, options types.ContainerListOptions) ([]types.Container, error) {
+	return client.containers, nil
+}
+
+func (client *fakeClient) ContainerRemove(ctx context.Context, containerID string, options types.ContainerRemoveOptions) error {
+	if !options.Force || !options.RemoveVolumes {
+		return fmt.Errorf("expected container to be force removed along with its volumes")
+	}
+	return client.remove("container", containerID)
+}
+
+func (client *fakeClient) NetworkList(ctx context.Context, options types.NetworkListOptions) ([]types.NetworkResource, error) {
+	return client.networks, nil
+}
+
+func (client *fakeClient) NetworkRemove(ctx context.Context, networkID string) error {
+	return client.remove("network", networkID)
+}
+
+func (client *fakeClient) VolumeList(ctx context.Context, filter filters.Args) (volumetypes.VolumeListOKBody, error) {
+	return volumetypes.VolumeListOKBody{Volumes: client.volumes}, nil
+}
+
+func (client *fakeClient) VolumeRemove(ctx context.Context, volumeID string, force bool) error {
+	return client.remove("volume", volumeID)
+}
+
+func (client *fakeClient) remove(kind, id string) error {
+	if client.failRemove[id] {
+		return fmt.Errorf("%s %s is in use", kind, id)
+	}
+	client.removed = append(client.removed, fmt.Sprintf("%s/%s", kind, id))
+	return nil
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{
+		containers: []types.Container{
+			{ID: "c1", Names: []string{"/test-mgmt-1652000000-control-plane-x7rtz"}},
+			{ID: "c2", Names: []string{"/test-mgmt-1652000000-lb"}},
+			{ID: "c3", Names: []string{"/renamed-node"}, Labels: map[string]string{docker.ClusterLabel: "test-mgmt-1652000000"}},
+			{ID: "c4", Names: []string{"/test-mgmt-16520000001-control-plane-abcde"}},
+			{ID: "c5", Names: []string{"/test-wkld-1652000000-md-0-abcde"}},
+		},
+		networks: []types.NetworkResource{
+			{ID: "n1", Name: "kind"},
+			{ID: "n2", Name: "test-mgmt-1652000000"},
+		},
+		volumes: []*types.Volume{
+			{Name: "anonymous-volume"},
+			{Name: "test-mgmt-1652000000-control-plane-x7rtz-var"},
+			{Name: "labelled-volume", Labels: map[string]string{docker.ClusterLabel: "test-mgmt-1652000000"}},
+		},
+	}
+}
+
+func TestRemoveClusterResources(t *testing.T) {
+	t.Run("when resources are labelled or named for the cluster it should remove only them", func(t *testing.T) {
+		client := newFakeClient()
+
+		removed, err := docker.RemoveClusterResources(context.Background(), client, "test-mgmt-1652000000")
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+
+		expectedRemoved := docker.RemovedResources{
+			Containers: []string{"test-mgmt-1652000000-control-plane-x7rtz", "test-mgmt-1652000000-lb", "renamed-node"},
+			Networks:   []string{"test-mgmt-1652000000"},
+			Volumes:    []string{"test-mgmt-1652000000-control-plane-x7rtz-var", "labelled-volume"},
+		}
+		if !reflect.DeepEqual(removed, expectedRemoved) {
+			t.Errorf("expected removed resources to be %v but got %v", expectedRemoved, removed)
+		}
+
+		expectedCalls := []string{
+			"container/c1", "container/c2", "container/c3",
+			"network/n2",
+			"volume/test-mgmt-1652000000-control-plane-x7rtz-var", "volume/labelled-volume",
+		}
+		if !reflect.DeepEqual(client.removed, expectedCalls) {
+			t.Errorf("expected remove calls to be %v but got %v", expectedCalls, client.removed)
+		}
+	})
+
+	t.Run("when a resource can't be removed it should remove the others and return an error", func(t *testing.T) {
+		client := newFakeClient()
+		client.failRemove = map[string]bool{"n2": true}
+
+		removed, err := docker.RemoveClusterResources(context.Background(), client, "test-mgmt-1652000000")
+		if err == nil {
+			t.Fatalf("expected an error but got none")
+		}
+		if !strings.Contains(err.Error(), "error while removing network test-mgmt-1652000000") {
+			t.Errorf("expected error to mention the network but got: %v", err)
+		}
+		if removed.Count() != 5 {
+			t.Errorf("expected 5 removed resources but got %d: %v", removed.Count(), removed)
+		}
+	})
+
+	t.Run("when there are no resources for the cluster it should remove nothing", func(t *testing.T) {
+		client := newFakeClient()
+
+		removed, err := docker.RemoveClusterResources(context.Background(), client, "test-wkld-1")
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		if removed.Count() != 0 || len(client.removed) != 0 {
+			t.Errorf("expected nothing to be removed but got %v", client.removed)
+		}
+	})
+	t.Run("when the cluster name is empty it should return an error without removing anything", func(t *testing.T) {
+		client := newFakeClient()
+
+		removed, err := docker.RemoveClusterResources(context.Background(), client, "")
+		if err == nil {
+			t.Fatalf("expected an error but got none")
+		}
+		if removed.Count() != 0 || len(client.removed) != 0 {
+			t.Errorf("expected nothing to be removed but got %v", client.removed)
+		}
+	})
+}
@@ -15,44 +15,44 @@ import (
 // client.NewClientWithOpts()
 var ctx context.Context = context.Background()
 
-func GetDockerClient() *client.Client {
+func GetDockerClient() (*client.Client, error) {
 	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
 	if err != nil {
-		// TODO: Handle errors by returning them? Should we log them here too or let caller decide about the logging?
-		log.Fatalf("error creating docker client: %v", err)
+		return nil, fmt.Errorf("error creating docker client: %v", err)
 	}
-	return cli
+	return cli, nil
 }
 
-func CheckDockerInstallation() {
+func CheckDockerInstallation() error {
 
 	log.Info("Checking docker CLI and Docker Engine installation")
-	cli := GetDockerClient()
 	path, err := exec.LookPath("docker")
 	if err != nil {
-		// TODO: Handle errors by returning them? Should we log them here too or let caller decide about the logging?
-		log.Fatalf("docker CLI is not installed")
+		return fmt.Errorf("docker CLI is not installed: %v", err)
+	}
+	cli, err := GetDockerClient()
+	if err != nil {
+		return err
 	}
 	serverVersionInfo, err := cli.ServerVersion(context.TODO())
 	if err != nil {
-		// TODO: Should the below be info? And should we always show it or only when there's an error in
-		// checking Docker Engine version?
-		log.Warn("Ensure Docker Engine is installed and accessible")
-		// TODO: Handle errors by returning them? Should we log them here too or let caller decide about the logging?
-		log.Fatalf("Error checking Docker Engine version: %v .", err)
+		return fmt.Errorf("error checking Docker Engine version, ensure Docker Engine is installed and accessible: %v", err)
 	}
 	log.Infof("docker CLI is available at path: %s", path)
 	log.Infof("E2E test Docker client's API version: %s", cli.ClientVersion())
 	log.Infof("Docker Engine's API version: %s", serverVersionInfo.APIVersion)
 	log.Infof("Docker Engine's version: %s", serverVersionInfo.Version)
+	return nil
 }
 
 // containerName - name of the container / container ID (full ID or unique partial ID)
 // ForceRemoveRunningContainer force stop and remove a running container
 func ForceRemoveRunningContainer(containerName string) error {
-	cli := GetDockerClient()
+	cli, err := GetDockerClient()
+	if err != nil {
+		return err
+	}
 
-	// TODO: Handle errors by returning them? Should we log them here too or let caller decide about the logging?
 	if err := cli.ContainerRemove(ctx, containerName, types.ContainerRemoveOptions{Force: true}); err != nil {
 		log.Infof("Failed to find container with  name: %s", containerName)
 		return fmt.Errorf("failed to find container with  name: %s", containerName)
@@ -61,11 +61,14 @@ func ForceRemoveRunningContainer(containerName string) error {
 	return nil
 }
 
-func ForceRemoveAllRunningContainers() {
-	cli := GetDockerClient()
+func ForceRemoveAllRunningContainers() error {
+	cli, err := GetDockerClient()
+	if err != nil {
+		return err
+	}
 	containers, err := cli.ContainerList(ctx, types.ContainerListOptions{All: true})
 	if err != nil {
-		log.Infof("Failed to List Containers: %s", err)
+		return fmt.Errorf("failed to list containers: %v", err)
 	}
 	log.Infof("Containers %s", containers)
 	for _, container := range containers {
@@ -75,4 +78,5 @@ func ForceRemoveAllRunningContainers() {
 		}
 		log.Infof("Container removed: %s", container.Names)
 	}
+	return nil
 }
@@ -3,22 +3,33 @@ package dockerprovider
 import (
 	"context"
 
+	"github.com/karuppiah7890/tce-e2e-test/testutils/cleanup"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/docker"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/log"
 	"github.com/karuppiah7890/tce-e2e-test/testutils/tanzu"
 	"github.com/karuppiah7890/tce-e2e-test/testutils/utils"
 )
 
 // TODO: Change name?
-type Provider struct{}
+type Provider struct {
+	logger *log.Logger
+}
 
 func (provider *Provider) RequiredEnvVars() []string {
 	return []string{}
 }
 
+// SecretEnvVars returns no environment variables, as the Docker provider needs no credentials
+func (provider *Provider) SecretEnvVars() []string {
+	return []string{}
+}
+
 func (provider *Provider) Name() string {
 	return "docker"
 }
 
-func (provider *Provider) Init() error {
+func (provider *Provider) Init(logger *log.Logger, cleanups *cleanup.Stack, tanzuConfig utils.TanzuConfigFunc) error {
+	provider.logger = logger
 	return nil
 }
 
@@ -26,12 +37,26 @@ func (provider *Provider) PreClusterCreationTasks(clusterName string, clusterTyp
 	return nil
 }
 
+// CleanupCluster force removes the containers, networks and volumes left behind by the cluster, like the CAPD
+// containers of the cluster nodes and load balancer, and logs what it removed
 func (provider *Provider) CleanupCluster(ctx context.Context, clusterName string) error {
-	// TODO: Implement using docker library
-	return nil
+	cli, err := docker.GetDockerClient()
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+
+	removed, err := docker.RemoveClusterResources(ctx, cli, clusterName)
+	if removed.Count() == 0 {
+		provider.logger.Infof("No docker resources found for cluster %s", clusterName)
+	} else {
+		provider.logger.Infof("Removed docker resources of cluster %s: %s", clusterName, removed)
+	}
+	return err
 }
 
-//TODO: Maybe make use of https://github.com/spf13/viper to set env vars and make some values as default and parameterised.
+// GetTanzuConfig returns the default tanzu config of the clusters. A scenario and environment variables can
+// override any of the values, see utils.ClusterTanzuConfig
 func (provider *Provider) GetTanzuConfig(clusterName string) tanzu.TanzuConfig {
 	return tanzu.TanzuConfig{
 		"CLUSTER_NAME":              clusterName,
@@ -50,3 +75,9 @@ func (provider *Provider) GetTanzuConfig(clusterName string) tanzu.TanzuConfig {
 
 // TODO: Change name?
 var PROVIDER utils.Provider = &Provider{}
+
+func init() {
+	utils.RegisterProvider("Docker on the local machine, using kind for the clusters", func() utils.Provider {
+		return &Provider{}
+	})
+}
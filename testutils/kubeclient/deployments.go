@@ -0,0 +1,60 @@
+package kubeclient
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CreateDeployment creates the namespace, if it doesn't exist, and a Deployment in it with the replicas of a pod
+// with a single container running the image
+func (kubeclient *KubeClient) CreateDeployment(ctx context.Context, namespace string, name string, image string, replicas int32) error {
+	_, err := kubeclient.CoreV1().Namespaces().Create(ctx, &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}, metav1.CreateOptions{})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("error creating namespace %s: %v", namespace, err)
+	}
+
+	labels := map[string]string{"app": name}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: name, Image: image}},
+				},
+			},
+		},
+	}
+	_, err = kubeclient.AppsV1().Deployments(namespace).Create(ctx, deployment, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("error creating deployment %s in namespace %s: %v", name, namespace, err)
+	}
+	return nil
+}
+
+// CheckDeploymentAvailable checks that the Deployment exists and all its replicas are available and up to date
+func (kubeclient *KubeClient) CheckDeploymentAvailable(ctx context.Context, namespace string, name string) error {
+	deployment, err := kubeclient.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("error getting deployment %s in namespace %s: %v", name, namespace, err)
+	}
+
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	if deployment.Status.ObservedGeneration < deployment.Generation {
+		return fmt.Errorf("deployment %s in namespace %s is not observed yet", name, namespace)
+	}
+	if deployment.Status.AvailableReplicas != replicas || deployment.Status.UpdatedReplicas != replicas {
+		return fmt.Errorf("deployment %s in namespace %s has %d of %d replicas available and %d up to date", name, namespace, deployment.Status.AvailableReplicas, replicas, deployment.Status.UpdatedReplicas)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package kubeclient_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/karuppiah7890/tce-e2e-test/testutils/kubeclient"
+)
+
+func TestCheckDeploymentAvailable(t *testing.T) {
+	t.Run("when the deployment is created it should be in its namespace and not available till its replicas are", func(t *testing.T) {
+		client := &kubeclient.KubeClient{Interface: fake.NewSimpleClientset()}
+		ctx := context.Background()
+
+		err := client.CreateDeployment(ctx, "upgrade-test", "pause", "k8s.gcr.io/pause:3.6", 2)
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+
+		err = client.CheckDeploymentAvailable(ctx, "upgrade-test", "pause")
+		expectedError := "deployment pause in namespace upgrade-test has 0 of 2 replicas available and 0 up to date"
+		if err == nil || !strings.Contains(err.Error(), expectedError) {
+			t.Errorf("expected error to contain: %v. But got: %v", expectedError, err)
+		}
+
+		deployment, err := client.AppsV1().Deployments("upgrade-test").Get(ctx, "pause", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		deployment.Status.AvailableReplicas = 2
+		deployment.Status.UpdatedReplicas = 2
+		_, err = client.AppsV1().Deployments("upgrade-test").UpdateStatus(ctx, deployment, metav1.UpdateOptions{})
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+
+		err = client.CheckDeploymentAvailable(ctx, "upgrade-test", "pause")
+		if err != nil {
+			t.Errorf("expected no error but got: %v", err)
+		}
+	})
+
+	t.Run("when the deployment doesn't exist it should return an error", func(t *testing.T) {
+		client := &kubeclient.KubeClient{Interface: fake.NewSimpleClientset()}
+
+		err := client.CheckDeploymentAvailable(context.Background(), "upgrade-test", "pause")
+		expectedError := "error getting deployment pause in namespace upgrade-test"
+		if err == nil || !strings.Contains(err.Error(), expectedError) {
+			t.Errorf("expected error to contain: %v. But got: %v", expectedError, err)
+		}
+	})
+}
@@ -1,12 +1,15 @@
 package kubeclient
 
 import (
+	"errors"
 	"fmt"
 
+	"github.com/karuppiah7890/tce-e2e-test/testutils/kubescheme"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // With the below we can have sugar coat methods on KubeClient and also to the full power of
@@ -40,6 +43,9 @@ func configForContext(kubeConfigPath string, context string) (*rest.Config, erro
 	return config, nil
 }
 
+// ErrContextNotFound is returned when deleting a context which is not in the kubeconfig file
+var ErrContextNotFound = errors.New("context not found")
+
 // TODO: Should we rename this to CleanupContext?
 // new package / file for kubeconfig stuff?
 // DeleteContext deletes the context from the kubeconfig file and also deletes the corresponding
@@ -54,7 +60,7 @@ func DeleteContext(kubeConfigPath string, contextName string) error {
 	context, ok := rawConfig.Contexts[contextName]
 
 	if !ok {
-		return fmt.Errorf("could not find context named %s in kubeconfig file at path %s", contextName, kubeConfigPath)
+		return fmt.Errorf("could not find context named %s in kubeconfig file at path %s: %w", contextName, kubeConfigPath, ErrContextNotFound)
 	}
 
 	// TODO: Should we consider setting it to any other valid context from the list of contexts?
@@ -109,3 +115,19 @@ func getConfig(kubeConfigPath string, context string) clientcmd.ClientConfig {
 
 	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides)
 }
+
+// GetControllerRuntimeClient creates a controller-runtime client for a given kubeconfig path and kubeconfig context
+// in the kubeconfig, which knows the kinds of kubescheme, like the CAPI kinds. When context is empty, the current
+// context mentioned in the kubeconfig is used
+func GetControllerRuntimeClient(kubeConfigPath string, context string) (client.Client, error) {
+	config, err := configForContext(kubeConfigPath, context)
+	if err != nil {
+		return nil, err
+	}
+	runtimeClient, err := client.New(config, client.Options{Scheme: kubescheme.GetScheme()})
+	if err != nil {
+		return nil, fmt.Errorf("could not get controller-runtime client: %v", err)
+	}
+
+	return runtimeClient, nil
+}
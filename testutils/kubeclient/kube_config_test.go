@@ -1,6 +1,7 @@
 package kubeclient_test
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -33,6 +34,10 @@ func TestConfigDeletion(t *testing.T) {
 		if !strings.Contains(err.Error(), fmt.Sprintf("could not find context named %s in kubeconfig file at path", nonExistentContext)) {
 			log.Fatalf("expected error around finding non-existent context %s but got some other error: %v", nonExistentContext, err)
 		}
+
+		if !errors.Is(err, kubeclient.ErrContextNotFound) {
+			log.Fatalf("expected error for non-existent context %s to be ErrContextNotFound but got: %v", nonExistentContext, err)
+		}
 	})
 
 	t.Run("when deleting a context that exists it should delete the context without any errors", func(t *testing.T) {
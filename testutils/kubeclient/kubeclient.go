@@ -33,25 +33,23 @@ func (kubeclient *KubeClient) GetAllNodes() (*v1.NodeList, error) {
 	return nodes, nil
 }
 
-func UseKubeConfigContext(workloadClusterKubeContext string) error {
-	exitCode, err := clirunner.Run(clirunner.Cmd{
+// UseKubeConfigContext makes the kube context the current context of the kubeconfig of the environment. If env is
+// nil, the environment of the process is used
+func UseKubeConfigContext(logger *log.Logger, env []string, workloadClusterKubeContext string) error {
+	result, err := clirunner.Run(clirunner.Cmd{
 		Name: "kubectl",
 		Args: []string{
 			"config",
 			"use-context",
 			workloadClusterKubeContext,
 		},
-		Stdout: log.InfoWriter,
-		// TODO: Should we log standard errors as errors in the log? Because tanzu prints other information also
-		// to standard error, which are kind of like information, apart from actual errors, so showing
-		// everything as error is misleading. Gotta think what to do about this. The main problem is
-		// console has only standard output and standard error, and tanzu is using standard output only for
-		// giving output for things like --dry-run when it needs to print yaml content, but everything else
-		// is printed to standard error
-		Stderr: log.ErrorWriter,
+		Env:              env,
+		Stdout:           logger.InfoWriter(),
+		StderrClassifier: log.DefaultStreamClassifier(),
+		Logger:           logger,
 	})
 	if err != nil {
-		return fmt.Errorf("error occurred while setting cluster kube config context. exit code: %v. error: %v", exitCode, err)
+		return fmt.Errorf("error occurred while setting cluster kube config context. exit code: %v. error: %v", result.ExitCode, err)
 	}
 	return nil
 }
@@ -0,0 +1,72 @@
+package kubeclient
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Labels of the control plane nodes. Kubernetes versions before v1.20 only have the master label
+const (
+	controlPlaneNodeLabel = "node-role.kubernetes.io/control-plane"
+	masterNodeLabel       = "node-role.kubernetes.io/master"
+)
+
+// CheckNodes checks that the cluster has the worker count of worker nodes, that all its nodes are Ready and, when
+// the kubelet version is not empty, that all its nodes run the kubelet version, like v1.22.8+vmware.1. It returns
+// an error with everything that doesn't match
+func (kubeclient *KubeClient) CheckNodes(ctx context.Context, workerCount int, kubeletVersion string) error {
+	nodes, err := kubeclient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("error getting all nodes: %v", err)
+	}
+
+	problems := []string{}
+	workers := 0
+	for _, node := range nodes.Items {
+		if !isControlPlaneNode(node) {
+			workers++
+		}
+		if !isNodeReady(node) {
+			problems = append(problems, fmt.Sprintf("node %s is not Ready", node.Name))
+		}
+		if kubeletVersion != "" && node.Status.NodeInfo.KubeletVersion != kubeletVersion {
+			problems = append(problems, fmt.Sprintf("node %s runs kubelet %s, expected %s", node.Name, node.Status.NodeInfo.KubeletVersion, kubeletVersion))
+		}
+	}
+	if workers != workerCount {
+		problems = append(problems, fmt.Sprintf("cluster has %d worker nodes, expected %d", workers, workerCount))
+	}
+
+	if len(problems) != 0 {
+		return fmt.Errorf("nodes are not as expected: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// DeleteNode deletes the node from the cluster
+func (kubeclient *KubeClient) DeleteNode(ctx context.Context, nodeName string) error {
+	err := kubeclient.CoreV1().Nodes().Delete(ctx, nodeName, metav1.DeleteOptions{})
+	if err != nil {
+		return fmt.Errorf("error deleting node %s: %v", nodeName, err)
+	}
+	return nil
+}
+
+func isControlPlaneNode(node v1.Node) bool {
+	_, controlPlane := node.Labels[controlPlaneNodeLabel]
+	_, master := node.Labels[masterNodeLabel]
+	return controlPlane || master
+}
+
+func isNodeReady(node v1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == v1.NodeReady {
+			return condition.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package kubeclient_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/karuppiah7890/tce-e2e-test/testutils/kubeclient"
+)
+
+func node(name string, labels map[string]string, ready v1.ConditionStatus, kubeletVersion string) *v1.Node {
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		Status: v1.NodeStatus{
+			Conditions: []v1.NodeCondition{{Type: v1.NodeReady, Status: ready}},
+			NodeInfo:   v1.NodeSystemInfo{KubeletVersion: kubeletVersion},
+		},
+	}
+}
+
+func TestCheckNodes(t *testing.T) {
+	controlPlaneLabels := map[string]string{"node-role.kubernetes.io/control-plane": ""}
+
+	t.Run("when the nodes are ready and run the kubelet version it should return no error", func(t *testing.T) {
+		client := &kubeclient.KubeClient{Interface: fake.NewSimpleClientset(
+			node("test-wkld-control-plane-abcde", controlPlaneLabels, v1.ConditionTrue, "v1.22.8+vmware.1"),
+			node("test-wkld-md-0-fghij", nil, v1.ConditionTrue, "v1.22.8+vmware.1"),
+		)}
+
+		err := client.CheckNodes(context.Background(), 1, "v1.22.8+vmware.1")
+		if err != nil {
+			t.Errorf("expected no error but got: %v", err)
+		}
+	})
+
+	t.Run("when a worker is missing, not ready and not upgraded it should return an error with the problems", func(t *testing.T) {
+		client := &kubeclient.KubeClient{Interface: fake.NewSimpleClientset(
+			node("test-wkld-control-plane-abcde", controlPlaneLabels, v1.ConditionTrue, "v1.22.8+vmware.1"),
+			node("test-wkld-md-0-fghij", nil, v1.ConditionFalse, "v1.21.11+vmware.1"),
+		)}
+
+		err := client.CheckNodes(context.Background(), 2, "v1.22.8+vmware.1")
+		if err == nil {
+			t.Fatalf("expected an error but got none")
+		}
+		for _, problem := range []string{
+			"node test-wkld-md-0-fghij is not Ready",
+			"node test-wkld-md-0-fghij runs kubelet v1.21.11+vmware.1, expected v1.22.8+vmware.1",
+			"cluster has 1 worker nodes, expected 2",
+		} {
+			if !strings.Contains(err.Error(), problem) {
+				t.Errorf("expected error to contain %q but got: %v", problem, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,56 @@
+package kubeclient
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// PackageInstallListGVK is the kind of the list of kapp-controller PackageInstalls, which is read as unstructured
+// objects, as the kapp-controller types are not in kubescheme
+var PackageInstallListGVK = schema.GroupVersionKind{Group: "packaging.carvel.dev", Version: "v1alpha1", Kind: "PackageInstallList"}
+
+// reconcileSucceededCondition is the condition of a PackageInstall which kapp-controller reconciled successfully
+const reconcileSucceededCondition = "ReconcileSucceeded"
+
+// CheckPackageInstallsReconciled checks that all the PackageInstalls of the cluster, in all the namespaces, are
+// reconciled successfully by kapp-controller. It returns an error with all the PackageInstalls which are not
+func CheckPackageInstallsReconciled(ctx context.Context, c client.Reader) error {
+	packageInstalls := &unstructured.UnstructuredList{}
+	packageInstalls.SetGroupVersionKind(PackageInstallListGVK)
+	err := c.List(ctx, packageInstalls)
+	if err != nil {
+		return fmt.Errorf("error listing package installs: %v", err)
+	}
+
+	problems := []string{}
+	for _, packageInstall := range packageInstalls.Items {
+		if !isReconcileSucceeded(packageInstall) {
+			description, _, _ := unstructured.NestedString(packageInstall.Object, "status", "friendlyDescription")
+			problems = append(problems, fmt.Sprintf("package install %s/%s is not reconciled: %s", packageInstall.GetNamespace(), packageInstall.GetName(), description))
+		}
+	}
+
+	if len(problems) != 0 {
+		return fmt.Errorf("%d of %d package installs are not reconciled: %s", len(problems), len(packageInstalls.Items), strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func isReconcileSucceeded(packageInstall unstructured.Unstructured) bool {
+	conditions, _, _ := unstructured.NestedSlice(packageInstall.Object, "status", "conditions")
+	for _, condition := range conditions {
+		conditionFields, ok := condition.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if conditionFields["type"] == reconcileSucceededCondition && conditionFields["status"] == "True" {
+			return true
+		}
+	}
+	return false
+}
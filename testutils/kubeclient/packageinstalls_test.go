@@ -0,0 +1,62 @@
+package kubeclient_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/karuppiah7890/tce-e2e-test/testutils/kubeclient"
+)
+
+func packageInstall(namespace, name, reconcileStatus, description string) *unstructured.Unstructured {
+	packageInstall := &unstructured.Unstructured{Object: map[string]interface{}{
+		"status": map[string]interface{}{
+			"friendlyDescription": description,
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "ReconcileSucceeded", "status": reconcileStatus},
+			},
+		},
+	}}
+	packageInstall.SetGroupVersionKind(kubeclient.PackageInstallListGVK.GroupVersion().WithKind("PackageInstall"))
+	packageInstall.SetNamespace(namespace)
+	packageInstall.SetName(name)
+	return packageInstall
+}
+
+func packageInstallScheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	scheme.AddKnownTypeWithName(kubeclient.PackageInstallListGVK.GroupVersion().WithKind("PackageInstall"), &unstructured.Unstructured{})
+	scheme.AddKnownTypeWithName(kubeclient.PackageInstallListGVK, &unstructured.UnstructuredList{})
+	return scheme
+}
+
+func TestCheckPackageInstallsReconciled(t *testing.T) {
+	t.Run("when all package installs are reconciled it should return no error", func(t *testing.T) {
+		c := fake.NewClientBuilder().WithScheme(packageInstallScheme()).WithObjects(
+			packageInstall("tkg-system", "antrea", "True", "Reconcile succeeded"),
+			packageInstall("default", "cert-manager", "True", "Reconcile succeeded"),
+		).Build()
+
+		err := kubeclient.CheckPackageInstallsReconciled(context.Background(), c)
+		if err != nil {
+			t.Errorf("expected no error but got: %v", err)
+		}
+	})
+
+	t.Run("when a package install failed to reconcile it should return an error with its description", func(t *testing.T) {
+		c := fake.NewClientBuilder().WithScheme(packageInstallScheme()).WithObjects(
+			packageInstall("tkg-system", "antrea", "True", "Reconcile succeeded"),
+			packageInstall("default", "cert-manager", "False", "Reconcile failed: Error (see .status.usefulErrorMessage for details)"),
+		).Build()
+
+		err := kubeclient.CheckPackageInstallsReconciled(context.Background(), c)
+		expectedError := "1 of 2 package installs are not reconciled: package install default/cert-manager is not reconciled: Reconcile failed"
+		if err == nil || !strings.Contains(err.Error(), expectedError) {
+			t.Errorf("expected error to contain: %v. But got: %v", expectedError, err)
+		}
+	})
+}
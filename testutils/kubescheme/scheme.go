@@ -12,6 +12,9 @@ import (
 	infrav1alpha4exp "sigs.k8s.io/cluster-api-provider-azure/exp/api/v1alpha4"
 	infrav1beta1exp "sigs.k8s.io/cluster-api-provider-azure/exp/api/v1beta1"
 
+	capav1beta1 "sigs.k8s.io/cluster-api-provider-aws/api/v1beta1"
+	capvv1beta1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	clusterv1alpha3 "sigs.k8s.io/cluster-api/api/v1alpha3"
@@ -115,6 +118,16 @@ func registerCustomResources() {
 		panic(err)
 	}
 
+	err = capav1beta1.AddToScheme(scheme)
+	if err != nil {
+		panic(err)
+	}
+
+	err = capvv1beta1.AddToScheme(scheme)
+	if err != nil {
+		panic(err)
+	}
+
 	err = capiControlplaneKubeadmv1beta.AddToScheme(scheme)
 	if err != nil {
 		panic(err)
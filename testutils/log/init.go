@@ -12,60 +12,82 @@ import (
 	"go.uber.org/zap/zapcore"
 )
 
-// TODO: Check what happens when multiple tests run in parallel and call this.
-// Does it support multiple parallel tests logging to different files? Or is there any problems
-// due to the usage of global loggers and zap.ReplaceGlobals 😅 in which case we have to resort to
-// using local loggers along with passing around loggers for usage in functions / methods etc
+// InitLogger initializes the global logger used by the package level functions like Infof and Errorf.
+// The global logger is shared by everything in the process, so tests that run in parallel should use
+// their own Logger created with NewLogger or NewTestLogger instead, so that each test logs to its own file
 func InitLogger(loggingProgram string) {
-	logDir := createDirectoryIfNotExists()
-	writerSync := getLogWriter(logDir, loggingProgram)
+	globalLogger, logFile, err := newZapLogger(loggingProgram, globalRecorder)
+	if err != nil {
+		log.Fatalf("Error while initializing logger: %v", err)
+	}
+	zap.ReplaceGlobals(globalLogger)
+	globalLogFilePath = logFile.Name()
+}
+
+// globalLogFilePath is the path of the log file of the global logger
+var globalLogFilePath string
+
+// newZapLogger creates a zap logger that logs to standard output, to a new log file for the
+// logging program and to the recorder, and returns the logger along with the log file
+func newZapLogger(loggingProgram string, recorder *lineRecorder) (*zap.Logger, *os.File, error) {
+	logDir, err := createDirectoryIfNotExists()
+	if err != nil {
+		return nil, nil, err
+	}
+	logFile, err := createLogFile(logDir, loggingProgram)
+	if err != nil {
+		return nil, nil, err
+	}
+	writerSync := getLogWriter(logFile, recorder)
 	encoder := getEncoder()
 	core := zapcore.NewCore(encoder, writerSync, zapcore.DebugLevel)
 	// TODO: The caller is always log/log.go and it's not useful as we don't know which function in the stack called it.
 	// Can we stack information etc? Or we will remove it for now
 	// Added AddCallerSkip to log stack for above todo
-	globalLogger := zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1))
-
-	zap.ReplaceGlobals(globalLogger)
+	return zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1)), logFile, nil
 }
 
-func createDirectoryIfNotExists() string {
+func createDirectoryIfNotExists() (string, error) {
 	path, err := os.Getwd()
 	if err != nil {
-		log.Fatalf("Error while trying to get working directory: %v", err)
+		return "", fmt.Errorf("error while trying to get working directory: %v", err)
 	}
 
 	logDir := filepath.Join(path, time.Now().Format("2006-01-02-logs"))
 
 	info, err := os.Stat(logDir)
-
 	if err != nil {
 		if !os.IsNotExist(err) {
-			log.Fatalf("Error while trying to get stats about '%s': %v", logDir, err)
+			return "", fmt.Errorf("error while trying to get stats about '%s': %v", logDir, err)
 		}
 
 		err = os.MkdirAll(logDir, os.ModePerm)
 		if err != nil {
-			log.Fatalf("Error while trying to create logs directory at '%s': %v", logDir, err)
+			return "", fmt.Errorf("error while trying to create logs directory at '%s': %v", logDir, err)
 		}
 	}
 
 	if info != nil && !info.IsDir() {
-		log.Fatalf("Error occurred as we want '%s' to be a directory but it is currently a file", logDir)
+		return "", fmt.Errorf("error occurred as we want '%s' to be a directory but it is currently a file", logDir)
 	}
 
-	return logDir
+	return logDir, nil
 }
 
-func getLogWriter(logDirectory string, loggingProgram string) zapcore.WriteSyncer {
+func createLogFile(logDirectory string, loggingProgram string) (*os.File, error) {
 	logFilePath := filepath.Join(logDirectory, fmt.Sprintf("%s-%s.log", loggingProgram, time.Now().Format("2006-01-02-15-04-05")))
 	logFile, err := os.OpenFile(logFilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
-		log.Fatalf("Error while trying to create log file at '%s': %v", logFilePath, err)
+		return nil, fmt.Errorf("error while trying to create log file at '%s': %v", logFilePath, err)
 	}
+	return logFile, nil
+}
+
+func getLogWriter(logFile *os.File, recorder *lineRecorder) zapcore.WriteSyncer {
 	// This way we log to standard output and to the log file, kind of like tee command in Linux :D
-	stdOutAndLogFile := io.MultiWriter(os.Stdout, logFile)
-	return zapcore.AddSync(stdOutAndLogFile)
+	stdOutAndLogFile := io.MultiWriter(os.Stdout, logFile, recorder)
+	// Secrets are scrubbed before anything reaches standard output or the log file
+	return zapcore.AddSync(redactingWriter{writer: stdOutAndLogFile})
 }
 
 func getEncoder() zapcore.Encoder {
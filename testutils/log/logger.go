@@ -0,0 +1,151 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// Logger logs to standard output and to its own log file. Unlike the global logger initialized
+// by InitLogger, many loggers can be used at the same time, for example one per test when tests
+// run in parallel.
+//
+// A nil *Logger is valid and logs using the global logger
+type Logger struct {
+	sugaredLogger *zap.SugaredLogger
+	logFile       *os.File
+	lineRecorder  *lineRecorder
+}
+
+// NewLogger creates a logger that logs to standard output and to a new log file for the logging program
+// in the logs directory. The logger should be closed once it's not needed anymore
+func NewLogger(loggingProgram string) (*Logger, error) {
+	recorder := &lineRecorder{}
+	zapLogger, logFile, err := newZapLogger(loggingProgram, recorder)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Logger{
+		sugaredLogger: zapLogger.Sugar(),
+		logFile:       logFile,
+		lineRecorder:  recorder,
+	}, nil
+}
+
+// NewTestLogger creates a logger for the test using NewLogger. The test fails immediately
+// if the logger cannot be created, and the logger is closed when the test and all its subtests complete
+func NewTestLogger(t testing.TB, loggingProgram string) *Logger {
+	t.Helper()
+
+	logger, err := NewLogger(loggingProgram)
+	if err != nil {
+		t.Fatalf("error while creating logger for the test: %v", err)
+	}
+
+	t.Cleanup(func() {
+		err := logger.Close()
+		if err != nil {
+			t.Logf("error while closing the logger of the test: %v", err)
+		}
+	})
+
+	return logger
+}
+
+func (logger *Logger) sugared() *zap.SugaredLogger {
+	if logger == nil {
+		return zap.S()
+	}
+	return logger.sugaredLogger
+}
+
+// FilePath returns the path of the log file of the logger. For a nil logger, it's the path of the
+// log file of the global logger, which is empty when the global logger has not been initialized
+func (logger *Logger) FilePath() string {
+	if logger == nil {
+		return globalLogFilePath
+	}
+	return logger.logFile.Name()
+}
+
+// Close flushes the logs and closes the log file of the logger
+func (logger *Logger) Close() error {
+	if logger == nil {
+		return nil
+	}
+
+	// Sync errors are ignored as syncing standard output fails on some platforms
+	_ = logger.sugaredLogger.Sync()
+
+	err := logger.logFile.Close()
+	if err != nil {
+		return fmt.Errorf("error while closing log file '%s': %v", logger.logFile.Name(), err)
+	}
+	return nil
+}
+
+func (logger *Logger) Fatalf(template string, args ...interface{}) {
+	logger.sugared().Fatalf(template, args...)
+}
+
+func (logger *Logger) Fatal(args ...interface{}) {
+	logger.sugared().Fatal(args...)
+}
+
+func (logger *Logger) Infof(template string, args ...interface{}) {
+	logger.sugared().Infof(template, args...)
+}
+
+func (logger *Logger) Info(args ...interface{}) {
+	logger.sugared().Info(args...)
+}
+
+func (logger *Logger) Warnf(template string, args ...interface{}) {
+	logger.sugared().Warnf(template, args...)
+}
+
+func (logger *Logger) Warn(args ...interface{}) {
+	logger.sugared().Warn(args...)
+}
+
+func (logger *Logger) DPanicf(template string, args ...interface{}) {
+	logger.sugared().DPanicf(template, args...)
+}
+
+func (logger *Logger) Errorf(template string, args ...interface{}) {
+	logger.sugared().Errorf(template, args...)
+}
+
+// loggerInfoWriter implements the io.Writer interface and logs everything written as info logs
+type loggerInfoWriter struct {
+	logger *Logger
+}
+
+func (w loggerInfoWriter) Write(p []byte) (n int, err error) {
+	w.logger.sugared().Info(string(p))
+	return len(p), nil
+}
+
+// InfoWriter returns a writer that logs everything written to it as info logs, like the package level InfoWriter
+func (logger *Logger) InfoWriter() io.Writer {
+	return loggerInfoWriter{logger: logger}
+}
+
+// loggerErrorWriter implements the io.Writer interface and logs everything written as error logs
+type loggerErrorWriter struct {
+	logger *Logger
+}
+
+func (w loggerErrorWriter) Write(p []byte) (n int, err error) {
+	w.logger.sugared().Error(string(p))
+	return len(p), nil
+}
+
+// ErrorWriter returns a writer that logs everything written to it as error logs, like the package level ErrorWriter
+func (logger *Logger) ErrorWriter() io.Writer {
+	return loggerErrorWriter{logger: logger}
+}
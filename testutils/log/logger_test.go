@@ -0,0 +1,82 @@
+package log_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/karuppiah7890/tce-e2e-test/testutils/log"
+)
+
+func TestNewLogger(t *testing.T) {
+	t.Run("when there are many loggers it should log to separate files", func(t *testing.T) {
+		firstLogger := log.NewTestLogger(t, "logger-test-first")
+		secondLogger := log.NewTestLogger(t, "logger-test-second")
+
+		firstLogger.Infof("log from the %s logger", "first")
+		secondLogger.Infof("log from the %s logger", "second")
+		_, _ = firstLogger.InfoWriter().Write([]byte("output written to the first logger"))
+
+		if firstLogger.FilePath() == secondLogger.FilePath() {
+			t.Fatalf("expected loggers to log to different files but both log to %s", firstLogger.FilePath())
+		}
+
+		firstLogs := readLogFile(t, firstLogger)
+		if !strings.Contains(firstLogs, "log from the first logger") || !strings.Contains(firstLogs, "output written to the first logger") {
+			t.Errorf("expected the first logger's logs in its log file but got: %s", firstLogs)
+		}
+		if strings.Contains(firstLogs, "second") {
+			t.Errorf("expected the second logger's logs to not be in the first logger's log file but got: %s", firstLogs)
+		}
+
+		secondLogs := readLogFile(t, secondLogger)
+		if !strings.Contains(secondLogs, "log from the second logger") {
+			t.Errorf("expected the second logger's logs in its log file but got: %s", secondLogs)
+		}
+	})
+
+	t.Run("when the logger is nil it should log using the global logger", func(t *testing.T) {
+		var logger *log.Logger
+		logger.Infof("log from a nil logger")
+		_, _ = logger.ErrorWriter().Write([]byte("output written to a nil logger"))
+
+		if logger.FilePath() != "" {
+			t.Errorf("expected no log file for a nil logger as the global logger is not initialized but got %s", logger.FilePath())
+		}
+		if err := logger.Close(); err != nil {
+			t.Errorf("expected no error while closing a nil logger but got: %v", err)
+		}
+	})
+}
+
+func readLogFile(t *testing.T, logger *log.Logger) string {
+	t.Helper()
+
+	logs, err := os.ReadFile(logger.FilePath())
+	if err != nil {
+		t.Fatalf("error while reading log file: %v", err)
+	}
+	return string(logs)
+}
+
+func TestLoggerLinesSince(t *testing.T) {
+	t.Run("it should return only the lines logged since the mark", func(t *testing.T) {
+		logger := log.NewTestLogger(t, "logger-test-lines-since")
+
+		logger.Infof("log before the mark")
+		mark := logger.Mark()
+		logger.Infof("first log after the mark")
+		logger.Warnf("second log after the mark")
+
+		lines := logger.LinesSince(mark)
+		if len(lines) != 2 {
+			t.Fatalf("expected 2 lines since the mark but got %d: %v", len(lines), lines)
+		}
+		if !strings.Contains(lines[0], "first log after the mark") || !strings.Contains(lines[1], "second log after the mark") {
+			t.Errorf("expected the lines logged after the mark but got: %v", lines)
+		}
+		if strings.Contains(lines[1], "\x1b[") {
+			t.Errorf("expected the color codes to be removed from the lines but got: %q", lines[1])
+		}
+	})
+}
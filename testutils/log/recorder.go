@@ -0,0 +1,92 @@
+package log
+
+import (
+	"bytes"
+	"regexp"
+	"sync"
+)
+
+// maxRecordedLines is the maximum number of log lines kept in memory by a logger, to be able to show
+// the lines logged during a part of a test run, for example in test reports
+const maxRecordedLines = 1000
+
+// ansiEscapeCodes matches the color codes of the log levels, which are not useful outside a terminal
+var ansiEscapeCodes = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// lineRecorder implements the io.Writer interface and keeps the last lines written to it. Each line
+// gets a sequence number so that the lines written after a point can be fetched
+type lineRecorder struct {
+	mutex    sync.Mutex
+	lines    []string
+	nextLine uint64
+	partial  []byte
+}
+
+func (recorder *lineRecorder) Write(p []byte) (int, error) {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	data := append(recorder.partial, p...)
+	for {
+		index := bytes.IndexByte(data, '\n')
+		if index < 0 {
+			break
+		}
+		recorder.addLine(string(data[:index]))
+		data = data[index+1:]
+	}
+	recorder.partial = append([]byte(nil), data...)
+
+	return len(p), nil
+}
+
+func (recorder *lineRecorder) addLine(line string) {
+	recorder.lines = append(recorder.lines, ansiEscapeCodes.ReplaceAllString(line, ""))
+	if len(recorder.lines) > maxRecordedLines {
+		recorder.lines = recorder.lines[len(recorder.lines)-maxRecordedLines:]
+	}
+	recorder.nextLine++
+}
+
+func (recorder *lineRecorder) mark() uint64 {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	return recorder.nextLine
+}
+
+func (recorder *lineRecorder) linesSince(mark uint64) []string {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	if mark >= recorder.nextLine {
+		return []string{}
+	}
+
+	count := recorder.nextLine - mark
+	if count > uint64(len(recorder.lines)) {
+		count = uint64(len(recorder.lines))
+	}
+	return append([]string(nil), recorder.lines[uint64(len(recorder.lines))-count:]...)
+}
+
+// globalRecorder records the lines logged by the global logger
+var globalRecorder = &lineRecorder{}
+
+func (logger *Logger) recorder() *lineRecorder {
+	if logger == nil {
+		return globalRecorder
+	}
+	return logger.lineRecorder
+}
+
+// Mark returns a mark of the current point in the logs, to be used with LinesSince
+func (logger *Logger) Mark() uint64 {
+	return logger.recorder().mark()
+}
+
+// LinesSince returns the lines logged since the mark was taken. Only the last lines logged by
+// the logger are kept in memory, so older lines are not returned
+func (logger *Logger) LinesSince(mark uint64) []string {
+	return logger.recorder().linesSince(mark)
+}
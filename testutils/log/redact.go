@@ -0,0 +1,130 @@
+package log
+
+import (
+	"encoding/base64"
+	"io"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// RedactedValue is what secrets are replaced with in the logs
+const RedactedValue = "******"
+
+// minSecretLength is the minimum length of a value to be treated as a secret. Shorter values
+// like "1" or "true" are ignored as scrubbing them would make the logs unreadable
+const minSecretLength = 4
+
+// secretRegistry holds the registered secrets and a replacer that scrubs them
+type secretRegistry struct {
+	mutex    sync.RWMutex
+	secrets  map[string]struct{}
+	replacer *strings.Replacer
+}
+
+var secrets = &secretRegistry{secrets: map[string]struct{}{}}
+
+// RegisterSecrets registers values that must never show up in the logs. The values, along with their
+// base64 encoded and URL encoded forms, are scrubbed from every log line and from any string passed to Redact
+func RegisterSecrets(values ...string) {
+	secrets.register(values...)
+}
+
+// RegisterSecretsFromEnvVars registers the values of the given environment variables as secrets.
+// Environment variables that are not defined are ignored
+func RegisterSecretsFromEnvVars(envVarNames ...string) {
+	values := make([]string, 0, len(envVarNames))
+	for _, envVarName := range envVarNames {
+		if value, isDefined := os.LookupEnv(envVarName); isDefined {
+			values = append(values, value)
+		}
+	}
+	secrets.register(values...)
+}
+
+// Redact returns the string with all the registered secrets replaced with RedactedValue
+func Redact(s string) string {
+	return secrets.redact(s)
+}
+
+// ContainsSecret returns whether the string has any of the registered secrets
+func ContainsSecret(s string) bool {
+	return Redact(s) != s
+}
+
+func (registry *secretRegistry) register(values ...string) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	for _, value := range values {
+		for _, form := range secretForms(value) {
+			if len(form) >= minSecretLength {
+				registry.secrets[form] = struct{}{}
+			}
+		}
+	}
+
+	forms := make([]string, 0, len(registry.secrets))
+	for form := range registry.secrets {
+		forms = append(forms, form)
+	}
+	// strings.Replacer tries the old strings in argument order, so longer secrets come first
+	// to scrub them fully when a shorter secret is a part of them
+	sort.Slice(forms, func(i, j int) bool {
+		if len(forms[i]) != len(forms[j]) {
+			return len(forms[i]) > len(forms[j])
+		}
+		return forms[i] < forms[j]
+	})
+
+	oldNew := make([]string, 0, 2*len(forms))
+	for _, form := range forms {
+		oldNew = append(oldNew, form, RedactedValue)
+	}
+	registry.replacer = strings.NewReplacer(oldNew...)
+}
+
+func (registry *secretRegistry) redact(s string) string {
+	registry.mutex.RLock()
+	defer registry.mutex.RUnlock()
+
+	if registry.replacer == nil {
+		return s
+	}
+	return registry.replacer.Replace(s)
+}
+
+// secretForms returns the secret value along with the encoded forms in which it can show up
+// in command output, for example in Kubernetes secrets or in URLs
+func secretForms(value string) []string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+
+	return []string{
+		value,
+		base64.StdEncoding.EncodeToString([]byte(value)),
+		base64.RawStdEncoding.EncodeToString([]byte(value)),
+		base64.URLEncoding.EncodeToString([]byte(value)),
+		base64.RawURLEncoding.EncodeToString([]byte(value)),
+		url.QueryEscape(value),
+		url.PathEscape(value),
+	}
+}
+
+// redactingWriter implements the io.Writer interface and scrubs the registered secrets from
+// the data before writing it to the underlying writer
+type redactingWriter struct {
+	writer io.Writer
+}
+
+func (w redactingWriter) Write(p []byte) (int, error) {
+	_, err := io.WriteString(w.writer, Redact(string(p)))
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
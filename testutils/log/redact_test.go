@@ -0,0 +1,47 @@
+package log_test
+
+import (
+	"encoding/base64"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/karuppiah7890/tce-e2e-test/testutils/log"
+)
+
+func TestRedact(t *testing.T) {
+	secret := "s3cr3t/pass+word=="
+	log.RegisterSecrets(secret)
+
+	t.Run("it should scrub the secret in raw, base64 encoded and URL encoded forms", func(t *testing.T) {
+		forms := []string{
+			secret,
+			base64.StdEncoding.EncodeToString([]byte(secret)),
+			base64.URLEncoding.EncodeToString([]byte(secret)),
+			base64.RawStdEncoding.EncodeToString([]byte(secret)),
+			url.QueryEscape(secret),
+			url.PathEscape(secret),
+		}
+		for _, form := range forms {
+			redacted := log.Redact("value: " + form + " end")
+			if redacted != "value: "+log.RedactedValue+" end" {
+				t.Errorf("expected %q to be scrubbed but got %q", form, redacted)
+			}
+		}
+	})
+
+	t.Run("when the secret is very short it should not be scrubbed", func(t *testing.T) {
+		log.RegisterSecrets("abc", "")
+		if redacted := log.Redact("abc"); redacted != "abc" {
+			t.Errorf("expected short value to be left as is but got %q", redacted)
+		}
+	})
+
+	t.Run("it should scrub secrets set in environment variables", func(t *testing.T) {
+		t.Setenv("REDACT_TEST_SECRET", "env-secret-value")
+		log.RegisterSecretsFromEnvVars("REDACT_TEST_SECRET", "REDACT_TEST_UNDEFINED")
+		if redacted := log.Redact("token=env-secret-value"); strings.Contains(redacted, "env-secret-value") {
+			t.Errorf("expected secret from environment variable to be scrubbed but got %q", redacted)
+		}
+	})
+}
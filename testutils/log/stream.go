@@ -0,0 +1,222 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Level is the level at which a line of a command's output is logged
+type Level int
+
+const (
+	InfoLevel Level = iota
+	WarnLevel
+	ErrorLevel
+)
+
+// maxErrorLines is the maximum number of error lines kept by a StreamWriter. When there are more
+// error lines, only the last ones are kept
+const maxErrorLines = 50
+
+// Default patterns used to classify the lines of a command's output stream. tanzu prints its progress
+// to standard error along with the actual errors, so only the lines that look like errors are logged as errors
+var (
+	DefaultErrorPatterns = []string{
+		`(?i)^\s*error\b`,
+		`\bError:`,
+		`(?i)\bfailed\b`,
+		`(?i)^\s*fatal\b`,
+	}
+	DefaultWarnPatterns = []string{
+		`(?i)^\s*warn(ing)?\b`,
+		`(?i)\bwarn(ing)?:`,
+		`(?i)\bretrying\b`,
+	}
+	DefaultPanicPatterns = []string{
+		`^panic:`,
+		`^fatal error:`,
+	}
+)
+
+// StreamClassifier classifies the lines of a command's output stream into log levels using patterns.
+// A line matching any of the error patterns is an error, else a line matching any of the warning patterns
+// is a warning, else it's an info. A line matching any of the panic patterns, and all the lines after it,
+// like the panic's stack trace, are errors
+type StreamClassifier struct {
+	ErrorPatterns []*regexp.Regexp
+	WarnPatterns  []*regexp.Regexp
+	PanicPatterns []*regexp.Regexp
+}
+
+var defaultStreamClassifier = mustNewStreamClassifier(DefaultErrorPatterns, DefaultWarnPatterns, DefaultPanicPatterns)
+
+// DefaultStreamClassifier returns the stream classifier that uses the default patterns
+func DefaultStreamClassifier() *StreamClassifier {
+	return defaultStreamClassifier
+}
+
+// NewStreamClassifier creates a stream classifier with the given error, warning and panic patterns,
+// which use the regular expression syntax accepted by the regexp package
+func NewStreamClassifier(errorPatterns, warnPatterns, panicPatterns []string) (*StreamClassifier, error) {
+	errorRegexps, err := compilePatterns(errorPatterns)
+	if err != nil {
+		return nil, fmt.Errorf("error compiling error patterns: %v", err)
+	}
+
+	warnRegexps, err := compilePatterns(warnPatterns)
+	if err != nil {
+		return nil, fmt.Errorf("error compiling warning patterns: %v", err)
+	}
+
+	panicRegexps, err := compilePatterns(panicPatterns)
+	if err != nil {
+		return nil, fmt.Errorf("error compiling panic patterns: %v", err)
+	}
+
+	return &StreamClassifier{
+		ErrorPatterns: errorRegexps,
+		WarnPatterns:  warnRegexps,
+		PanicPatterns: panicRegexps,
+	}, nil
+}
+
+func mustNewStreamClassifier(errorPatterns, warnPatterns, panicPatterns []string) *StreamClassifier {
+	classifier, err := NewStreamClassifier(errorPatterns, warnPatterns, panicPatterns)
+	if err != nil {
+		panic(err)
+	}
+	return classifier
+}
+
+func compilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	regexps := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		compiledRegexp, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern `%s`: %v", pattern, err)
+		}
+		regexps = append(regexps, compiledRegexp)
+	}
+	return regexps, nil
+}
+
+// Classify returns the level of the line
+func (classifier *StreamClassifier) Classify(line string) Level {
+	if matchesAny(classifier.ErrorPatterns, line) || matchesAny(classifier.PanicPatterns, line) {
+		return ErrorLevel
+	}
+	if matchesAny(classifier.WarnPatterns, line) {
+		return WarnLevel
+	}
+	return InfoLevel
+}
+
+// IsPanic returns true if the line is the start of a panic
+func (classifier *StreamClassifier) IsPanic(line string) bool {
+	return matchesAny(classifier.PanicPatterns, line)
+}
+
+func matchesAny(regexps []*regexp.Regexp, line string) bool {
+	for _, r := range regexps {
+		if r.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// StreamWriter implements the io.Writer interface. It splits the data written to it into lines and
+// logs each line, tagged with the stream name, at the level given by the classifier. The lines
+// classified as errors are kept so that they can be shown as the summary of a failure
+type StreamWriter struct {
+	mutex      sync.Mutex
+	logger     *Logger
+	stream     string
+	classifier *StreamClassifier
+	partial    []byte
+	inPanic    bool
+	errorLines []string
+}
+
+// StreamWriter returns a writer that logs the lines of the stream with the given name, like
+// "tanzu stderr", using the classifier. If the classifier is nil, the default classifier is used.
+// Flush should be called once the stream ends to log any last line that does not end with a newline
+func (logger *Logger) StreamWriter(stream string, classifier *StreamClassifier) *StreamWriter {
+	if classifier == nil {
+		classifier = DefaultStreamClassifier()
+	}
+	return &StreamWriter{
+		logger:     logger,
+		stream:     stream,
+		classifier: classifier,
+	}
+}
+
+func (w *StreamWriter) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	data := append(w.partial, p...)
+	for {
+		index := bytes.IndexByte(data, '\n')
+		if index < 0 {
+			break
+		}
+		w.logLine(string(data[:index]))
+		data = data[index+1:]
+	}
+	w.partial = append([]byte(nil), data...)
+
+	return len(p), nil
+}
+
+// Flush logs the last line written, if it does not end with a newline
+func (w *StreamWriter) Flush() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if len(w.partial) != 0 {
+		w.logLine(string(w.partial))
+		w.partial = nil
+	}
+}
+
+// ErrorLines returns the last lines of the stream that were classified as errors
+func (w *StreamWriter) ErrorLines() []string {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	return append([]string(nil), w.errorLines...)
+}
+
+func (w *StreamWriter) logLine(line string) {
+	line = strings.TrimSuffix(line, "\r")
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+
+	if w.classifier.IsPanic(line) {
+		w.inPanic = true
+	}
+
+	level := w.classifier.Classify(line)
+	if w.inPanic {
+		level = ErrorLevel
+	}
+
+	switch level {
+	case ErrorLevel:
+		w.logger.sugared().Errorw(line, "stream", w.stream)
+		w.errorLines = append(w.errorLines, line)
+		if len(w.errorLines) > maxErrorLines {
+			w.errorLines = w.errorLines[len(w.errorLines)-maxErrorLines:]
+		}
+	case WarnLevel:
+		w.logger.sugared().Warnw(line, "stream", w.stream)
+	default:
+		w.logger.sugared().Infow(line, "stream", w.stream)
+	}
+}
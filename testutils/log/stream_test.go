@@ -0,0 +1,63 @@
+package log_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/karuppiah7890/tce-e2e-test/testutils/log"
+)
+
+func TestStreamClassifier(t *testing.T) {
+	classifier := log.DefaultStreamClassifier()
+
+	testCases := []struct {
+		line          string
+		expectedLevel log.Level
+	}{
+		{line: "Validating configuration...", expectedLevel: log.InfoLevel},
+		{line: "Warning: Pinniped configuration not found. Skipping pinniped configuration", expectedLevel: log.WarnLevel},
+		{line: "Error: unable to create cluster", expectedLevel: log.ErrorLevel},
+		{line: "Failed to create management cluster", expectedLevel: log.ErrorLevel},
+		{line: "panic: runtime error: invalid memory address or nil pointer dereference", expectedLevel: log.ErrorLevel},
+	}
+
+	for _, testCase := range testCases {
+		if level := classifier.Classify(testCase.line); level != testCase.expectedLevel {
+			t.Errorf("expected line %q to be classified as level %v but got %v", testCase.line, testCase.expectedLevel, level)
+		}
+	}
+
+	t.Run("when a pattern is invalid it should return an error", func(t *testing.T) {
+		_, err := log.NewStreamClassifier([]string{"("}, nil, nil)
+		if err == nil {
+			t.Errorf("expected error for an invalid pattern")
+		}
+	})
+}
+
+func TestStreamWriter(t *testing.T) {
+	logger := log.NewTestLogger(t, "stream-writer-test")
+
+	t.Run("it should keep the error lines including the panic trace", func(t *testing.T) {
+		stream := logger.StreamWriter("tanzu stderr", nil)
+
+		_, _ = stream.Write([]byte("Setting up management cluster...\nError: could not "))
+		_, _ = stream.Write([]byte("create cluster\nStill going\npanic: something went wrong\n\ngoroutine 1 [running]:\nmain.main()"))
+		stream.Flush()
+
+		expectedErrorLines := []string{
+			"Error: could not create cluster",
+			"panic: something went wrong",
+			"goroutine 1 [running]:",
+			"main.main()",
+		}
+		if got := strings.Join(stream.ErrorLines(), "|"); got != strings.Join(expectedErrorLines, "|") {
+			t.Errorf("expected error lines %q but got %q", expectedErrorLines, stream.ErrorLines())
+		}
+
+		logs := readLogFile(t, logger)
+		if !strings.Contains(logs, "tanzu stderr") {
+			t.Errorf("expected the lines to be tagged with the stream name but got: %s", logs)
+		}
+	})
+}
@@ -0,0 +1,79 @@
+package manifest
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kubeRuntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	yamlserializer "k8s.io/apimachinery/pkg/runtime/serializer/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/karuppiah7890/tce-e2e-test/testutils"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/kubescheme"
+)
+
+// Manifest is a cluster manifest, like the one rendered by `tanzu management-cluster create --dry-run`
+type Manifest struct {
+	// Objects are the objects of the manifest. The kinds known to kubescheme are typed, like
+	// *v1beta1.Cluster, and the other kinds are *unstructured.Unstructured
+	Objects []client.Object
+}
+
+// Parse parses the YAML documents of a cluster manifest
+func Parse(data []byte) (*Manifest, error) {
+	documents, err := testutils.SplitYAML(data)
+	if err != nil {
+		return nil, fmt.Errorf("error while splitting manifest into YAML documents: %v", err)
+	}
+
+	decoder := serializer.NewCodecFactory(kubescheme.GetScheme()).UniversalDeserializer()
+	unstructuredDecoder := yamlserializer.NewDecodingSerializer(unstructured.UnstructuredJSONScheme)
+
+	manifest := &Manifest{Objects: []client.Object{}}
+	for index, document := range documents {
+		if strings.TrimSpace(string(document)) == "" || strings.TrimSpace(string(document)) == "null" {
+			continue
+		}
+
+		obj, _, err := decoder.Decode(document, nil, nil)
+		if kubeRuntime.IsNotRegisteredError(err) {
+			obj, _, err = unstructuredDecoder.Decode(document, nil, &unstructured.Unstructured{})
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error while decoding YAML document %d of manifest: %v", index+1, err)
+		}
+
+		clientObject, ok := obj.(client.Object)
+		if !ok {
+			return nil, fmt.Errorf("YAML document %d of manifest is not a Kubernetes object", index+1)
+		}
+		manifest.Objects = append(manifest.Objects, clientObject)
+	}
+
+	return manifest, nil
+}
+
+// Kind returns the kind of the object, which is not set in the type meta of typed objects once they are decoded
+func Kind(obj client.Object) string {
+	kind := obj.GetObjectKind().GroupVersionKind().Kind
+	if kind != "" {
+		return kind
+	}
+	gvks, _, err := kubescheme.GetScheme().ObjectKinds(obj)
+	if err != nil || len(gvks) == 0 {
+		return ""
+	}
+	return gvks[0].Kind
+}
+
+// Find returns the object of the manifest with the kind and name, or nil when there's no such object
+func (manifest *Manifest) Find(kind, name string) client.Object {
+	for _, obj := range manifest.Objects {
+		if Kind(obj) == kind && obj.GetName() == name {
+			return obj
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package manifest_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/karuppiah7890/tce-e2e-test/testutils/manifest"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+func readManifest(t *testing.T, replacements ...string) *manifest.Manifest {
+	data, err := os.ReadFile("testdata/aws-cluster.yaml")
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+
+	clusterManifest, err := manifest.Parse([]byte(strings.NewReplacer(replacements...).Replace(string(data))))
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	return clusterManifest
+}
+
+func TestParse(t *testing.T) {
+	t.Run("when the manifest has known and unknown kinds it should parse all of them", func(t *testing.T) {
+		clusterManifest := readManifest(t)
+
+		if len(clusterManifest.Objects) != 10 {
+			t.Fatalf("expected 10 objects but got %d", len(clusterManifest.Objects))
+		}
+		if _, ok := clusterManifest.Find("Cluster", "test-mgmt-1").(*clusterv1.Cluster); !ok {
+			t.Errorf("expected Cluster to be parsed as a typed object")
+		}
+		if _, ok := clusterManifest.Find("TanzuKubernetesRelease", "v1.22.8---vmware.1-tkg.1").(*unstructured.Unstructured); !ok {
+			t.Errorf("expected TanzuKubernetesRelease to be parsed as an unstructured object")
+		}
+	})
+
+	t.Run("when the manifest is not valid YAML it should return an error", func(t *testing.T) {
+		_, err := manifest.Parse([]byte("kind: [Cluster"))
+		if err == nil {
+			t.Errorf("expected an error but got none")
+		}
+	})
+}
+
+func TestValidate(t *testing.T) {
+	t.Run("when the manifest is valid it should return no error", func(t *testing.T) {
+		err := readManifest(t).Validate("test-mgmt-1")
+		if err != nil {
+			t.Errorf("expected no error but got: %v", err)
+		}
+	})
+
+	t.Run("when the manifest is for another cluster it should return an error", func(t *testing.T) {
+		err := readManifest(t).Validate("test-mgmt-2")
+		if err == nil || !strings.Contains(err.Error(), "Cluster object is named test-mgmt-1, expected test-mgmt-2") {
+			t.Errorf("expected an error about the cluster name but got: %v", err)
+		}
+	})
+
+	t.Run("when CIDRs overlap it should return an error", func(t *testing.T) {
+		err := readManifest(t, "cidrBlock: 10.0.0.0/16", "cidrBlock: 100.64.0.0/10").Validate("test-mgmt-1")
+		if err == nil {
+			t.Fatalf("expected an error but got none")
+		}
+		for _, problem := range []string{
+			"pods CIDR 100.96.0.0/11 overlaps with AWS VPC CIDR 100.64.0.0/10",
+			"services CIDR 100.64.0.0/13 overlaps with AWS VPC CIDR 100.64.0.0/10",
+		} {
+			if !strings.Contains(err.Error(), problem) {
+				t.Errorf("expected error to contain %q but got: %v", problem, err)
+			}
+		}
+	})
+
+	t.Run("when a machine template has no machine type or is missing it should return an error", func(t *testing.T) {
+		err := readManifest(t, "instanceType: m5.xlarge\n---\napiVersion: controlplane", "instanceType: \"\"\n---\napiVersion: controlplane", "name: test-mgmt-1-md-0\n---\napiVersion: cluster.x-k8s.io/v1beta1\nkind: MachineHealthCheck", "name: test-mgmt-1-md-1\n---\napiVersion: cluster.x-k8s.io/v1beta1\nkind: MachineHealthCheck").Validate("test-mgmt-1")
+		if err == nil {
+			t.Fatalf("expected an error but got none")
+		}
+		for _, problem := range []string{
+			"AWSMachineTemplate test-mgmt-1-control-plane of KubeadmControlPlane test-mgmt-1-control-plane has no instance type",
+			"MachineDeployment test-mgmt-1-md-0 refers to AWSMachineTemplate test-mgmt-1-md-1 which is not in the manifest",
+		} {
+			if !strings.Contains(err.Error(), problem) {
+				t.Errorf("expected error to contain %q but got: %v", problem, err)
+			}
+		}
+	})
+}
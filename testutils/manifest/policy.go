@@ -0,0 +1,274 @@
+package manifest
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kubeRuntime "k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiControlplaneKubeadmv1beta "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Policy is the set of rules the manifest of a cluster is expected to follow for the cluster config it was
+// rendered with. The zero value of each rule turns the rule off
+type Policy struct {
+	// ControlPlaneReplicas is the number of control plane machines of the cluster, like 1 for the dev plan
+	ControlPlaneReplicas int32
+	// MachineHealthCheck requires a MachineHealthCheck for the cluster
+	MachineHealthCheck bool
+	// KubernetesVersion is the Kubernetes version of the control plane and the machine deployments, like
+	// v1.22.8+vmware.1. When empty, the Kubernetes version of the TanzuKubernetesRelease in the manifest, if
+	// any, is used
+	KubernetesVersion string
+	// NoLatestImageTags forbids images with the latest tag, or with no tag at all, anywhere in the manifest,
+	// including in the YAML embedded in ConfigMaps and Secrets, like the ones of ClusterResourceSets
+	NoLatestImageTags bool
+}
+
+// planControlPlaneReplicas is the number of control plane machines of each cluster plan
+var planControlPlaneReplicas = map[string]int32{
+	"dev":  1,
+	"prod": 3,
+}
+
+// embeddedImagePattern matches the images of the YAML embedded in strings, like `image: nginx:1.21`
+var embeddedImagePattern = regexp.MustCompile(`(?m)^\s*(?:-\s+)?image:\s*["']?([^\s"'#]+)`)
+
+// PolicyForConfig returns the policy of the manifest of a cluster rendered with the tanzu config:
+//
+//   - the control plane replicas of the CLUSTER_PLAN plan, or CONTROL_PLANE_MACHINE_COUNT when set
+//   - a MachineHealthCheck when ENABLE_MHC is true
+//   - the Kubernetes version of the KUBERNETES_RELEASE TKr when set
+//   - no images with the latest tag
+func PolicyForConfig(config map[string]string) Policy {
+	policy := Policy{
+		ControlPlaneReplicas: planControlPlaneReplicas[config["CLUSTER_PLAN"]],
+		NoLatestImageTags:    true,
+	}
+
+	if count, err := strconv.ParseInt(config["CONTROL_PLANE_MACHINE_COUNT"], 10, 32); err == nil {
+		policy.ControlPlaneReplicas = int32(count)
+	}
+	if enabled, err := strconv.ParseBool(config["ENABLE_MHC"]); err == nil {
+		policy.MachineHealthCheck = enabled
+	}
+	if tkr := config["KUBERNETES_RELEASE"]; tkr != "" {
+		policy.KubernetesVersion = KubernetesVersionOfTKr(tkr)
+	}
+
+	return policy
+}
+
+// KubernetesVersionOfTKr returns the Kubernetes version of a TKr name or version, like v1.22.8+vmware.1
+// for v1.22.8---vmware.1-tkg.1
+func KubernetesVersionOfTKr(tkr string) string {
+	version := strings.ReplaceAll(tkr, "---", "+")
+	if index := strings.LastIndex(version, "-tkg."); index != -1 {
+		version = version[:index]
+	}
+	return version
+}
+
+// CheckPolicy checks the manifest of the cluster against the policy and returns an error with all the
+// violations found
+func (manifest *Manifest) CheckPolicy(clusterName string, policy Policy) error {
+	violations := []string{}
+
+	if policy.ControlPlaneReplicas != 0 {
+		violations = append(violations, manifest.checkControlPlaneReplicas(policy.ControlPlaneReplicas)...)
+	}
+	if policy.MachineHealthCheck {
+		violations = append(violations, manifest.checkMachineHealthCheck(clusterName)...)
+	}
+	violations = append(violations, manifest.checkKubernetesVersion(policy.KubernetesVersion)...)
+	if policy.NoLatestImageTags {
+		violations = append(violations, manifest.checkImageTags()...)
+	}
+
+	if len(violations) != 0 {
+		return fmt.Errorf("manifest of cluster %s violates the policy: %s", clusterName, strings.Join(violations, "; "))
+	}
+	return nil
+}
+
+func (manifest *Manifest) checkControlPlaneReplicas(expectedReplicas int32) []string {
+	violations := []string{}
+
+	for _, obj := range manifest.Objects {
+		controlPlane, ok := obj.(*capiControlplaneKubeadmv1beta.KubeadmControlPlane)
+		if !ok {
+			continue
+		}
+		// The replicas default to 1 when not set
+		replicas := int32(1)
+		if controlPlane.Spec.Replicas != nil {
+			replicas = *controlPlane.Spec.Replicas
+		}
+		if replicas != expectedReplicas {
+			violations = append(violations, fmt.Sprintf("KubeadmControlPlane %s has %d replicas, expected %d", controlPlane.Name, replicas, expectedReplicas))
+		}
+	}
+
+	return violations
+}
+
+func (manifest *Manifest) checkMachineHealthCheck(clusterName string) []string {
+	for _, obj := range manifest.Objects {
+		if healthCheck, ok := obj.(*clusterv1.MachineHealthCheck); ok && healthCheck.Spec.ClusterName == clusterName {
+			return nil
+		}
+	}
+	return []string{fmt.Sprintf("no MachineHealthCheck found for cluster %s, expected one as machine health checks are enabled", clusterName)}
+}
+
+func (manifest *Manifest) checkKubernetesVersion(expectedVersion string) []string {
+	if expectedVersion == "" {
+		expectedVersion = manifest.tkrKubernetesVersion()
+	}
+	if expectedVersion == "" {
+		return nil
+	}
+
+	violations := []string{}
+	checkVersion := func(owner string, version *string) {
+		if version != nil && *version != expectedVersion {
+			violations = append(violations, fmt.Sprintf("%s has Kubernetes version %s, expected %s", owner, *version, expectedVersion))
+		}
+	}
+
+	for _, obj := range manifest.Objects {
+		switch machines := obj.(type) {
+		case *capiControlplaneKubeadmv1beta.KubeadmControlPlane:
+			checkVersion(fmt.Sprintf("KubeadmControlPlane %s", machines.Name), &machines.Spec.Version)
+		case *clusterv1.MachineDeployment:
+			checkVersion(fmt.Sprintf("MachineDeployment %s", machines.Name), machines.Spec.Template.Spec.Version)
+		}
+	}
+
+	return violations
+}
+
+// tkrKubernetesVersion returns the Kubernetes version of the TanzuKubernetesRelease in the manifest, or an
+// empty string when there's none
+func (manifest *Manifest) tkrKubernetesVersion() string {
+	for _, obj := range manifest.Objects {
+		tkr, ok := obj.(*unstructured.Unstructured)
+		if !ok || Kind(tkr) != "TanzuKubernetesRelease" {
+			continue
+		}
+		version, _, _ := unstructured.NestedString(tkr.Object, "spec", "kubernetesVersion")
+		if version != "" {
+			return version
+		}
+		return KubernetesVersionOfTKr(tkr.GetName())
+	}
+	return ""
+}
+
+func (manifest *Manifest) checkImageTags() []string {
+	violations := []string{}
+
+	for _, obj := range manifest.Objects {
+		unpinned := map[string]bool{}
+		addImage := func(image string) {
+			if problem := unpinnedImage(image); problem != "" {
+				unpinned[problem] = true
+			}
+		}
+
+		content, err := toUnstructuredContent(obj)
+		if err != nil {
+			violations = append(violations, fmt.Sprintf("error while reading the images of %s %s: %v", Kind(obj), obj.GetName(), err))
+			continue
+		}
+		findImages(content, addImage)
+
+		// The data of Secrets is base64 encoded in the unstructured content
+		if secret, ok := obj.(*corev1.Secret); ok {
+			for _, data := range secret.Data {
+				findImages(string(data), addImage)
+			}
+		}
+
+		for _, problem := range sortedKeys(unpinned) {
+			violations = append(violations, fmt.Sprintf("%s %s has %s, expected a pinned image tag", Kind(obj), obj.GetName(), problem))
+		}
+	}
+
+	return violations
+}
+
+func toUnstructuredContent(obj client.Object) (map[string]interface{}, error) {
+	if unstructuredObj, ok := obj.(*unstructured.Unstructured); ok {
+		return unstructuredObj.Object, nil
+	}
+	return kubeRuntime.DefaultUnstructuredConverter.ToUnstructured(obj)
+}
+
+// findImages finds the images in the unstructured content - the values of the image fields, the values of
+// the imageTag fields, like the one of etcd in a KubeadmControlPlane, as tags, and the images of embedded YAML
+func findImages(value interface{}, addImage func(image string)) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, field := range value {
+			switch fieldValue := field.(type) {
+			case string:
+				if key == "image" {
+					addImage(fieldValue)
+					continue
+				}
+				if key == "imageTag" {
+					addImage(":" + fieldValue)
+					continue
+				}
+			}
+			findImages(field, addImage)
+		}
+	case []interface{}:
+		for _, item := range value {
+			findImages(item, addImage)
+		}
+	case string:
+		for _, match := range embeddedImagePattern.FindAllStringSubmatch(value, -1) {
+			addImage(match[1])
+		}
+	}
+}
+
+// unpinnedImage returns the problem with the image when it has the latest tag, or has no tag and no digest,
+// which means the latest tag. An image of just a tag, like `:v3.5.2`, is the tag of an imageTag field
+func unpinnedImage(image string) string {
+	if strings.Contains(image, "@") {
+		return ""
+	}
+	name := image
+	if index := strings.LastIndex(name, "/"); index != -1 {
+		name = name[index+1:]
+	}
+	index := strings.LastIndex(name, ":")
+	switch {
+	case index == -1:
+		return fmt.Sprintf("image %s with no tag", image)
+	case name[index+1:] != "latest":
+		return ""
+	case index == 0 && image == name:
+		return "image tag latest"
+	default:
+		return fmt.Sprintf("image %s", image)
+	}
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
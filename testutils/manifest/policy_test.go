@@ -0,0 +1,79 @@
+package manifest_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/karuppiah7890/tce-e2e-test/testutils/manifest"
+)
+
+func TestPolicyForConfig(t *testing.T) {
+	t.Run("when the config has a plan, MHC enabled and a TKr it should return the policy of them", func(t *testing.T) {
+		policy := manifest.PolicyForConfig(map[string]string{
+			"CLUSTER_PLAN":       "prod",
+			"ENABLE_MHC":         "true",
+			"KUBERNETES_RELEASE": "v1.22.8---vmware.1-tkg.1",
+		})
+
+		expectedPolicy := manifest.Policy{
+			ControlPlaneReplicas: 3,
+			MachineHealthCheck:   true,
+			KubernetesVersion:    "v1.22.8+vmware.1",
+			NoLatestImageTags:    true,
+		}
+		if !reflect.DeepEqual(policy, expectedPolicy) {
+			t.Errorf("expected policy %+v but got %+v", expectedPolicy, policy)
+		}
+	})
+
+	t.Run("when the config has a control plane machine count it should be used over the plan's", func(t *testing.T) {
+		policy := manifest.PolicyForConfig(map[string]string{
+			"CLUSTER_PLAN":                "dev",
+			"CONTROL_PLANE_MACHINE_COUNT": "3",
+		})
+
+		if policy.ControlPlaneReplicas != 3 {
+			t.Errorf("expected 3 control plane replicas but got %d", policy.ControlPlaneReplicas)
+		}
+	})
+}
+
+func TestCheckPolicy(t *testing.T) {
+	policy := manifest.Policy{
+		ControlPlaneReplicas: 1,
+		MachineHealthCheck:   true,
+		NoLatestImageTags:    true,
+	}
+
+	t.Run("when the manifest follows the policy it should return no error", func(t *testing.T) {
+		err := readManifest(t).CheckPolicy("test-mgmt-1", policy)
+		if err != nil {
+			t.Errorf("expected no error but got: %v", err)
+		}
+	})
+
+	t.Run("when the manifest violates the policy it should return an error with all the violations", func(t *testing.T) {
+		err := readManifest(t,
+			"replicas: 1\n  version", "replicas: 3\n  version",
+			"kind: MachineHealthCheck", "kind: MachineSet",
+			"version: v1.22.8+vmware.1\n      bootstrap", "version: v1.21.2+vmware.1\n      bootstrap",
+			"imageTag: v3.5.2_vmware.3", "imageTag: latest",
+			"aws-ebs-csi-driver:v1.4.0_vmware.1", "aws-ebs-csi-driver",
+		).CheckPolicy("test-mgmt-1", policy)
+		if err == nil {
+			t.Fatalf("expected an error but got none")
+		}
+		for _, violation := range []string{
+			"KubeadmControlPlane test-mgmt-1-control-plane has 3 replicas, expected 1",
+			"no MachineHealthCheck found for cluster test-mgmt-1",
+			"MachineDeployment test-mgmt-1-md-0 has Kubernetes version v1.21.2+vmware.1, expected v1.22.8+vmware.1",
+			"KubeadmControlPlane test-mgmt-1-control-plane has image tag latest",
+			"Secret test-mgmt-1-aws-ebs-csi-driver has image projects.registry.vmware.com/tkg/aws-ebs-csi-driver with no tag",
+		} {
+			if !strings.Contains(err.Error(), violation) {
+				t.Errorf("expected error to contain %q but got: %v", violation, err)
+			}
+		}
+	})
+}
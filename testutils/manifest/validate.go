@@ -0,0 +1,156 @@
+package manifest
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	capav1beta1 "sigs.k8s.io/cluster-api-provider-aws/api/v1beta1"
+	capzv1beta1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	capvv1beta1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiControlplaneKubeadmv1beta "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+)
+
+// namedCIDR is a CIDR of a cluster network, along with what it's for, like the pods
+type namedCIDR struct {
+	name    string
+	network *net.IPNet
+}
+
+// Validate validates the manifest of the cluster and returns an error with all the problems found:
+//
+//   - the manifest has the Cluster object of the cluster
+//   - the CIDRs of the pods, the services and the provider's network, like the AWS VPC, are valid and don't overlap
+//   - the machines of the control plane and of the machine deployments refer to infrastructure machine templates
+//     in the manifest, and the machine templates have a machine type, like an AWS instance type or an Azure VM size
+func (manifest *Manifest) Validate(clusterName string) error {
+	problems := []string{}
+
+	clusters := []*clusterv1.Cluster{}
+	for _, obj := range manifest.Objects {
+		if cluster, ok := obj.(*clusterv1.Cluster); ok {
+			clusters = append(clusters, cluster)
+		}
+	}
+	switch {
+	case len(clusters) == 0:
+		problems = append(problems, "no Cluster object found")
+	case len(clusters) > 1:
+		problems = append(problems, fmt.Sprintf("%d Cluster objects found, expected one", len(clusters)))
+	case clusters[0].Name != clusterName:
+		problems = append(problems, fmt.Sprintf("Cluster object is named %s, expected %s", clusters[0].Name, clusterName))
+	}
+
+	problems = append(problems, manifest.validateCIDRs(clusters)...)
+	problems = append(problems, manifest.validateMachines()...)
+
+	if len(problems) != 0 {
+		return fmt.Errorf("invalid manifest of cluster %s: %s", clusterName, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func (manifest *Manifest) validateCIDRs(clusters []*clusterv1.Cluster) []string {
+	problems := []string{}
+	cidrs := []namedCIDR{}
+
+	addCIDRs := func(name string, values ...string) {
+		for _, value := range values {
+			if value == "" {
+				continue
+			}
+			_, network, err := net.ParseCIDR(value)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s CIDR %q is not a valid CIDR", name, value))
+				continue
+			}
+			cidrs = append(cidrs, namedCIDR{name: name, network: network})
+		}
+	}
+
+	for _, cluster := range clusters {
+		if cluster.Spec.ClusterNetwork == nil {
+			continue
+		}
+		if cluster.Spec.ClusterNetwork.Pods != nil {
+			addCIDRs("pods", cluster.Spec.ClusterNetwork.Pods.CIDRBlocks...)
+		}
+		if cluster.Spec.ClusterNetwork.Services != nil {
+			addCIDRs("services", cluster.Spec.ClusterNetwork.Services.CIDRBlocks...)
+		}
+	}
+
+	for _, obj := range manifest.Objects {
+		switch infraCluster := obj.(type) {
+		case *capav1beta1.AWSCluster:
+			addCIDRs("AWS VPC", infraCluster.Spec.NetworkSpec.VPC.CidrBlock)
+		case *capzv1beta1.AzureCluster:
+			addCIDRs("Azure virtual network", infraCluster.Spec.NetworkSpec.Vnet.CIDRBlocks...)
+		}
+	}
+
+	for i := 0; i < len(cidrs); i++ {
+		for j := i + 1; j < len(cidrs); j++ {
+			if cidrs[i].name != cidrs[j].name && overlap(cidrs[i].network, cidrs[j].network) {
+				problems = append(problems, fmt.Sprintf("%s CIDR %s overlaps with %s CIDR %s", cidrs[i].name, cidrs[i].network, cidrs[j].name, cidrs[j].network))
+			}
+		}
+	}
+
+	return problems
+}
+
+func overlap(a, b *net.IPNet) bool {
+	return a.Contains(b.IP) || b.Contains(a.IP)
+}
+
+func (manifest *Manifest) validateMachines() []string {
+	problems := []string{}
+
+	for _, obj := range manifest.Objects {
+		var owner string
+		var ref corev1.ObjectReference
+		switch machines := obj.(type) {
+		case *capiControlplaneKubeadmv1beta.KubeadmControlPlane:
+			owner, ref = fmt.Sprintf("KubeadmControlPlane %s", machines.Name), machines.Spec.MachineTemplate.InfrastructureRef
+		case *clusterv1.MachineDeployment:
+			owner, ref = fmt.Sprintf("MachineDeployment %s", machines.Name), machines.Spec.Template.Spec.InfrastructureRef
+		default:
+			continue
+		}
+
+		template := manifest.Find(ref.Kind, ref.Name)
+		if template == nil {
+			problems = append(problems, fmt.Sprintf("%s refers to %s %s which is not in the manifest", owner, ref.Kind, ref.Name))
+			continue
+		}
+		if problem := validateMachineType(template); problem != "" {
+			problems = append(problems, fmt.Sprintf("%s %s of %s %s", Kind(template), template.GetName(), owner, problem))
+		}
+	}
+
+	return problems
+}
+
+// validateMachineType returns the problem with the machine type of the infrastructure machine template, if any.
+// Machine templates of other providers, like Docker, have no machine type
+func validateMachineType(template interface{}) string {
+	switch template := template.(type) {
+	case *capav1beta1.AWSMachineTemplate:
+		if template.Spec.Template.Spec.InstanceType == "" {
+			return "has no instance type"
+		}
+	case *capzv1beta1.AzureMachineTemplate:
+		if template.Spec.Template.Spec.VMSize == "" {
+			return "has no VM size"
+		}
+	case *capvv1beta1.VSphereMachineTemplate:
+		spec := template.Spec.Template.Spec
+		if spec.NumCPUs <= 0 || spec.MemoryMiB <= 0 || spec.DiskGiB <= 0 {
+			return fmt.Sprintf("has %d CPUs, %d MiB memory and %d GiB disk, expected all to be positive", spec.NumCPUs, spec.MemoryMiB, spec.DiskGiB)
+		}
+	}
+	return ""
+}
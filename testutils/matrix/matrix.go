@@ -0,0 +1,298 @@
+package matrix
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/karuppiah7890/tce-e2e-test/testutils/log"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/packagetest"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/report"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/scenario"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/tce"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/utils"
+)
+
+// tceBuildType is the build type of TCE installed for the TCE versions of the matrix
+const tceBuildType = "release"
+
+// Matrix has the values of each dimension of the test matrix. Every combination of the values is a provider
+// test run. An empty dimension other than Providers has a single default value - the provider's default plan,
+// the installed TCE version or no package test
+type Matrix struct {
+	Providers   []string
+	Plans       []string
+	TCEVersions []string
+	Packages    []tce.Package
+}
+
+// Combination is one combination of the values of the matrix
+type Combination struct {
+	Provider   string
+	Plan       string
+	TCEVersion string
+	Package    tce.Package
+}
+
+// Name returns a name for the combination made of its values, like aws-prod-v0.12.1-velero
+func (combination Combination) Name() string {
+	parts := []string{}
+	for _, part := range []string{combination.Provider, combination.Plan, combination.TCEVersion, combination.Package.Name} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+// Expand returns all the combinations of the matrix, grouped by TCE version
+func (matrix Matrix) Expand() []Combination {
+	tceVersions := orDefault(matrix.TCEVersions)
+	plans := orDefault(matrix.Plans)
+	packages := matrix.Packages
+	if len(packages) == 0 {
+		packages = []tce.Package{{}}
+	}
+
+	combinations := []Combination{}
+	for _, tceVersion := range tceVersions {
+		for _, provider := range matrix.Providers {
+			for _, plan := range plans {
+				for _, packageDetails := range packages {
+					combinations = append(combinations, Combination{
+						Provider:   provider,
+						Plan:       plan,
+						TCEVersion: tceVersion,
+						Package:    packageDetails,
+					})
+				}
+			}
+		}
+	}
+	return combinations
+}
+
+func (matrix Matrix) hasPackages() bool {
+	for _, packageDetails := range matrix.Packages {
+		if packageDetails.Name != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func orDefault(values []string) []string {
+	if len(values) == 0 {
+		return []string{""}
+	}
+	return values
+}
+
+// RunFunc runs a provider test, like utils.RunProviderTestWithOptions
+type RunFunc func(ctx context.Context, provider utils.Provider, r utils.ClusterTestRunner, packageDetails tce.Package, options utils.RunOptions) error
+
+// Options holds the options of a matrix run
+type Options struct {
+	// Concurrency is the maximum number of combinations of a TCE version run at the same time. It's 1 when not set
+	Concurrency int
+	// NewProvider returns a new provider with the name for each combination, as providers hold the state of
+	// a test run. It's utils.LookupProvider when not set
+	NewProvider func(name string) (utils.Provider, error)
+	// InstallTCE installs a TCE version of the matrix before its combinations are run. It's tce.Install when not set
+	InstallTCE func(version string) error
+	// ClonePackageTests gets the package tests before the combinations with a package are run. It's
+	// packagetest.CloneTests when not set
+	ClonePackageTests func() error
+	// AfterPackageTest runs after the provider test run of each combination with a package. It's
+	// packagetest.AfterTest when not set
+	AfterPackageTest func(packageDetails tce.Package)
+	// RunTest runs the provider test of each combination. It's utils.RunProviderTestWithOptions when not set
+	RunTest RunFunc
+	// RunOptions are the options of the provider test runs. The TCE version is set for each combination
+	RunOptions utils.RunOptions
+}
+
+// Result is the result of the provider test run of a combination
+type Result struct {
+	Combination Combination
+	Err         error
+	Duration    time.Duration
+	LogFile     string
+	ReportFile  string
+}
+
+// Outcome returns the outcome of the combination's test run
+func (result Result) Outcome() report.Outcome {
+	if result.Err != nil {
+		return report.OutcomeFailed
+	}
+	return report.OutcomePassed
+}
+
+// Run runs the provider test of every combination of the matrix and returns their results in the order of the
+// combinations. As the tanzu CLI can have only one TCE version installed, the combinations of each TCE version
+// are run together, and the TCE versions one after the other. When upgrading TCE, the combinations are run one
+// after the other, each after installing its TCE version again. Each test run has its own cluster names, log
+// file, report and home directory
+func Run(ctx context.Context, matrix Matrix, options Options) []Result {
+	if options.Concurrency <= 0 {
+		options.Concurrency = 1
+	}
+	if options.NewProvider == nil {
+		options.NewProvider = utils.LookupProvider
+	}
+	if options.InstallTCE == nil {
+		options.InstallTCE = func(version string) error {
+			return tce.Install(version, tceBuildType)
+		}
+	}
+	if options.ClonePackageTests == nil {
+		options.ClonePackageTests = packagetest.CloneTests
+	}
+	if options.AfterPackageTest == nil {
+		options.AfterPackageTest = packagetest.AfterTest
+	}
+	if options.RunTest == nil {
+		options.RunTest = utils.RunProviderTestWithOptions
+	}
+
+	combinations := matrix.Expand()
+
+	// Upgrading TCE installs the version to upgrade to over the TCE version of the combination, so each
+	// combination reinstalls its TCE version and runs on its own. Without a TCE version, the installed TCE
+	// version can't be reinstalled after the first upgrade
+	upgrading := options.RunOptions.Lifecycle.UpgradeTCEVersion != ""
+	if upgrading && len(combinations) > 1 && combinations[0].TCEVersion == "" {
+		return notRun(combinations, fmt.Errorf("upgrading %d combinations to TCE version %s is not supported without the TCE versions to upgrade from, as the TCE version installed before the first upgrade can't be installed again", len(combinations), options.RunOptions.Lifecycle.UpgradeTCEVersion))
+	}
+
+	results := make([]Result, len(combinations))
+	clusterNameSuffix := time.Now().Unix()
+
+	// The package tests are cloned once for all the combinations with a package
+	var clonePackageTestsErr error
+	if matrix.hasPackages() {
+		clonePackageTestsErr = options.ClonePackageTests()
+		if clonePackageTestsErr != nil {
+			clonePackageTestsErr = fmt.Errorf("error while getting the package tests: %v", clonePackageTestsErr)
+		}
+	}
+
+	for start := 0; start < len(combinations); {
+		tceVersion := combinations[start].TCEVersion
+		end := start + 1
+		for !upgrading && end < len(combinations) && combinations[end].TCEVersion == tceVersion {
+			end++
+		}
+
+		var installErr error
+		if tceVersion != "" {
+			log.Infof("Installing TCE version %s", tceVersion)
+			installErr = options.InstallTCE(tceVersion)
+			if installErr != nil {
+				installErr = fmt.Errorf("error while installing TCE version %s: %v", tceVersion, installErr)
+			}
+		}
+
+		semaphore := make(chan struct{}, options.Concurrency)
+		var waitGroup sync.WaitGroup
+		for index := start; index < end; index++ {
+			if installErr != nil {
+				results[index] = Result{Combination: combinations[index], Err: installErr}
+				continue
+			}
+			if clonePackageTestsErr != nil && combinations[index].Package.Name != "" {
+				results[index] = Result{Combination: combinations[index], Err: clonePackageTestsErr}
+				continue
+			}
+
+			semaphore <- struct{}{}
+			waitGroup.Add(1)
+			go func(index int) {
+				defer waitGroup.Done()
+				defer func() { <-semaphore }()
+
+				results[index] = runCombination(ctx, combinations[index], fmt.Sprintf("%d-%d", clusterNameSuffix, index), options)
+			}(index)
+		}
+		waitGroup.Wait()
+
+		start = end
+	}
+
+	return results
+}
+
+// notRun returns the results of the combinations which were not run due to the error
+func notRun(combinations []Combination, err error) []Result {
+	results := make([]Result, len(combinations))
+	for index, combination := range combinations {
+		results[index] = Result{Combination: combination, Err: err}
+	}
+	return results
+}
+
+func runCombination(ctx context.Context, combination Combination, clusterNameSuffix string, options Options) (result Result) {
+	result.Combination = combination
+	startTime := time.Now()
+	defer func() {
+		result.Duration = time.Since(startTime)
+	}()
+
+	if err := ctx.Err(); err != nil {
+		result.Err = fmt.Errorf("not run: %v", err)
+		return result
+	}
+
+	provider, err := options.NewProvider(combination.Provider)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	logger, err := log.NewLogger(fmt.Sprintf("%s-e2e", combination.Name()))
+	if err != nil {
+		result.Err = fmt.Errorf("error while creating logger: %v", err)
+		return result
+	}
+	defer logger.Close()
+	result.LogFile = logger.FilePath()
+	result.ReportFile = report.FilePathForLogFile(result.LogFile)
+
+	var testScenario *scenario.Scenario
+	if combination.Plan != "" {
+		testScenario = &scenario.Scenario{
+			Name:     combination.Name(),
+			Provider: combination.Provider,
+			Plan:     combination.Plan,
+		}
+	}
+
+	// Each test run has its own home directory, so that test runs run at the same time don't share the
+	// kubeconfig and the tanzu CLI config
+	homeDir, err := utils.NewHomeDir(result.LogFile)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	r := utils.DefaultClusterTestRunner{
+		Logger:            logger,
+		Scenario:          testScenario,
+		ClusterNameSuffix: clusterNameSuffix,
+		HomeDir:           homeDir,
+	}
+
+	runOptions := options.RunOptions
+	if combination.TCEVersion != "" {
+		runOptions.TCEVersion = combination.TCEVersion
+	}
+
+	result.Err = options.RunTest(ctx, provider, r, combination.Package, runOptions)
+	if combination.Package.Name != "" {
+		options.AfterPackageTest(combination.Package)
+	}
+	return result
+}
@@ -0,0 +1,334 @@
+package matrix_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/matrix"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/tce"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/utils"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/utils/mock_utils"
+)
+
+func TestMatrixExpand(t *testing.T) {
+	t.Run("it should return every combination grouped by TCE version", func(t *testing.T) {
+		testMatrix := matrix.Matrix{
+			Providers:   []string{"aws", "azure"},
+			Plans:       []string{"dev", "prod"},
+			TCEVersions: []string{"v0.11.0", "v0.12.1"},
+			Packages:    []tce.Package{{Name: "velero", Version: "1.8.0"}},
+		}
+
+		combinations := testMatrix.Expand()
+		if len(combinations) != 8 {
+			t.Fatalf("expected 8 combinations but got %d: %v", len(combinations), combinations)
+		}
+		if combinations[0].Name() != "aws-dev-v0.11.0-velero" || combinations[7].Name() != "azure-prod-v0.12.1-velero" {
+			t.Errorf("expected combinations in order but got first %s and last %s", combinations[0].Name(), combinations[7].Name())
+		}
+	})
+
+	t.Run("when only providers are given it should return one combination for each provider", func(t *testing.T) {
+		combinations := matrix.Matrix{Providers: []string{"docker"}}.Expand()
+		if len(combinations) != 1 || combinations[0].Name() != "docker" {
+			t.Errorf("expected one docker combination but got %v", combinations)
+		}
+	})
+}
+
+const kubeConfigWithContext = `apiVersion: v1
+kind: Config
+clusters:
+- cluster:
+    server: https://127.0.0.1:6443
+  name: test-mgmt
+contexts:
+- context:
+    cluster: test-mgmt
+    user: test-mgmt-admin
+  name: test-mgmt-admin@test-mgmt
+current-context: test-mgmt-admin@test-mgmt
+users:
+- name: test-mgmt-admin
+  user:
+    token: some-token
+`
+
+func TestMatrixRun(t *testing.T) {
+	t.Run("it should run the combinations within the concurrency limit with their own cluster names", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+
+		defer ctrl.Finish()
+
+		testMatrix := matrix.Matrix{
+			Providers:   []string{"aws", "azure", "vsphere"},
+			Plans:       []string{"dev", "prod"},
+			TCEVersions: []string{"v0.11.0", "v0.12.1"},
+		}
+
+		var mutex sync.Mutex
+		running, maxRunning := 0, 0
+		clusterNames := map[string]bool{}
+
+		results := matrix.Run(context.Background(), testMatrix, matrix.Options{
+			Concurrency: 2,
+			NewProvider: func(name string) (utils.Provider, error) {
+				return mock_utils.NewMockProvider(ctrl), nil
+			},
+			InstallTCE: func(version string) error {
+				if version == "v0.11.0" {
+					return fmt.Errorf("some error in TCE installation")
+				}
+				return nil
+			},
+			RunTest: func(ctx context.Context, provider utils.Provider, r utils.ClusterTestRunner, packageDetails tce.Package, options utils.RunOptions) error {
+				managementClusterName, _ := r.GetRandomClusterNames()
+
+				mutex.Lock()
+				running++
+				if running > maxRunning {
+					maxRunning = running
+				}
+				clusterNames[managementClusterName] = true
+				mutex.Unlock()
+
+				time.Sleep(10 * time.Millisecond)
+
+				mutex.Lock()
+				running--
+				mutex.Unlock()
+
+				if options.TCEVersion != "v0.12.1" {
+					return fmt.Errorf("expected TCE version v0.12.1 but got %s", options.TCEVersion)
+				}
+				return nil
+			},
+		})
+
+		for _, result := range results {
+			if result.LogFile != "" {
+				defer os.RemoveAll(filepath.Dir(result.LogFile))
+			}
+		}
+
+		if len(results) != 12 {
+			t.Fatalf("expected 12 results but got %d", len(results))
+		}
+		for _, result := range results[:6] {
+			if result.Err == nil || !strings.Contains(result.Err.Error(), "some error in TCE installation") {
+				t.Errorf("expected %s to fail with TCE installation error but got: %v", result.Combination.Name(), result.Err)
+			}
+		}
+		for _, result := range results[6:] {
+			if result.Err != nil {
+				t.Errorf("expected %s to pass but got: %v", result.Combination.Name(), result.Err)
+			}
+		}
+		if maxRunning != 2 {
+			t.Errorf("expected at most 2 combinations to run at the same time but got %d", maxRunning)
+		}
+		if len(clusterNames) != 6 {
+			t.Errorf("expected each combination to have its own cluster names but got %v", clusterNames)
+		}
+
+		var summary bytes.Buffer
+		err := matrix.WriteSummary(&summary, results)
+		if err != nil {
+			t.Fatalf("expected no error while writing summary but got: %v", err)
+		}
+		if !strings.Contains(summary.String(), "6 of 12 combinations passed") || !strings.Contains(summary.String(), "vsphere-prod-v0.12.1") {
+			t.Errorf("expected summary of the results but got:\n%s", summary.String())
+		}
+	})
+
+	t.Run("it should run each combination with its own home directory with a copy of the kubeconfig", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+
+		defer ctrl.Finish()
+
+		userHomeDir := t.TempDir()
+		t.Setenv("HOME", userHomeDir)
+		t.Setenv("KUBECONFIG", "")
+		t.Setenv("TANZU_CONFIG", filepath.Join(userHomeDir, "tanzu-config.yaml"))
+		err := os.MkdirAll(filepath.Join(userHomeDir, ".kube"), 0700)
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		err = os.WriteFile(filepath.Join(userHomeDir, ".kube", "config"), []byte(kubeConfigWithContext), 0600)
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+
+		var mutex sync.Mutex
+		homeDirs := map[string]bool{}
+		results := matrix.Run(context.Background(), matrix.Matrix{Providers: []string{"aws", "azure"}}, matrix.Options{
+			Concurrency: 2,
+			NewProvider: func(name string) (utils.Provider, error) {
+				return mock_utils.NewMockProvider(ctrl), nil
+			},
+			RunTest: func(ctx context.Context, provider utils.Provider, r utils.ClusterTestRunner, packageDetails tce.Package, options utils.RunOptions) error {
+				kubeConfigPath, err := r.GetKubeConfigPath()
+				if err != nil {
+					return err
+				}
+				kubeConfig, err := os.ReadFile(kubeConfigPath)
+				if err != nil {
+					return err
+				}
+				if !strings.Contains(string(kubeConfig), "test-mgmt-admin@test-mgmt") {
+					return fmt.Errorf("expected a copy of the kubeconfig but got:\n%s", kubeConfig)
+				}
+
+				mutex.Lock()
+				defer mutex.Unlock()
+				homeDirs[filepath.Dir(filepath.Dir(kubeConfigPath))] = true
+				return nil
+			},
+		})
+
+		for _, result := range results {
+			if result.LogFile != "" {
+				defer os.RemoveAll(filepath.Dir(result.LogFile))
+			}
+			if result.Err != nil {
+				t.Errorf("expected %s to pass but got: %v", result.Combination.Name(), result.Err)
+			}
+		}
+		if len(homeDirs) != 2 || homeDirs[userHomeDir] {
+			t.Errorf("expected each combination to have its own home directory but got %v", homeDirs)
+		}
+	})
+
+	t.Run("when upgrading TCE it should install the TCE version of each combination again before running it", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+
+		defer ctrl.Finish()
+
+		steps := []string{}
+		results := matrix.Run(context.Background(), matrix.Matrix{
+			Providers:   []string{"aws", "azure"},
+			TCEVersions: []string{"v0.11.0"},
+		}, matrix.Options{
+			Concurrency: 2,
+			NewProvider: func(name string) (utils.Provider, error) {
+				return mock_utils.NewMockProvider(ctrl), nil
+			},
+			InstallTCE: func(version string) error {
+				steps = append(steps, "install "+version)
+				return nil
+			},
+			RunTest: func(ctx context.Context, provider utils.Provider, r utils.ClusterTestRunner, packageDetails tce.Package, options utils.RunOptions) error {
+				steps = append(steps, "upgrade to "+options.Lifecycle.UpgradeTCEVersion)
+				return nil
+			},
+			RunOptions: utils.RunOptions{
+				Lifecycle: utils.LifecycleOptions{UpgradeTCEVersion: "v0.12.1"},
+			},
+		})
+
+		for _, result := range results {
+			if result.LogFile != "" {
+				defer os.RemoveAll(filepath.Dir(result.LogFile))
+			}
+			if result.Err != nil {
+				t.Errorf("expected %s to pass but got: %v", result.Combination.Name(), result.Err)
+			}
+		}
+		expectedSteps := "install v0.11.0, upgrade to v0.12.1, install v0.11.0, upgrade to v0.12.1"
+		if strings.Join(steps, ", ") != expectedSteps {
+			t.Errorf("expected steps to be: %v. But got: %v", expectedSteps, strings.Join(steps, ", "))
+		}
+	})
+
+	t.Run("when upgrading TCE without the TCE versions to upgrade from it should not run more than one combination", func(t *testing.T) {
+		results := matrix.Run(context.Background(), matrix.Matrix{Providers: []string{"aws", "azure"}}, matrix.Options{
+			RunTest: func(ctx context.Context, provider utils.Provider, r utils.ClusterTestRunner, packageDetails tce.Package, options utils.RunOptions) error {
+				t.Errorf("expected no provider test run but got one")
+				return nil
+			},
+			RunOptions: utils.RunOptions{
+				Lifecycle: utils.LifecycleOptions{UpgradeTCEVersion: "v0.12.1"},
+			},
+		})
+
+		if len(results) != 2 {
+			t.Fatalf("expected 2 results but got %d", len(results))
+		}
+		expectedError := "upgrading 2 combinations to TCE version v0.12.1 is not supported without the TCE versions to upgrade from"
+		for _, result := range results {
+			if result.Err == nil || !strings.Contains(result.Err.Error(), expectedError) {
+				t.Errorf("expected %s to fail with error containing: %v. But got: %v", result.Combination.Name(), expectedError, result.Err)
+			}
+		}
+	})
+
+	t.Run("when there are packages it should get the package tests once and run the steps after each package test", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+
+		defer ctrl.Finish()
+
+		clones := 0
+		afterPackageTests := []string{}
+		results := matrix.Run(context.Background(), matrix.Matrix{
+			Providers: []string{"aws", "azure"},
+			Packages:  []tce.Package{{Name: "velero", Version: "1.8.0"}},
+		}, matrix.Options{
+			NewProvider: func(name string) (utils.Provider, error) {
+				return mock_utils.NewMockProvider(ctrl), nil
+			},
+			ClonePackageTests: func() error {
+				clones++
+				return nil
+			},
+			AfterPackageTest: func(packageDetails tce.Package) {
+				afterPackageTests = append(afterPackageTests, packageDetails.Name)
+			},
+			RunTest: func(ctx context.Context, provider utils.Provider, r utils.ClusterTestRunner, packageDetails tce.Package, options utils.RunOptions) error {
+				return nil
+			},
+		})
+
+		for _, result := range results {
+			if result.LogFile != "" {
+				defer os.RemoveAll(filepath.Dir(result.LogFile))
+			}
+			if result.Err != nil {
+				t.Errorf("expected %s to pass but got: %v", result.Combination.Name(), result.Err)
+			}
+		}
+		if clones != 1 {
+			t.Errorf("expected the package tests to be cloned once but got %d", clones)
+		}
+		if len(afterPackageTests) != 2 || afterPackageTests[0] != "velero" || afterPackageTests[1] != "velero" {
+			t.Errorf("expected the steps after the package test to run for both combinations but got: %v", afterPackageTests)
+		}
+	})
+
+	t.Run("when the package tests can't be got it should fail the combinations with a package", func(t *testing.T) {
+		results := matrix.Run(context.Background(), matrix.Matrix{
+			Providers: []string{"aws"},
+			Packages:  []tce.Package{{Name: "velero", Version: "1.8.0"}},
+		}, matrix.Options{
+			ClonePackageTests: func() error {
+				return fmt.Errorf("some error in cloning")
+			},
+			RunTest: func(ctx context.Context, provider utils.Provider, r utils.ClusterTestRunner, packageDetails tce.Package, options utils.RunOptions) error {
+				t.Errorf("expected no test run but got one for %s", packageDetails.Name)
+				return nil
+			},
+		})
+
+		expectedError := "error while getting the package tests: some error in cloning"
+		if len(results) != 1 || results[0].Err == nil || results[0].Err.Error() != expectedError {
+			t.Errorf("expected one result with error: %v. But got: %+v", expectedError, results)
+		}
+	})
+}
@@ -0,0 +1,57 @@
+package matrix
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// WriteSummary writes a summary table of the results, with one row for each combination, followed by the
+// errors of the failed combinations
+func WriteSummary(w io.Writer, results []Result) error {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "NAME\tPROVIDER\tPLAN\tTCE VERSION\tPACKAGE\tOUTCOME\tDURATION\tREPORT")
+
+	passed := 0
+	for _, result := range results {
+		combination := result.Combination
+		if result.Err == nil {
+			passed++
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			combination.Name(),
+			combination.Provider,
+			orDash(combination.Plan),
+			orDash(combination.TCEVersion),
+			orDash(combination.Package.Name),
+			result.Outcome(),
+			result.Duration.Round(time.Second),
+			orDash(result.ReportFile),
+		)
+	}
+	err := table.Flush()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "\n%d of %d combinations passed\n", passed, len(results))
+
+	for _, result := range results {
+		if result.Err != nil {
+			// Only the first line of the error is shown, the full error is in the report
+			message := strings.SplitN(result.Err.Error(), "\n", 2)[0]
+			fmt.Fprintf(w, "%s: %s\n", result.Combination.Name(), message)
+		}
+	}
+
+	return nil
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
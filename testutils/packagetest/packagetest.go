@@ -0,0 +1,40 @@
+package packagetest
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/karuppiah7890/tce-e2e-test/testutils/aws"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/github"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/log"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/tce"
+)
+
+// communityEditionRepoURL is the repository with the package tests which tce.PackageE2Etest runs
+const communityEditionRepoURL = "https://github.com/vmware-tanzu/community-edition"
+
+// communityEditionDirectory is the directory in the working directory which the repository is cloned into
+const communityEditionDirectory = "community-edition"
+
+// CloneTests clones the community-edition repository with the package tests into the working directory, unless
+// it's cloned already. It's run before the provider test runs with a package test
+func CloneTests() error {
+	if _, err := os.Stat(communityEditionDirectory); err == nil {
+		log.Infof("Using the package tests in the existing %s directory", communityEditionDirectory)
+		return nil
+	}
+
+	err := github.CloneRepo(communityEditionRepoURL)
+	if err != nil {
+		return fmt.Errorf("error while cloning %s: %v", communityEditionRepoURL, err)
+	}
+	return nil
+}
+
+// AfterTest runs the steps needed after the provider test run of the package, like emptying the S3 bucket which
+// the velero package test backs up to
+func AfterTest(packageDetails tce.Package) {
+	if packageDetails.Name == "velero" {
+		aws.EmptyS3Bucket()
+	}
+}
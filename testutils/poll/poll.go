@@ -0,0 +1,122 @@
+package poll
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	defaultInterval = 10 * time.Second
+	defaultTimeout  = 10 * time.Minute
+)
+
+// ErrTimeout is returned, wrapped, by Until when the condition is not done before the timeout
+var ErrTimeout = errors.New("timed out")
+
+// Condition checks whether what is being waited for is done. It returns nil when it's done, and otherwise an
+// error saying why it's not done yet, like a cluster not being ready. Polling goes on after such an error, unless
+// it's wrapped using Stop
+type Condition func(ctx context.Context) error
+
+// Attempt is a check of the condition which found it not done yet
+type Attempt struct {
+	// Number is the number of the attempt, starting from 1
+	Number int
+	// Elapsed is the time elapsed since polling started
+	Elapsed time.Duration
+	// Err is the error returned by the condition, saying why it's not done yet
+	Err error
+}
+
+// Options are the options of polling. The zero value polls every 10 seconds for 10 minutes
+type Options struct {
+	// Interval is the time to wait after the first check of the condition which is not done
+	Interval time.Duration
+	// Timeout is the maximum time to poll for
+	Timeout time.Duration
+	// Backoff is the factor the interval is multiplied by after each check which is not done. Values less than
+	// or equal to 1 keep the interval constant
+	Backoff float64
+	// MaxInterval caps the interval as it grows due to the backoff. It is not capped when zero
+	MaxInterval time.Duration
+	// Progress, when set, is called after each check of the condition which is not done, for example to log
+	// what is being waited for
+	Progress func(attempt Attempt)
+}
+
+type stopError struct {
+	err error
+}
+
+func (e *stopError) Error() string {
+	return e.err.Error()
+}
+
+func (e *stopError) Unwrap() error {
+	return e.err
+}
+
+// Stop wraps the error of a condition to stop polling, for errors which waiting won't fix
+func Stop(err error) error {
+	return &stopError{err: err}
+}
+
+// Until checks the condition right away, and then at every interval, until it's done, the condition stops the
+// polling with an error wrapped using Stop, the timeout is reached or the context is done. The condition is given a
+// context which is done on timeout, so that a slow check doesn't run past the timeout. On timeout, it returns an
+// error wrapping ErrTimeout along with the last error of the condition
+func Until(ctx context.Context, options Options, condition Condition) error {
+	interval := options.Interval
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+	timeout := options.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	start := time.Now()
+	pollCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for attempt := 1; ; attempt++ {
+		err := condition(pollCtx)
+		if err == nil {
+			return nil
+		}
+		var stop *stopError
+		if errors.As(err, &stop) {
+			return stop.err
+		}
+
+		if options.Progress != nil {
+			options.Progress(Attempt{Number: attempt, Elapsed: time.Since(start), Err: err})
+		}
+
+		wait := time.NewTimer(interval)
+		select {
+		case <-wait.C:
+		case <-pollCtx.Done():
+			wait.Stop()
+			if ctx.Err() != nil {
+				return fmt.Errorf("stopped polling after %d attempts: %w. last error: %v", attempt, ctx.Err(), err)
+			}
+			return fmt.Errorf("%w after %v and %d attempts: %v", ErrTimeout, timeout, attempt, err)
+		}
+
+		interval = nextInterval(interval, options)
+	}
+}
+
+func nextInterval(interval time.Duration, options Options) time.Duration {
+	if options.Backoff <= 1 {
+		return interval
+	}
+	next := time.Duration(float64(interval) * options.Backoff)
+	if options.MaxInterval > 0 && next > options.MaxInterval {
+		return options.MaxInterval
+	}
+	return next
+}
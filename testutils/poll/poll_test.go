@@ -0,0 +1,101 @@
+package poll_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/karuppiah7890/tce-e2e-test/testutils/poll"
+)
+
+func TestUntil(t *testing.T) {
+	t.Run("when the condition gets done it should return no error and report the progress of the attempts before", func(t *testing.T) {
+		checks := 0
+		attempts := []poll.Attempt{}
+		options := poll.Options{
+			Interval: time.Millisecond,
+			Timeout:  time.Second,
+			Progress: func(attempt poll.Attempt) {
+				attempts = append(attempts, attempt)
+			},
+		}
+
+		err := poll.Until(context.Background(), options, func(ctx context.Context) error {
+			checks++
+			if checks < 3 {
+				return fmt.Errorf("not done at check %d", checks)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		if len(attempts) != 2 || attempts[1].Number != 2 || attempts[1].Err.Error() != "not done at check 2" {
+			t.Errorf("expected progress of 2 attempts but got: %+v", attempts)
+		}
+	})
+
+	t.Run("when the condition is never done it should time out with the last error", func(t *testing.T) {
+		options := poll.Options{Interval: time.Millisecond, Timeout: 20 * time.Millisecond, Backoff: 2, MaxInterval: 5 * time.Millisecond}
+
+		err := poll.Until(context.Background(), options, func(ctx context.Context) error {
+			return errors.New("cluster is not ready")
+		})
+		if !errors.Is(err, poll.ErrTimeout) {
+			t.Fatalf("expected a timeout error but got: %v", err)
+		}
+		if expected := "cluster is not ready"; !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected error to contain %q but got: %v", expected, err)
+		}
+	})
+
+	t.Run("when a check of the condition runs past the timeout it should cancel it and time out", func(t *testing.T) {
+		options := poll.Options{Interval: time.Millisecond, Timeout: 20 * time.Millisecond}
+
+		err := poll.Until(context.Background(), options, func(ctx context.Context) error {
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("cluster is not ready: %v", ctx.Err())
+			case <-time.After(10 * time.Second):
+				return nil
+			}
+		})
+		if !errors.Is(err, poll.ErrTimeout) {
+			t.Fatalf("expected a timeout error but got: %v", err)
+		}
+		if expected := "cluster is not ready: context deadline exceeded"; !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected error to contain %q but got: %v", expected, err)
+		}
+	})
+
+	t.Run("when the condition stops the polling it should return the error right away", func(t *testing.T) {
+		checks := 0
+		stopErr := errors.New("cluster is deleting")
+
+		err := poll.Until(context.Background(), poll.Options{Interval: time.Millisecond, Timeout: time.Second}, func(ctx context.Context) error {
+			checks++
+			return poll.Stop(stopErr)
+		})
+		if err != stopErr {
+			t.Errorf("expected the stop error but got: %v", err)
+		}
+		if checks != 1 {
+			t.Errorf("expected 1 check but got %d", checks)
+		}
+	})
+
+	t.Run("when the context is cancelled it should stop polling", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		err := poll.Until(ctx, poll.Options{Interval: time.Hour, Timeout: 2 * time.Hour}, func(ctx context.Context) error {
+			cancel()
+			return errors.New("not done")
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected a context cancelled error but got: %v", err)
+		}
+	})
+}
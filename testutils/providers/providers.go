@@ -0,0 +1,10 @@
+// Package providers registers all the providers in the provider registry of the utils package. Import it
+// for its side effects to look up any of the providers by name using utils.LookupProvider
+package providers
+
+import (
+	_ "github.com/karuppiah7890/tce-e2e-test/testutils/aws"
+	_ "github.com/karuppiah7890/tce-e2e-test/testutils/azure"
+	_ "github.com/karuppiah7890/tce-e2e-test/testutils/dockerprovider"
+	_ "github.com/karuppiah7890/tce-e2e-test/testutils/vsphere"
+)
@@ -0,0 +1,123 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// junitTestSuites is the root element of a JUnit XML report
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Output  string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// WriteJUnit writes the report as JUnit XML, with one test case for each phase of the test run
+func (report *Report) WriteJUnit(w io.Writer) error {
+	report.mutex.Lock()
+	defer report.mutex.Unlock()
+
+	suiteName := fmt.Sprintf("%s-e2e", report.Provider)
+	suite := junitTestSuite{
+		Name:      suiteName,
+		Tests:     len(report.Phases),
+		Time:      junitSeconds(report.EndTime.Sub(report.StartTime)),
+		Timestamp: report.StartTime.UTC().Format("2006-01-02T15:04:05"),
+		TestCases: []junitTestCase{},
+	}
+
+	for _, phase := range report.Phases {
+		testCase := junitTestCase{
+			Name:      phase.Name,
+			ClassName: suiteName,
+			Time:      junitSeconds(phase.Duration),
+			SystemOut: strings.Join(phase.OutputTail, "\n"),
+		}
+
+		switch phase.Outcome {
+		case OutcomeFailed:
+			suite.Failures++
+			testCase.Failure = &junitFailure{
+				Message: phase.Error,
+				Type:    "PhaseFailure",
+				Output:  phase.Error,
+			}
+		case OutcomeSkipped:
+			suite.Skipped++
+			testCase.Skipped = &junitSkipped{Message: phase.Error}
+		}
+
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return fmt.Errorf("error while writing JUnit XML header: %v", err)
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	err = encoder.Encode(junitTestSuites{Suites: []junitTestSuite{suite}})
+	if err != nil {
+		return fmt.Errorf("error while encoding report as JUnit XML: %v", err)
+	}
+
+	return nil
+}
+
+// WriteJUnitFile writes the report as JUnit XML to the file at the given path
+func (report *Report) WriteJUnitFile(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error while creating JUnit report file at '%s': %v", path, err)
+	}
+	defer file.Close()
+
+	err = report.WriteJUnit(file)
+	if err != nil {
+		return err
+	}
+
+	err = file.Close()
+	if err != nil {
+		return fmt.Errorf("error while closing JUnit report file at '%s': %v", path, err)
+	}
+
+	return nil
+}
+
+func junitSeconds(duration time.Duration) string {
+	return fmt.Sprintf("%.3f", duration.Seconds())
+}
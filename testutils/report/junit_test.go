@@ -0,0 +1,94 @@
+package report_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"testing"
+
+	"github.com/karuppiah7890/tce-e2e-test/testutils/report"
+)
+
+type fakeOutputRecorder struct {
+	lines []string
+}
+
+func (recorder *fakeOutputRecorder) Mark() uint64 {
+	return uint64(len(recorder.lines))
+}
+
+func (recorder *fakeOutputRecorder) LinesSince(mark uint64) []string {
+	return recorder.lines[mark:]
+}
+
+func TestWriteJUnit(t *testing.T) {
+	t.Run("it should write a test case for each phase with its outcome and output", func(t *testing.T) {
+		recorder := &fakeOutputRecorder{}
+		runReport := report.New("docker")
+		runReport.SetOutputRecorder(recorder)
+
+		_ = runReport.RunPhase(report.PhaseManagementClusterCreate, func() error {
+			recorder.lines = append(recorder.lines, "management cluster created")
+			return nil
+		})
+		_ = runReport.RunPhase(report.PhaseWorkloadClusterCreate, func() error {
+			recorder.lines = append(recorder.lines, "timed out waiting for workload cluster")
+			return fmt.Errorf("some error in workload cluster creation")
+		})
+		runReport.SkipPhase(report.PhasePackageTest, "no package to test")
+		runReport.Finish()
+
+		var junitReport bytes.Buffer
+		err := runReport.WriteJUnit(&junitReport)
+		if err != nil {
+			t.Fatalf("expected no error while writing JUnit report but got: %v", err)
+		}
+
+		var suites struct {
+			Suites []struct {
+				Name      string `xml:"name,attr"`
+				Tests     int    `xml:"tests,attr"`
+				Failures  int    `xml:"failures,attr"`
+				Skipped   int    `xml:"skipped,attr"`
+				TestCases []struct {
+					Name    string `xml:"name,attr"`
+					Failure *struct {
+						Message string `xml:"message,attr"`
+					} `xml:"failure"`
+					Skipped *struct {
+						Message string `xml:"message,attr"`
+					} `xml:"skipped"`
+					SystemOut string `xml:"system-out"`
+				} `xml:"testcase"`
+			} `xml:"testsuite"`
+		}
+		err = xml.Unmarshal(junitReport.Bytes(), &suites)
+		if err != nil {
+			t.Fatalf("expected valid JUnit XML but got error: %v\n%s", err, junitReport.String())
+		}
+
+		if len(suites.Suites) != 1 {
+			t.Fatalf("expected 1 test suite but got %d", len(suites.Suites))
+		}
+		suite := suites.Suites[0]
+		if suite.Name != "docker-e2e" || suite.Tests != 3 || suite.Failures != 1 || suite.Skipped != 1 {
+			t.Errorf("expected test suite docker-e2e with 3 tests, 1 failure and 1 skipped but got %+v", suite)
+		}
+		if len(suite.TestCases) != 3 {
+			t.Fatalf("expected 3 test cases but got %d", len(suite.TestCases))
+		}
+
+		failed := suite.TestCases[1]
+		if failed.Name != report.PhaseWorkloadClusterCreate || failed.Failure == nil || failed.Failure.Message != "some error in workload cluster creation" {
+			t.Errorf("expected failure of the workload cluster creation but got %+v", failed)
+		}
+		if failed.SystemOut != "timed out waiting for workload cluster" {
+			t.Errorf("expected only the output of the phase in the test case but got %q", failed.SystemOut)
+		}
+
+		skipped := suite.TestCases[2]
+		if skipped.Skipped == nil || skipped.Skipped.Message != "no package to test" {
+			t.Errorf("expected the package test to be skipped with the reason but got %+v", skipped)
+		}
+	})
+}
@@ -0,0 +1,303 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Outcome is the outcome of a phase or of the whole test run
+type Outcome string
+
+const (
+	OutcomePassed  Outcome = "passed"
+	OutcomeFailed  Outcome = "failed"
+	OutcomeSkipped Outcome = "skipped"
+)
+
+// Names of the phases of a provider test run
+const (
+	PhaseChecks                  = "checks"
+	PhaseManagementClusterCreate = "management-cluster-create"
+	// PhaseManagementClusterCheck replaces PhaseManagementClusterCreate when an existing management cluster is used
+	PhaseManagementClusterCheck  = "management-cluster-check"
+	PhaseWorkloadClusterCreate   = "workload-cluster-create"
+	PhasePackageTest             = "package-test"
+	PhaseWorkloadClusterDelete   = "workload-cluster-delete"
+	PhaseManagementClusterDelete = "management-cluster-delete"
+	PhaseCleanup                 = "cleanup"
+	// Phases of the lifecycle operations on the clusters, like scaling and upgrading them, which run after the package test
+	PhaseWorkloadClusterScale              = "workload-cluster-scale"
+	PhaseWorkloadClusterMachineHealthCheck = "workload-cluster-machine-health-check"
+	PhaseUpgradeTestWorkloadDeploy         = "upgrade-test-workload-deploy"
+	PhaseTCEUpgrade                        = "tce-upgrade"
+	PhaseManagementClusterUpgrade          = "management-cluster-upgrade"
+	PhaseWorkloadClusterUpgrade            = "workload-cluster-upgrade"
+	PhaseUpgradeCheck                      = "upgrade-check"
+	// Phases of a dry run, which only renders and validates the manifests of the clusters
+	PhaseManagementClusterDryRun = "management-cluster-dry-run"
+	PhaseWorkloadClusterDryRun   = "workload-cluster-dry-run"
+)
+
+// Phase holds the details of a phase of a test run
+type Phase struct {
+	Name      string        `json:"name"`
+	StartTime time.Time     `json:"startTime"`
+	EndTime   time.Time     `json:"endTime"`
+	Duration  time.Duration `json:"duration"`
+	Outcome   Outcome       `json:"outcome"`
+	Error     string        `json:"error,omitempty"`
+	// OutputTail holds the last lines logged during the phase
+	OutputTail []string `json:"outputTail,omitempty"`
+}
+
+// phaseOutputTailLines is the number of last lines logged during a phase that are kept in the phase
+const phaseOutputTailLines = 50
+
+// OutputRecorder gives the lines logged during a phase. It's implemented by *log.Logger
+type OutputRecorder interface {
+	// Mark returns a mark of the current point in the logs
+	Mark() uint64
+	// LinesSince returns the lines logged since the mark was taken
+	LinesSince(mark uint64) []string
+}
+
+// Report is a machine-readable report of a provider test run. It's safe for concurrent use
+type Report struct {
+	mutex          sync.Mutex
+	outputRecorder OutputRecorder
+
+	Provider              string    `json:"provider"`
+	ManagementClusterName string    `json:"managementClusterName,omitempty"`
+	WorkloadClusterName   string    `json:"workloadClusterName,omitempty"`
+	TCEVersion            string    `json:"tceVersion,omitempty"`
+	TFVersion             string    `json:"tfVersion,omitempty"`
+	StartTime             time.Time `json:"startTime"`
+	EndTime               time.Time `json:"endTime"`
+	Outcome               Outcome   `json:"outcome"`
+	Phases                []*Phase  `json:"phases"`
+	DiagnosticsBundles    []string  `json:"diagnosticsBundles,omitempty"`
+	ClusterConfigFiles    []string  `json:"clusterConfigFiles,omitempty"`
+	ClusterManifestFiles  []string  `json:"clusterManifestFiles,omitempty"`
+	StateFile             string    `json:"stateFile,omitempty"`
+	LogFile               string    `json:"logFile,omitempty"`
+}
+
+// New creates a report for a test run on the provider, starting now
+func New(provider string) *Report {
+	return &Report{
+		Provider:  provider,
+		StartTime: time.Now(),
+		Phases:    []*Phase{},
+	}
+}
+
+// SetOutputRecorder sets the recorder used to get the output logged during each phase
+func (report *Report) SetOutputRecorder(outputRecorder OutputRecorder) {
+	report.mutex.Lock()
+	defer report.mutex.Unlock()
+
+	report.outputRecorder = outputRecorder
+}
+
+// SetClusterNames records the names of the management cluster and the workload cluster
+func (report *Report) SetClusterNames(managementClusterName, workloadClusterName string) {
+	report.mutex.Lock()
+	defer report.mutex.Unlock()
+
+	report.ManagementClusterName = managementClusterName
+	report.WorkloadClusterName = workloadClusterName
+}
+
+// SetStateFile records the path of the state file of the test run, which can be used to resume the test run
+// or to tear down the resources it created
+func (report *Report) SetStateFile(path string) {
+	report.mutex.Lock()
+	defer report.mutex.Unlock()
+
+	report.StateFile = path
+}
+
+// SetVersions records the versions of TCE and TF (Tanzu Framework) being tested
+func (report *Report) SetVersions(tceVersion, tfVersion string) {
+	report.mutex.Lock()
+	defer report.mutex.Unlock()
+
+	report.TCEVersion = tceVersion
+	report.TFVersion = tfVersion
+}
+
+// AddDiagnosticsBundles records the paths of diagnostics bundles collected during the test run
+func (report *Report) AddDiagnosticsBundles(paths ...string) {
+	report.mutex.Lock()
+	defer report.mutex.Unlock()
+
+	for _, path := range paths {
+		if !contains(report.DiagnosticsBundles, path) {
+			report.DiagnosticsBundles = append(report.DiagnosticsBundles, path)
+		}
+	}
+}
+
+// AddClusterConfigFiles records the paths of the cluster config files the clusters were created with
+func (report *Report) AddClusterConfigFiles(paths ...string) {
+	report.mutex.Lock()
+	defer report.mutex.Unlock()
+
+	for _, path := range paths {
+		if !contains(report.ClusterConfigFiles, path) {
+			report.ClusterConfigFiles = append(report.ClusterConfigFiles, path)
+		}
+	}
+}
+
+// AddClusterManifestFiles records the paths of the cluster manifests rendered in dry run mode
+func (report *Report) AddClusterManifestFiles(paths ...string) {
+	report.mutex.Lock()
+	defer report.mutex.Unlock()
+
+	for _, path := range paths {
+		if !contains(report.ClusterManifestFiles, path) {
+			report.ClusterManifestFiles = append(report.ClusterManifestFiles, path)
+		}
+	}
+}
+
+// RunPhase runs the phase and records its timing and outcome. It returns the phase's error
+func (report *Report) RunPhase(name string, run func() error) error {
+	phase := &Phase{
+		Name:      name,
+		StartTime: time.Now(),
+	}
+
+	report.mutex.Lock()
+	outputRecorder := report.outputRecorder
+	report.mutex.Unlock()
+
+	var outputMark uint64
+	if outputRecorder != nil {
+		outputMark = outputRecorder.Mark()
+	}
+
+	err := run()
+
+	phase.EndTime = time.Now()
+	if outputRecorder != nil {
+		phase.OutputTail = lastLines(outputRecorder.LinesSince(outputMark), phaseOutputTailLines)
+	}
+	phase.Duration = phase.EndTime.Sub(phase.StartTime)
+	phase.Outcome = OutcomePassed
+	if err != nil {
+		phase.Outcome = OutcomeFailed
+		phase.Error = err.Error()
+	}
+
+	report.addPhase(phase)
+
+	return err
+}
+
+// SkipPhase records that the phase was skipped along with the reason
+func (report *Report) SkipPhase(name string, reason string) {
+	now := time.Now()
+	report.addPhase(&Phase{
+		Name:      name,
+		StartTime: now,
+		EndTime:   now,
+		Outcome:   OutcomeSkipped,
+		Error:     reason,
+	})
+}
+
+func (report *Report) addPhase(phase *Phase) {
+	report.mutex.Lock()
+	defer report.mutex.Unlock()
+
+	report.Phases = append(report.Phases, phase)
+}
+
+// Finish marks the end of the test run. The test run fails if any of its phases failed
+func (report *Report) Finish() {
+	report.mutex.Lock()
+	defer report.mutex.Unlock()
+
+	report.EndTime = time.Now()
+	report.Outcome = OutcomePassed
+	for _, phase := range report.Phases {
+		if phase.Outcome == OutcomeFailed {
+			report.Outcome = OutcomeFailed
+		}
+	}
+}
+
+// FailedPhases returns the phases that failed
+func (report *Report) FailedPhases() []*Phase {
+	report.mutex.Lock()
+	defer report.mutex.Unlock()
+
+	failedPhases := []*Phase{}
+	for _, phase := range report.Phases {
+		if phase.Outcome == OutcomeFailed {
+			failedPhases = append(failedPhases, phase)
+		}
+	}
+	return failedPhases
+}
+
+// WriteFile writes the report as JSON to the file at the given path
+func (report *Report) WriteFile(path string) error {
+	report.mutex.Lock()
+	defer report.mutex.Unlock()
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error while encoding report as JSON: %v", err)
+	}
+
+	err = os.WriteFile(path, data, 0644)
+	if err != nil {
+		return fmt.Errorf("error while writing report to '%s': %v", path, err)
+	}
+
+	return nil
+}
+
+// ReadFile reads a report written as JSON by WriteFile
+func ReadFile(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error while reading report from '%s': %v", path, err)
+	}
+
+	report := &Report{}
+	err = json.Unmarshal(data, report)
+	if err != nil {
+		return nil, fmt.Errorf("error while decoding report from '%s': %v", path, err)
+	}
+
+	return report, nil
+}
+
+// FilePathForLogFile returns the path of the report file to be written next to the log file
+func FilePathForLogFile(logFilePath string) string {
+	return strings.TrimSuffix(logFilePath, ".log") + ".report.json"
+}
+
+func lastLines(lines []string, count int) []string {
+	if len(lines) > count {
+		return lines[len(lines)-count:]
+	}
+	return lines
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package report_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/karuppiah7890/tce-e2e-test/testutils/report"
+)
+
+func TestReport(t *testing.T) {
+	t.Run("it should record the phases and be written as JSON", func(t *testing.T) {
+		runReport := report.New("docker")
+		runReport.SetClusterNames("test-mgmt", "test-wkld")
+		runReport.SetVersions("v0.12.1", "v0.11.6")
+
+		err := runReport.RunPhase(report.PhaseManagementClusterCreate, func() error { return nil })
+		if err != nil {
+			t.Fatalf("expected no error from passing phase but got: %v", err)
+		}
+		err = runReport.RunPhase(report.PhaseWorkloadClusterCreate, func() error {
+			return fmt.Errorf("some error in workload cluster creation")
+		})
+		if err == nil {
+			t.Fatalf("expected error from failing phase")
+		}
+		runReport.SkipPhase(report.PhasePackageTest, "no package to test")
+		runReport.AddDiagnosticsBundles("/tmp/management-cluster.test-mgmt.diagnostics.tar.gz")
+		runReport.Finish()
+
+		reportFilePath := filepath.Join(t.TempDir(), "docker-mgmt-wkld-e2e.report.json")
+		err = runReport.WriteFile(reportFilePath)
+		if err != nil {
+			t.Fatalf("expected no error while writing report but got: %v", err)
+		}
+
+		writtenReport, err := report.ReadFile(reportFilePath)
+		if err != nil {
+			t.Fatalf("expected no error while reading report but got: %v", err)
+		}
+
+		if writtenReport.Outcome != report.OutcomeFailed {
+			t.Errorf("expected test run to have failed but got outcome %v", writtenReport.Outcome)
+		}
+		if writtenReport.ManagementClusterName != "test-mgmt" || writtenReport.TFVersion != "v0.11.6" || len(writtenReport.DiagnosticsBundles) != 1 {
+			t.Errorf("expected the details of the test run in the report but got %+v", writtenReport)
+		}
+
+		expectedOutcomes := []report.Outcome{report.OutcomePassed, report.OutcomeFailed, report.OutcomeSkipped}
+		if len(writtenReport.Phases) != len(expectedOutcomes) {
+			t.Fatalf("expected %d phases but got %d", len(expectedOutcomes), len(writtenReport.Phases))
+		}
+		for i, phase := range writtenReport.Phases {
+			if phase.Outcome != expectedOutcomes[i] {
+				t.Errorf("expected phase %s to have outcome %v but got %v", phase.Name, expectedOutcomes[i], phase.Outcome)
+			}
+		}
+		if writtenReport.Phases[1].Error != "some error in workload cluster creation" {
+			t.Errorf("expected error of the failed phase in the report but got %q", writtenReport.Phases[1].Error)
+		}
+	})
+
+	t.Run("it should be written next to the log file", func(t *testing.T) {
+		reportFilePath := report.FilePathForLogFile("/logs/aws-mgmt-wkld-e2e-2022-06-01-10-00-00.log")
+		if reportFilePath != "/logs/aws-mgmt-wkld-e2e-2022-06-01-10-00-00.report.json" {
+			t.Errorf("expected report file next to the log file but got %s", reportFilePath)
+		}
+	})
+}
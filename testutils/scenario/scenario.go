@@ -0,0 +1,241 @@
+package scenario
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/karuppiah7890/tce-e2e-test/testutils/tanzu"
+)
+
+// FileEnvVarName is the environment variable with the path of the scenarios file to use
+const FileEnvVarName = "SCENARIO_FILE"
+
+// NameEnvVarName is the environment variable with the name of the scenario to run from the scenarios file
+const NameEnvVarName = "SCENARIO"
+
+// Plans supported by the tanzu CLI
+const (
+	PlanDev  = "dev"
+	PlanProd = "prod"
+)
+
+// ClusterRole is the role of a cluster in a test run, used to override the config of only one of the clusters
+type ClusterRole string
+
+const (
+	ManagementCluster ClusterRole = "management"
+	WorkloadCluster   ClusterRole = "workload"
+)
+
+// Config variables set by the test run itself, which can't be overridden by a scenario
+var reservedConfigVariables = []string{"CLUSTER_NAME", "INFRASTRUCTURE_PROVIDER"}
+
+var configVariableNamePattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+
+// OS is the OS of the cluster nodes
+type OS struct {
+	Name    string `yaml:"name"`
+	Version string `yaml:"version"`
+	Arch    string `yaml:"arch"`
+}
+
+// Scenario is a named test scenario - the provider to test along with the cluster plan, node OS, Kubernetes
+// version and any other tanzu config variables to use instead of the provider's defaults
+type Scenario struct {
+	Name     string `yaml:"name"`
+	Provider string `yaml:"provider"`
+	// Plan is the cluster plan, dev or prod
+	Plan              string `yaml:"plan"`
+	OS                OS     `yaml:"os"`
+	KubernetesVersion string `yaml:"kubernetesVersion"`
+	// Config has tanzu config variables for both the clusters, like AWS_REGION
+	Config map[string]string `yaml:"config"`
+	// Clusters has tanzu config variables for only the management or the workload cluster, which take
+	// precedence over Config
+	Clusters map[ClusterRole]map[string]string `yaml:"clusters"`
+}
+
+// File is a scenarios file
+type File struct {
+	Scenarios []Scenario `yaml:"scenarios"`
+}
+
+// Parse parses and validates a scenarios file. Unknown fields are errors, so that typos don't go unnoticed
+func Parse(data []byte) (*File, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	file := &File{}
+	err := decoder.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("error while parsing scenarios: %v", err)
+	}
+
+	err = file.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// ReadFile reads, parses and validates the scenarios file at the path
+func ReadFile(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error while reading scenarios file %s: %v", path, err)
+	}
+
+	file, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("error in scenarios file %s: %v", path, err)
+	}
+
+	return file, nil
+}
+
+// Validate validates all the scenarios in the file and returns an error with all the problems found
+func (file *File) Validate() error {
+	problems := []string{}
+
+	if len(file.Scenarios) == 0 {
+		problems = append(problems, "no scenarios found")
+	}
+
+	names := map[string]bool{}
+	for _, scenario := range file.Scenarios {
+		if names[scenario.Name] {
+			problems = append(problems, fmt.Sprintf("more than one scenario is named %s", scenario.Name))
+		}
+		names[scenario.Name] = true
+
+		err := scenario.Validate()
+		if err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+
+	if len(problems) != 0 {
+		return fmt.Errorf("invalid scenarios: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Get returns the scenario with the name
+func (file *File) Get(name string) (*Scenario, error) {
+	for i := range file.Scenarios {
+		if file.Scenarios[i].Name == name {
+			return &file.Scenarios[i], nil
+		}
+	}
+	return nil, fmt.Errorf("scenario %s not found", name)
+}
+
+// Validate validates the scenario and its config variables against the config schema
+func (scenario Scenario) Validate() error {
+	problems := []string{}
+
+	if scenario.Name == "" {
+		problems = append(problems, "name is required")
+	}
+	if scenario.Provider == "" {
+		problems = append(problems, "provider is required")
+	}
+
+	for role := range scenario.Clusters {
+		if role != ManagementCluster && role != WorkloadCluster {
+			problems = append(problems, fmt.Sprintf("unknown cluster %s, expected %s or %s", role, ManagementCluster, WorkloadCluster))
+		}
+	}
+
+	// The config common to both the clusters is validated once, and then the overrides of each cluster
+	problems = append(problems, validateScenarioConfig("", scenario.commonTanzuConfig())...)
+	for _, role := range []ClusterRole{ManagementCluster, WorkloadCluster} {
+		if config, ok := scenario.Clusters[role]; ok {
+			problems = append(problems, validateScenarioConfig(fmt.Sprintf("%s cluster: ", role), config)...)
+		}
+	}
+
+	if len(problems) != 0 {
+		return fmt.Errorf("scenario %s: %s", scenario.Name, strings.Join(problems, ", "))
+	}
+	return nil
+}
+
+func validateScenarioConfig(prefix string, config map[string]string) []string {
+	problems := []string{}
+
+	for _, name := range reservedConfigVariables {
+		if _, ok := config[name]; ok {
+			problems = append(problems, fmt.Sprintf("%s%s is set by the test run and can't be set in a scenario", prefix, name))
+		}
+	}
+
+	err := ValidateTanzuConfig(config)
+	if err != nil {
+		problems = append(problems, fmt.Sprintf("%s%v", prefix, err))
+	}
+
+	return problems
+}
+
+// TanzuConfig returns the tanzu config variables set by the scenario for the cluster with the role
+func (scenario Scenario) TanzuConfig(role ClusterRole) tanzu.TanzuConfig {
+	config := scenario.commonTanzuConfig()
+	for name, value := range scenario.Clusters[role] {
+		config[name] = value
+	}
+	return config
+}
+
+// commonTanzuConfig returns the tanzu config variables set by the scenario for both the clusters
+func (scenario Scenario) commonTanzuConfig() tanzu.TanzuConfig {
+	config := tanzu.TanzuConfig{}
+
+	setIfNotEmpty := func(name, value string) {
+		if value != "" {
+			config[name] = value
+		}
+	}
+	setIfNotEmpty("CLUSTER_PLAN", scenario.Plan)
+	setIfNotEmpty("OS_NAME", scenario.OS.Name)
+	setIfNotEmpty("OS_VERSION", scenario.OS.Version)
+	setIfNotEmpty("OS_ARCH", scenario.OS.Arch)
+	setIfNotEmpty("KUBERNETES_VERSION", scenario.KubernetesVersion)
+
+	for name, value := range scenario.Config {
+		config[name] = value
+	}
+
+	return config
+}
+
+// FromEnv returns the scenario named by the SCENARIO environment variable from the scenarios file at the path
+// in the SCENARIO_FILE environment variable. When SCENARIO is not set, the file must have only one scenario.
+// It returns nil when SCENARIO_FILE is not set
+func FromEnv() (*Scenario, error) {
+	path := os.Getenv(FileEnvVarName)
+	if path == "" {
+		return nil, nil
+	}
+
+	file, err := ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	name := os.Getenv(NameEnvVarName)
+	if name == "" {
+		if len(file.Scenarios) != 1 {
+			return nil, fmt.Errorf("scenarios file %s has %d scenarios, set %s environment variable to choose one", path, len(file.Scenarios), NameEnvVarName)
+		}
+		return &file.Scenarios[0], nil
+	}
+
+	return file.Get(name)
+}
@@ -0,0 +1,138 @@
+package scenario_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/karuppiah7890/tce-e2e-test/testutils/scenario"
+)
+
+func TestReadFile(t *testing.T) {
+	t.Run("when the scenarios are valid it should return the config of each cluster", func(t *testing.T) {
+		file, err := scenario.ReadFile("testdata/scenarios.yaml")
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+
+		awsScenario, err := file.Get("aws-us-west-2-prod")
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+
+		managementClusterConfig := awsScenario.TanzuConfig(scenario.ManagementCluster)
+		expectedManagementClusterConfig := map[string]string{
+			"CLUSTER_PLAN":               "prod",
+			"OS_NAME":                    "ubuntu",
+			"OS_VERSION":                 "20.04",
+			"OS_ARCH":                    "amd64",
+			"KUBERNETES_VERSION":         "v1.22.8",
+			"AWS_REGION":                 "us-west-2",
+			"AWS_NODE_AZ":                "us-west-2a",
+			"CONTROL_PLANE_MACHINE_TYPE": "m5.2xlarge",
+		}
+		if len(managementClusterConfig) != len(expectedManagementClusterConfig) {
+			t.Errorf("expected management cluster config to be %v but got %v", expectedManagementClusterConfig, managementClusterConfig)
+		}
+		for name, value := range expectedManagementClusterConfig {
+			if managementClusterConfig[name] != value {
+				t.Errorf("expected %s to be %s in management cluster config but got %s", name, value, managementClusterConfig[name])
+			}
+		}
+
+		workloadClusterConfig := awsScenario.TanzuConfig(scenario.WorkloadCluster)
+		if workloadClusterConfig["NODE_MACHINE_TYPE"] != "t3.large" || workloadClusterConfig["CONTROL_PLANE_MACHINE_TYPE"] != "" {
+			t.Errorf("expected only the workload cluster overrides in workload cluster config but got %v", workloadClusterConfig)
+		}
+
+		_, err = file.Get("some-unknown-scenario")
+		if err == nil {
+			t.Errorf("expected error for unknown scenario")
+		}
+	})
+}
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		name          string
+		scenarios     string
+		expectedError string
+	}{
+		{
+			name: "when a field is unknown it should return an error",
+			scenarios: `
+scenarios:
+  - name: aws-dev
+    provider: aws
+    plann: dev
+`,
+			expectedError: "field plann not found",
+		},
+		{
+			name: "when the plan is not supported it should return an error",
+			scenarios: `
+scenarios:
+  - name: aws-dev
+    provider: aws
+    plan: large
+`,
+			expectedError: `CLUSTER_PLAN must be dev or prod, got "large"`,
+		},
+		{
+			name: "when the config has invalid values it should return all the errors",
+			scenarios: `
+scenarios:
+  - name: aws-dev
+    config:
+      CLUSTER_NAME: my-cluster
+      AWS_VPC_CIDR: 10.0.0.0
+      ENABLE_MHC: "yes"
+`,
+			expectedError: `scenario aws-dev: provider is required, CLUSTER_NAME is set by the test run and can't be set in a scenario, ` +
+				`invalid config: AWS_VPC_CIDR must be a CIDR, got "10.0.0.0", ENABLE_MHC must be true or false, got "yes"`,
+		},
+		{
+			name: "when a cluster override is invalid it should return an error with the cluster",
+			scenarios: `
+scenarios:
+  - name: vsphere-dev
+    provider: vsphere
+    clusters:
+      workload:
+        VSPHERE_WORKER_NUM_CPUS: "0"
+`,
+			expectedError: `scenario vsphere-dev: workload cluster: invalid config: VSPHERE_WORKER_NUM_CPUS must be a positive number, got "0"`,
+		},
+		{
+			name: "when an availability zone is not in the region it should return an error",
+			scenarios: `
+scenarios:
+  - name: aws-us-west-2
+    provider: aws
+    config:
+      AWS_REGION: us-west-2
+      AWS_NODE_AZ: us-east-1a
+`,
+			expectedError: `scenario aws-us-west-2: invalid config: AWS_NODE_AZ us-east-1a is not in AWS_REGION us-west-2`,
+		},
+		{
+			name: "when two scenarios have the same name it should return an error",
+			scenarios: `
+scenarios:
+  - name: docker-dev
+    provider: docker
+  - name: docker-dev
+    provider: docker
+`,
+			expectedError: "more than one scenario is named docker-dev",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := scenario.Parse([]byte(testCase.scenarios))
+			if err == nil || !strings.Contains(err.Error(), testCase.expectedError) {
+				t.Errorf("expected error to contain: %v. But got: %v", testCase.expectedError, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package scenario
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/karuppiah7890/tce-e2e-test/testutils/tanzu"
+)
+
+// ValidateTanzuConfig validates the tanzu config variables against the schema of the values the tanzu CLI
+// accepts for them, so that mistakes are found before any cluster is created. Variables with no known
+// schema are only checked for a valid name
+func ValidateTanzuConfig(config tanzu.TanzuConfig) error {
+	names := make([]string, 0, len(config))
+	for name := range config {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	problems := []string{}
+	for _, name := range names {
+		err := validateConfigVariable(name, config[name])
+		if err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	problems = append(problems, awsAvailabilityZoneProblems(config)...)
+
+	if len(problems) != 0 {
+		return fmt.Errorf("invalid config: %s", strings.Join(problems, ", "))
+	}
+	return nil
+}
+
+func validateConfigVariable(name, value string) error {
+	if !configVariableNamePattern.MatchString(name) {
+		return fmt.Errorf("%s is not a valid config variable name", name)
+	}
+
+	switch {
+	case name == "CLUSTER_PLAN":
+		if value != PlanDev && value != PlanProd {
+			return fmt.Errorf("%s must be %s or %s, got %q", name, PlanDev, PlanProd, value)
+		}
+	case name == "OS_ARCH":
+		if value != "" && value != "amd64" && value != "arm64" {
+			return fmt.Errorf("%s must be amd64 or arm64, got %q", name, value)
+		}
+	case name == "KUBERNETES_VERSION":
+		if !strings.HasPrefix(value, "v") {
+			return fmt.Errorf("%s must be a version like v1.22.8, got %q", name, value)
+		}
+	case strings.HasSuffix(name, "_CIDR"):
+		if _, _, err := net.ParseCIDR(value); err != nil {
+			return fmt.Errorf("%s must be a CIDR, got %q", name, value)
+		}
+	case strings.HasPrefix(name, "ENABLE_"), strings.HasSuffix(name, "_ENABLED"), name == "VSPHERE_INSECURE":
+		if value != "true" && value != "false" {
+			return fmt.Errorf("%s must be true or false, got %q", name, value)
+		}
+	case strings.HasSuffix(name, "_MACHINE_TYPE"):
+		if value == "" {
+			return fmt.Errorf("%s must not be empty", name)
+		}
+	case strings.HasSuffix(name, "_DISK_GIB"), strings.HasSuffix(name, "_MEM_MIB"), strings.HasSuffix(name, "_NUM_CPUS"):
+		if number, err := strconv.Atoi(value); err != nil || number <= 0 {
+			return fmt.Errorf("%s must be a positive number, got %q", name, value)
+		}
+	}
+
+	return nil
+}
+
+// AWS availability zone variables, which must be in the AWS_REGION of the config
+var awsAvailabilityZoneVariables = []string{"AWS_NODE_AZ", "AWS_NODE_AZ_1", "AWS_NODE_AZ_2"}
+
+// awsAvailabilityZoneProblems returns the AWS availability zones of the config which are not in its AWS_REGION,
+// like when the AWS_REGION environment variable with the AWS credentials overrides the region of a scenario
+func awsAvailabilityZoneProblems(config tanzu.TanzuConfig) []string {
+	region := config["AWS_REGION"]
+	if region == "" {
+		return nil
+	}
+
+	problems := []string{}
+	for _, name := range awsAvailabilityZoneVariables {
+		zone := config[name]
+		if zone != "" && !strings.HasPrefix(zone, region) {
+			problems = append(problems, fmt.Sprintf("%s %s is not in AWS_REGION %s", name, zone, region))
+		}
+	}
+	return problems
+}
@@ -0,0 +1,223 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/karuppiah7890/tce-e2e-test/testutils/cleanup"
+)
+
+// Resource is a resource created during a test run
+type Resource struct {
+	cleanup.Resource
+	// Phase is the phase of the test run during which the resource was created
+	Phase     string    `json:"phase,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	Deleted   bool      `json:"deleted"`
+}
+
+// State is the state of a provider test run - the names of its clusters, the phases it completed and the
+// resources it created, like clusters, kube contexts and cloud resources, along with whether they are deleted.
+// It's written to a file on every change, so that a test run which dies partway through can be resumed, or
+// the resources it created can be torn down. It's safe for concurrent use, and implements cleanup.Tracker
+type State struct {
+	mutex sync.Mutex
+	path  string
+
+	Provider              string      `json:"provider"`
+	ManagementClusterName string      `json:"managementClusterName"`
+	WorkloadClusterName   string      `json:"workloadClusterName"`
+	CurrentPhase          string      `json:"currentPhase,omitempty"`
+	CompletedPhases       []string    `json:"completedPhases"`
+	Resources             []*Resource `json:"resources"`
+	UpdatedAt             time.Time   `json:"updatedAt"`
+}
+
+// New creates the state of a test run on the provider and writes it to the file at the path. When the path
+// is empty, the state is not written to any file
+func New(path, provider, managementClusterName, workloadClusterName string) (*State, error) {
+	state := &State{
+		path:                  path,
+		Provider:              provider,
+		ManagementClusterName: managementClusterName,
+		WorkloadClusterName:   workloadClusterName,
+		CompletedPhases:       []string{},
+		Resources:             []*Resource{},
+	}
+
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+
+	err := state.save()
+	if err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// ReadFile reads the state written to the file at the path. Any change to the state is written back to the file
+func ReadFile(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error while reading state from '%s': %v", path, err)
+	}
+
+	state := &State{}
+	err = json.Unmarshal(data, state)
+	if err != nil {
+		return nil, fmt.Errorf("error while decoding state from '%s': %v", path, err)
+	}
+	state.path = path
+
+	return state, nil
+}
+
+// FilePathForLogFile returns the path of the state file to be written next to the log file
+func FilePathForLogFile(logFilePath string) string {
+	return strings.TrimSuffix(logFilePath, ".log") + ".state.json"
+}
+
+// Path returns the path of the file the state is written to
+func (state *State) Path() string {
+	return state.path
+}
+
+// StartPhase records the phase as the current phase, which the resources created from now on are created in
+func (state *State) StartPhase(name string) error {
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+
+	state.CurrentPhase = name
+	return state.save()
+}
+
+// CompletePhase records the phase as completed
+func (state *State) CompletePhase(name string) error {
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+
+	if !state.isPhaseCompleted(name) {
+		state.CompletedPhases = append(state.CompletedPhases, name)
+	}
+	state.CurrentPhase = ""
+	return state.save()
+}
+
+// IsPhaseCompleted returns true when the phase is recorded as completed
+func (state *State) IsPhaseCompleted(name string) bool {
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+
+	return state.isPhaseCompleted(name)
+}
+
+func (state *State) isPhaseCompleted(name string) bool {
+	for _, phase := range state.CompletedPhases {
+		if phase == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Created records the resource as created in the current phase. A resource which is already recorded and not
+// deleted, like one created by the test run being resumed, keeps its phase
+func (state *State) Created(resource cleanup.Resource) error {
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+
+	existing := state.find(resource)
+	if existing != nil && !existing.Deleted {
+		return nil
+	}
+	if existing != nil {
+		existing.Phase = state.CurrentPhase
+		existing.CreatedAt = time.Now()
+		existing.Deleted = false
+		return state.save()
+	}
+
+	state.Resources = append(state.Resources, &Resource{
+		Resource:  resource,
+		Phase:     state.CurrentPhase,
+		CreatedAt: time.Now(),
+	})
+	return state.save()
+}
+
+// Deleted records the resource as deleted
+func (state *State) Deleted(resource cleanup.Resource) error {
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+
+	existing := state.find(resource)
+	if existing == nil || existing.Deleted {
+		return nil
+	}
+	existing.Deleted = true
+	return state.save()
+}
+
+// RemainingResources returns the resources which are not deleted, in the order of their creation
+func (state *State) RemainingResources() []cleanup.Resource {
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+
+	resources := []cleanup.Resource{}
+	for _, resource := range state.Resources {
+		if !resource.Deleted {
+			resources = append(resources, resource.Resource)
+		}
+	}
+	return resources
+}
+
+func (state *State) find(resource cleanup.Resource) *Resource {
+	for _, existing := range state.Resources {
+		if existing.Resource == resource {
+			return existing
+		}
+	}
+	return nil
+}
+
+// save writes the state to its file, through a temporary file which replaces it, so that the file is never left
+// half written when the process dies. It must be called with the mutex locked
+func (state *State) save() error {
+	if state.path == "" {
+		return nil
+	}
+	state.UpdatedAt = time.Now()
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error while encoding state: %v", err)
+	}
+
+	tempFile, err := os.CreateTemp(filepath.Dir(state.path), filepath.Base(state.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("error while creating temporary file to write state to '%s': %v", state.path, err)
+	}
+	defer os.Remove(tempFile.Name())
+
+	_, err = tempFile.Write(data)
+	closeErr := tempFile.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("error while writing state to '%s': %v", tempFile.Name(), err)
+	}
+
+	err = os.Rename(tempFile.Name(), state.path)
+	if err != nil {
+		return fmt.Errorf("error while writing state to '%s': %v", state.path, err)
+	}
+
+	return nil
+}
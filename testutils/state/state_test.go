@@ -0,0 +1,88 @@
+package state_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/karuppiah7890/tce-e2e-test/testutils/cleanup"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/state"
+)
+
+func TestState(t *testing.T) {
+	t.Run("when phases complete and resources are created and deleted it should write them to the state file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "aws-mgmt-wkld-e2e.state.json")
+		managementCluster := cleanup.Resource{Kind: "cluster", Name: "test-mgmt"}
+		workloadCluster := cleanup.Resource{Kind: "cluster", Name: "test-wkld"}
+		kubeContext := cleanup.Resource{Kind: "kube-context", Name: "test-wkld-admin@test-wkld"}
+
+		runState, err := state.New(path, "aws", "test-mgmt", "test-wkld")
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		for _, err := range []error{
+			runState.StartPhase("management-cluster-create"),
+			runState.Created(managementCluster),
+			runState.CompletePhase("management-cluster-create"),
+			runState.StartPhase("workload-cluster-create"),
+			runState.Created(workloadCluster),
+			runState.Created(kubeContext),
+			runState.Deleted(workloadCluster),
+		} {
+			if err != nil {
+				t.Fatalf("expected no error but got: %v", err)
+			}
+		}
+
+		readState, err := state.ReadFile(path)
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		if readState.Provider != "aws" || readState.ManagementClusterName != "test-mgmt" || readState.WorkloadClusterName != "test-wkld" {
+			t.Errorf("expected provider and cluster names to be read but got: %+v", readState)
+		}
+		if !readState.IsPhaseCompleted("management-cluster-create") || readState.IsPhaseCompleted("workload-cluster-create") {
+			t.Errorf("expected only the management-cluster-create phase to be completed but got: %v", readState.CompletedPhases)
+		}
+		if readState.CurrentPhase != "workload-cluster-create" || readState.Resources[0].Phase != "management-cluster-create" {
+			t.Errorf("expected the current phase and the phase of the resources to be read but got: %+v", readState)
+		}
+
+		expectedRemaining := []cleanup.Resource{managementCluster, kubeContext}
+		if fmt.Sprint(readState.RemainingResources()) != fmt.Sprint(expectedRemaining) {
+			t.Errorf("expected remaining resources %v but got %v", expectedRemaining, readState.RemainingResources())
+		}
+	})
+
+	t.Run("when a read state changes it should write the change back to the same file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "docker-mgmt-wkld-e2e.state.json")
+		runState, err := state.New(path, "docker", "test-mgmt", "test-wkld")
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+
+		readState, err := state.ReadFile(runState.Path())
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		err = readState.CompletePhase("checks")
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+
+		readAgainState, err := state.ReadFile(path)
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		if !readAgainState.IsPhaseCompleted("checks") {
+			t.Errorf("expected the checks phase to be completed but got: %v", readAgainState.CompletedPhases)
+		}
+	})
+
+	t.Run("when the state file does not exist it should return an error", func(t *testing.T) {
+		_, err := state.ReadFile(filepath.Join(t.TempDir(), "missing.state.json"))
+		if err == nil {
+			t.Errorf("expected an error but got none")
+		}
+	})
+}
@@ -0,0 +1,219 @@
+package tanzu
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/karuppiah7890/tce-e2e-test/testutils/clirunner"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/log"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// ManagementClusterRole is the role of the management cluster in the list of clusters
+const ManagementClusterRole = "management"
+
+// ClusterStatusRunning is the status of a cluster which is created and not being changed
+const ClusterStatusRunning = "running"
+
+// ReconcileSucceededStatus is the status of a package repository or an installed package which kapp-controller
+// reconciled successfully
+const ReconcileSucceededStatus = "Reconcile succeeded"
+
+// Cluster is a cluster in the output of `tanzu cluster list -o json`
+type Cluster struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	// Status is like running, creating, updating or deleting
+	Status string `json:"status"`
+	Plan   string `json:"plan"`
+	// ControlPlane is the count of ready control plane nodes out of the total, like 1/1
+	ControlPlane string `json:"controlplane"`
+	// Workers is the count of ready workers out of the total, like 1/2
+	Workers           string            `json:"workers"`
+	KubernetesVersion string            `json:"kubernetes"`
+	Roles             []string          `json:"roles"`
+	Labels            map[string]string `json:"labels"`
+}
+
+// HasRole tells if the cluster has the role, like ManagementClusterRole
+func (cluster Cluster) HasRole(role string) bool {
+	for _, clusterRole := range cluster.Roles {
+		if clusterRole == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Plugin is a plugin in the output of `tanzu plugin list -o json`. Some of the fields are empty depending on
+// whether the tanzu CLI uses context-aware plugin discovery or not
+type Plugin struct {
+	Name          string `json:"name"`
+	Description   string `json:"description"`
+	Scope         string `json:"scope"`
+	Discovery     string `json:"discovery"`
+	Repository    string `json:"repository"`
+	Version       string `json:"version"`
+	LatestVersion string `json:"latest_version"`
+	// Status is like installed, not installed or upgrade available
+	Status string `json:"status"`
+}
+
+// PackageRepository is a package repository in the output of `tanzu package repository list -o json`
+type PackageRepository struct {
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace"`
+	Repository string `json:"repository"`
+	Tag        string `json:"tag"`
+	// Status is like ReconcileSucceededStatus
+	Status string `json:"status"`
+	// Details has the error of the package repository when it failed to reconcile
+	Details string `json:"details"`
+}
+
+// InstalledPackage is a package in the output of `tanzu package installed list -o json`
+type InstalledPackage struct {
+	Name           string `json:"name"`
+	Namespace      string `json:"namespace"`
+	PackageName    string `json:"package-name"`
+	PackageVersion string `json:"package-version"`
+	// Status is like ReconcileSucceededStatus
+	Status string `json:"status"`
+}
+
+// Client runs tanzu CLI commands which list and get things, and parses their JSON output
+type Client struct {
+	// Logger is used for the logs of the commands run. If Logger is nil, the global logger is used
+	Logger *log.Logger
+	// Env is the environment of the tanzu CLI. If Env is nil, the environment of the process is used
+	Env []string
+	// Run runs the tanzu CLI with the arguments and returns its standard output. When Run is nil, the tanzu CLI
+	// is run using clirunner. Tests set it to return recorded output of the tanzu CLI
+	Run func(ctx context.Context, args ...string) ([]byte, error)
+}
+
+// ListClusters returns the workload clusters of the current management cluster
+// Runs `tanzu cluster list -o json`
+func (c Client) ListClusters(ctx context.Context) ([]Cluster, error) {
+	clusters := []Cluster{}
+	err := c.runJSON(ctx, &clusters, "cluster", "list", "-o", "json")
+	if err != nil {
+		return nil, fmt.Errorf("error occurred while listing clusters: %v", err)
+	}
+	return clusters, nil
+}
+
+// ListManagementClusters returns the current management cluster, which is the only management cluster listed
+// by the tanzu CLI
+// Runs `tanzu cluster list --include-management-cluster -o json`
+func (c Client) ListManagementClusters(ctx context.Context) ([]Cluster, error) {
+	clusters := []Cluster{}
+	err := c.runJSON(ctx, &clusters, "cluster", "list", "--include-management-cluster", "-o", "json")
+	if err != nil {
+		return nil, fmt.Errorf("error occurred while listing management clusters: %v", err)
+	}
+
+	managementClusters := []Cluster{}
+	for _, cluster := range clusters {
+		if cluster.HasRole(ManagementClusterRole) {
+			managementClusters = append(managementClusters, cluster)
+		}
+	}
+	return managementClusters, nil
+}
+
+// ListPlugins returns the plugins of the tanzu CLI
+// Runs `tanzu plugin list -o json`
+func (c Client) ListPlugins(ctx context.Context) ([]Plugin, error) {
+	plugins := []Plugin{}
+	err := c.runJSON(ctx, &plugins, "plugin", "list", "-o", "json")
+	if err != nil {
+		return nil, fmt.Errorf("error occurred while listing plugins: %v", err)
+	}
+	return plugins, nil
+}
+
+// ListPackageRepositories returns the package repositories of the current cluster in all the namespaces
+// Runs `tanzu package repository list --all-namespaces -o json`
+func (c Client) ListPackageRepositories(ctx context.Context) ([]PackageRepository, error) {
+	packageRepositories := []PackageRepository{}
+	err := c.runJSON(ctx, &packageRepositories, "package", "repository", "list", "--all-namespaces", "-o", "json")
+	if err != nil {
+		return nil, fmt.Errorf("error occurred while listing package repositories: %v", err)
+	}
+	return packageRepositories, nil
+}
+
+// ListInstalledPackages returns the installed packages of the current cluster in all the namespaces
+// Runs `tanzu package installed list --all-namespaces -o json`
+func (c Client) ListInstalledPackages(ctx context.Context) ([]InstalledPackage, error) {
+	installedPackages := []InstalledPackage{}
+	err := c.runJSON(ctx, &installedPackages, "package", "installed", "list", "--all-namespaces", "-o", "json")
+	if err != nil {
+		return nil, fmt.Errorf("error occurred while listing installed packages: %v", err)
+	}
+	return installedPackages, nil
+}
+
+// GetClusterKubeconfig returns the admin kubeconfig of the workload cluster, without merging it into the
+// kubeconfig of the user
+// Runs `tanzu cluster kubeconfig get <cluster-name> --admin --export-file <file>`
+func (c Client) GetClusterKubeconfig(ctx context.Context, clusterName string) (*clientcmdapi.Config, error) {
+	exportDir, err := os.MkdirTemp("", "tanzu-kubeconfig-")
+	if err != nil {
+		return nil, fmt.Errorf("error occurred while creating directory for kubeconfig of %s cluster: %v", clusterName, err)
+	}
+	defer os.RemoveAll(exportDir)
+	exportFile := filepath.Join(exportDir, "kubeconfig")
+
+	_, err = c.run(ctx, "cluster", "kubeconfig", "get", clusterName, "--admin", "--export-file", exportFile)
+	if err != nil {
+		return nil, fmt.Errorf("error occurred while getting kubeconfig of %s cluster: %v", clusterName, err)
+	}
+
+	config, err := clientcmd.LoadFromFile(exportFile)
+	if err != nil {
+		return nil, fmt.Errorf("error occurred while parsing kubeconfig of %s cluster: %v", clusterName, err)
+	}
+	return config, nil
+}
+
+// runJSON runs the tanzu CLI with the arguments and decodes its JSON output into the value
+func (c Client) runJSON(ctx context.Context, value interface{}, args ...string) error {
+	output, err := c.run(ctx, args...)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(output, value)
+	if err != nil {
+		return fmt.Errorf("error decoding JSON output of `tanzu %s`: %v", strings.Join(args, " "), err)
+	}
+	return nil
+}
+
+func (c Client) run(ctx context.Context, args ...string) ([]byte, error) {
+	if c.Run != nil {
+		return c.Run(ctx, args...)
+	}
+
+	var output bytes.Buffer
+	result, err := clirunner.RunContext(ctx, clirunner.Cmd{
+		Name:             "tanzu",
+		Args:             args,
+		Env:              environ(c.Env),
+		Stdout:           &output,
+		StderrClassifier: log.DefaultStreamClassifier(),
+		Logger:           c.Logger,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("exit code: %v. error: %w. failure summary:\n%s", result.ExitCode, err, result.FailureSummary())
+	}
+	return output.Bytes(), nil
+}
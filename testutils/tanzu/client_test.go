@@ -0,0 +1,208 @@
+package tanzu_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/karuppiah7890/tce-e2e-test/testutils/tanzu"
+)
+
+// recordedOutput returns a tanzu CLI runner which returns the recorded output in the testdata directory for each
+// command, and fails the test for any other command
+func recordedOutput(t *testing.T, outputs map[string]string) func(ctx context.Context, args ...string) ([]byte, error) {
+	return func(ctx context.Context, args ...string) ([]byte, error) {
+		command := strings.Join(args, " ")
+		fixture, ok := outputs[command]
+		if !ok {
+			t.Fatalf("unexpected tanzu CLI command: tanzu %s", command)
+		}
+		return os.ReadFile(filepath.Join("testdata", fixture))
+	}
+}
+
+func TestClientListClusters(t *testing.T) {
+	t.Run("when there are clusters it should return all their details", func(t *testing.T) {
+		client := tanzu.Client{Run: recordedOutput(t, map[string]string{"cluster list -o json": "cluster-list.json"})}
+
+		clusters, err := client.ListClusters(context.Background())
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		if len(clusters) != 2 {
+			t.Fatalf("expected 2 clusters but got: %v", clusters)
+		}
+
+		expectedCluster := tanzu.Cluster{
+			Name:              "test-wkld",
+			Namespace:         "default",
+			Status:            tanzu.ClusterStatusRunning,
+			Plan:              "dev",
+			ControlPlane:      "1/1",
+			Workers:           "1/1",
+			KubernetesVersion: "v1.22.8+vmware.1",
+			Roles:             []string{},
+			Labels: map[string]string{
+				"cluster.x-k8s.io/cluster-name":     "test-wkld",
+				"tanzuKubernetesRelease":            "v1.22.8---vmware.1-tkg.1",
+				"tkg.tanzu.vmware.com/cluster-name": "test-wkld",
+			},
+		}
+		if !reflect.DeepEqual(clusters[0], expectedCluster) {
+			t.Errorf("expected cluster to be: %+v. But got: %+v", expectedCluster, clusters[0])
+		}
+		if clusters[1].Status != "creating" || clusters[1].Plan != "prod" || clusters[1].Workers != "0/3" {
+			t.Errorf("expected second cluster to be a prod cluster being created but got: %+v", clusters[1])
+		}
+	})
+
+	t.Run("when the tanzu CLI fails it should return an error", func(t *testing.T) {
+		client := tanzu.Client{Run: func(ctx context.Context, args ...string) ([]byte, error) {
+			return nil, fmt.Errorf("exit code: 1")
+		}}
+
+		_, err := client.ListClusters(context.Background())
+		expectedError := "error occurred while listing clusters: exit code: 1"
+		if err == nil || err.Error() != expectedError {
+			t.Errorf("expected error to be: %v. But got: %v", expectedError, err)
+		}
+	})
+
+	t.Run("when the output is not JSON it should return an error", func(t *testing.T) {
+		client := tanzu.Client{Run: func(ctx context.Context, args ...string) ([]byte, error) {
+			return []byte("NAME  NAMESPACE  STATUS"), nil
+		}}
+
+		_, err := client.ListClusters(context.Background())
+		expectedError := "error decoding JSON output of `tanzu cluster list -o json`"
+		if err == nil || !strings.Contains(err.Error(), expectedError) {
+			t.Errorf("expected error to contain: %v. But got: %v", expectedError, err)
+		}
+	})
+}
+
+func TestClientListManagementClusters(t *testing.T) {
+	t.Run("when the management cluster is listed along with the workload clusters it should return only the management cluster", func(t *testing.T) {
+		client := tanzu.Client{Run: recordedOutput(t, map[string]string{"cluster list --include-management-cluster -o json": "cluster-list-include-management-cluster.json"})}
+
+		clusters, err := client.ListManagementClusters(context.Background())
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		if len(clusters) != 1 || clusters[0].Name != "test-mgmt" || clusters[0].Namespace != "tkg-system" || !clusters[0].HasRole(tanzu.ManagementClusterRole) {
+			t.Errorf("expected only the test-mgmt management cluster but got: %+v", clusters)
+		}
+	})
+}
+
+func TestClientListPlugins(t *testing.T) {
+	t.Run("when there are plugins it should return their versions and statuses", func(t *testing.T) {
+		client := tanzu.Client{Run: recordedOutput(t, map[string]string{"plugin list -o json": "plugin-list.json"})}
+
+		plugins, err := client.ListPlugins(context.Background())
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+
+		expectedPlugin := tanzu.Plugin{
+			Name:        "unmanaged-cluster",
+			Description: "Create and manage local unmanaged clusters",
+			Scope:       "Stand-Alone",
+			Discovery:   "default-local",
+			Version:     "v0.12.0",
+			Status:      "not installed",
+		}
+		if len(plugins) != 4 || !reflect.DeepEqual(plugins[3], expectedPlugin) {
+			t.Errorf("expected 4 plugins with the last one being: %+v. But got: %+v", expectedPlugin, plugins)
+		}
+	})
+}
+
+func TestClientListPackageRepositories(t *testing.T) {
+	t.Run("when a package repository failed to reconcile it should return its status and details", func(t *testing.T) {
+		client := tanzu.Client{Run: recordedOutput(t, map[string]string{"package repository list --all-namespaces -o json": "package-repository-list.json"})}
+
+		packageRepositories, err := client.ListPackageRepositories(context.Background())
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		if len(packageRepositories) != 2 {
+			t.Fatalf("expected 2 package repositories but got: %+v", packageRepositories)
+		}
+
+		expectedPackageRepository := tanzu.PackageRepository{
+			Name:       "tanzu-core",
+			Namespace:  "tkg-system",
+			Repository: "projects.registry.vmware.com/tkg/packages/core/repo",
+			Tag:        "v1.22.8_vmware.1-tkg.1-tf-v0.20.0",
+			Status:     tanzu.ReconcileSucceededStatus,
+		}
+		if !reflect.DeepEqual(packageRepositories[0], expectedPackageRepository) {
+			t.Errorf("expected package repository to be: %+v. But got: %+v", expectedPackageRepository, packageRepositories[0])
+		}
+		if packageRepositories[1].Status == tanzu.ReconcileSucceededStatus || !strings.Contains(packageRepositories[1].Details, "MANIFEST_UNKNOWN") {
+			t.Errorf("expected package repository to have failed to reconcile but got: %+v", packageRepositories[1])
+		}
+	})
+}
+
+func TestClientListInstalledPackages(t *testing.T) {
+	t.Run("when there are installed packages it should return their packages, versions and statuses", func(t *testing.T) {
+		client := tanzu.Client{Run: recordedOutput(t, map[string]string{"package installed list --all-namespaces -o json": "package-installed-list.json"})}
+
+		installedPackages, err := client.ListInstalledPackages(context.Background())
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+
+		expectedInstalledPackages := []tanzu.InstalledPackage{
+			{
+				Name:           "antrea",
+				Namespace:      "tkg-system",
+				PackageName:    "antrea.tanzu.vmware.com",
+				PackageVersion: "1.2.3+vmware.4-tkg.1-advanced-zshippable",
+				Status:         tanzu.ReconcileSucceededStatus,
+			},
+			{
+				Name:           "cert-manager",
+				Namespace:      "default",
+				PackageName:    "cert-manager.community.tanzu.vmware.com",
+				PackageVersion: "1.6.1",
+				Status:         "Reconciling",
+			},
+		}
+		if !reflect.DeepEqual(installedPackages, expectedInstalledPackages) {
+			t.Errorf("expected installed packages to be: %+v. But got: %+v", expectedInstalledPackages, installedPackages)
+		}
+	})
+}
+
+func TestClientGetClusterKubeconfig(t *testing.T) {
+	t.Run("when the kubeconfig is exported it should return the parsed kubeconfig", func(t *testing.T) {
+		client := tanzu.Client{Run: func(ctx context.Context, args ...string) ([]byte, error) {
+			if len(args) != 7 || strings.Join(args[:6], " ") != "cluster kubeconfig get test-wkld --admin --export-file" {
+				t.Fatalf("unexpected tanzu CLI command: tanzu %s", strings.Join(args, " "))
+			}
+			data, err := os.ReadFile(filepath.Join("testdata", "kubeconfig.yaml"))
+			if err != nil {
+				return nil, err
+			}
+			return nil, os.WriteFile(args[6], data, 0600)
+		}}
+
+		config, err := client.GetClusterKubeconfig(context.Background(), "test-wkld")
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		if config.CurrentContext != "test-wkld-admin@test-wkld" {
+			t.Errorf("expected current context to be test-wkld-admin@test-wkld but got: %v", config.CurrentContext)
+		}
+		if config.Clusters["test-wkld"] == nil || config.Clusters["test-wkld"].Server != "https://10.0.0.10:6443" {
+			t.Errorf("expected test-wkld cluster with server https://10.0.0.10:6443 but got: %+v", config.Clusters)
+		}
+	})
+}
@@ -3,6 +3,8 @@ package tanzu
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
 	// TODO: Better name for this package?
 	tf "github.com/vmware-tanzu/tanzu-framework/apis/config/v1alpha1"
@@ -11,6 +13,7 @@ import (
 
 	"github.com/karuppiah7890/tce-e2e-test/testutils/clirunner"
 	"github.com/karuppiah7890/tce-e2e-test/testutils/log"
+	"sigs.k8s.io/yaml"
 )
 
 // TODO: Consider using Tanzu golang client library instead of running tanzu as a CLI.
@@ -34,7 +37,8 @@ type TanzuConfig map[string]string
 
 type EnvVars []string
 
-//TODO: Should we stick to env vars for cluster config or can we use yaml like tanzu cli consumes
+// TanzuConfigToEnvVars returns the config as environment variables. Clusters are created using a cluster config
+// file written by WriteFile instead, as environment variables are passed on to every child process
 func TanzuConfigToEnvVars(tanzuConfig TanzuConfig) EnvVars {
 	envVars := make(EnvVars, 0, len(tanzuConfig))
 
@@ -47,7 +51,7 @@ func TanzuConfigToEnvVars(tanzuConfig TanzuConfig) EnvVars {
 
 // Runs `tanzu config set features.global.context-aware-cli-for-plugins false` command
 func DisableContextAwareCliForPluginsGlobally() error {
-	exitCode, err := clirunner.Run(clirunner.Cmd{
+	result, err := clirunner.Run(clirunner.Cmd{
 		Name: "tanzu",
 		Args: []string{
 			"config",
@@ -55,16 +59,68 @@ func DisableContextAwareCliForPluginsGlobally() error {
 			"features.global.context-aware-cli-for-plugins",
 			"false",
 		},
-		Env:    os.Environ(),
-		Stdout: log.InfoWriter,
-		Stderr: log.ErrorWriter,
+		Env:              os.Environ(),
+		Stdout:           log.InfoWriter,
+		StderrClassifier: log.DefaultStreamClassifier(),
 	})
 	if err != nil {
-		return fmt.Errorf("error occurred while disabling context aware cli for plugins globally. Exit code: %v. Error: %v", exitCode, err)
+		return fmt.Errorf("error occurred while disabling context aware cli for plugins globally. Exit code: %v. Error: %v", result.ExitCode, err)
 	}
 	return nil
 }
 
-func GetClientConfig() (*tf.ClientConfig, error) {
-	return tfconfig.GetClientConfig()
+// GetClientConfig returns the config of the tanzu CLI run with the environment, like the environment of a test run
+// which has its own home directory. If env is nil, the environment of the process is used
+func GetClientConfig(env []string) (*tf.ClientConfig, error) {
+	configPath, err := ClientConfigPath(env)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading tanzu config %s: %v", configPath, err)
+	}
+
+	var clientConfig tf.ClientConfig
+	err = yaml.Unmarshal(data, &clientConfig)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding tanzu config %s: %v", configPath, err)
+	}
+	return &clientConfig, nil
+}
+
+// ClientConfigPath returns the path of the config of the tanzu CLI run with the environment - the TANZU_CONFIG
+// environment variable or else config.yaml in the .config/tanzu directory of HOME. If env is nil, the
+// environment of the process is used
+func ClientConfigPath(env []string) (string, error) {
+	env = environ(env)
+	if configPath, ok := lookupEnv(env, tfconfig.EnvConfigKey); ok {
+		return configPath, nil
+	}
+
+	home, ok := lookupEnv(env, "HOME")
+	if !ok || home == "" {
+		return "", fmt.Errorf("could not find home directory to get path of tanzu config")
+	}
+	return filepath.Join(home, tfconfig.LocalDirName, tfconfig.ConfigName), nil
+}
+
+// environ returns the environment, or the environment of the process if env is nil
+func environ(env []string) []string {
+	if env == nil {
+		return os.Environ()
+	}
+	return env
+}
+
+// lookupEnv returns the value of the environment variable in the environment. Like for a command, the last
+// value wins when the environment variable is set more than once
+func lookupEnv(env []string, name string) (string, bool) {
+	for index := len(env) - 1; index >= 0; index-- {
+		if strings.HasPrefix(env[index], name+"=") {
+			return strings.TrimPrefix(env[index], name+"="), true
+		}
+	}
+	return "", false
 }
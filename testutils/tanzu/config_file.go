@@ -0,0 +1,57 @@
+package tanzu
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// WriteFile writes the config as a cluster config file, in the format accepted by the --file flag of
+// `tanzu management-cluster create` and `tanzu cluster create`. The config variables named in secretNames, like
+// the provider's credentials, are left out so that the file can be kept with the test run artifacts, and their
+// names are written in a comment at the top of the file. Pass them as environment variables instead, using Secrets
+func (tanzuConfig TanzuConfig) WriteFile(path string, secretNames []string) error {
+	secrets := tanzuConfig.Secrets(secretNames)
+	config := map[string]string{}
+	for name, value := range tanzuConfig {
+		if _, isSecret := secrets[name]; !isSecret {
+			config[name] = value
+		}
+	}
+
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("error while encoding cluster config: %v", err)
+	}
+
+	if len(secrets) != 0 {
+		omittedNames := make([]string, 0, len(secrets))
+		for name := range secrets {
+			omittedNames = append(omittedNames, name)
+		}
+		sort.Strings(omittedNames)
+		comment := fmt.Sprintf("# Secrets left out of this file, to be set as environment variables: %s\n", strings.Join(omittedNames, ", "))
+		data = append([]byte(comment), data...)
+	}
+
+	err = os.WriteFile(path, data, 0644)
+	if err != nil {
+		return fmt.Errorf("error while writing cluster config file %s: %v", path, err)
+	}
+
+	return nil
+}
+
+// Secrets returns the config variables named in secretNames, which are not written by WriteFile
+func (tanzuConfig TanzuConfig) Secrets(secretNames []string) TanzuConfig {
+	secrets := TanzuConfig{}
+	for _, name := range secretNames {
+		if value, ok := tanzuConfig[name]; ok {
+			secrets[name] = value
+		}
+	}
+	return secrets
+}
@@ -0,0 +1,71 @@
+package tanzu_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/karuppiah7890/tce-e2e-test/testutils/tanzu"
+)
+
+func TestTanzuConfigWriteFile(t *testing.T) {
+	t.Run("it should write the config without the secrets and with a comment naming them", func(t *testing.T) {
+		tanzuConfig := tanzu.TanzuConfig{
+			"CLUSTER_NAME":               "test-mgmt",
+			"CLUSTER_PLAN":               "dev",
+			"ENABLE_MHC":                 "true",
+			"OS_ARCH":                    "",
+			"AWS_REGION":                 "us-east-1",
+			"AWS_NODE_AZ":                "us-east-1a",
+			"AWS_B64ENCODED_CREDENTIALS": "some-secret-b64-credentials",
+		}
+		secretNames := []string{"AWS_ACCESS_KEY_ID", "AWS_B64ENCODED_CREDENTIALS"}
+
+		configFilePath := filepath.Join(t.TempDir(), "test-mgmt.cluster-config.yaml")
+		err := tanzuConfig.WriteFile(configFilePath, secretNames)
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+
+		data, err := os.ReadFile(configFilePath)
+		if err != nil {
+			t.Fatalf("expected no error while reading cluster config file but got: %v", err)
+		}
+
+		expectedComment := "# Secrets left out of this file, to be set as environment variables: AWS_B64ENCODED_CREDENTIALS\n"
+		if !strings.HasPrefix(string(data), expectedComment) {
+			t.Errorf("expected cluster config file to start with %q but got:\n%s", expectedComment, data)
+		}
+
+		writtenConfig := map[string]string{}
+		err = yaml.Unmarshal(data, &writtenConfig)
+		if err != nil {
+			t.Fatalf("expected cluster config file to be YAML but got error: %v", err)
+		}
+
+		expectedConfig := map[string]string{
+			"CLUSTER_NAME": "test-mgmt",
+			"CLUSTER_PLAN": "dev",
+			"ENABLE_MHC":   "true",
+			"OS_ARCH":      "",
+			"AWS_REGION":   "us-east-1",
+			"AWS_NODE_AZ":  "us-east-1a",
+		}
+		if len(writtenConfig) != len(expectedConfig) {
+			t.Errorf("expected cluster config file to have %v but got %v", expectedConfig, writtenConfig)
+		}
+		for name, value := range expectedConfig {
+			if writtenValue, ok := writtenConfig[name]; !ok || writtenValue != value {
+				t.Errorf("expected %s to be %q in cluster config file but got %q", name, value, writtenValue)
+			}
+		}
+
+		secrets := tanzuConfig.Secrets(secretNames)
+		if len(secrets) != 1 || secrets["AWS_B64ENCODED_CREDENTIALS"] != "some-secret-b64-credentials" {
+			t.Errorf("expected only the credentials in the secrets but got %v", secrets)
+		}
+	})
+}
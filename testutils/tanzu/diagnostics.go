@@ -2,17 +2,17 @@ package tanzu
 
 import (
 	"fmt"
-	"os"
+	"path/filepath"
 
 	"github.com/karuppiah7890/tce-e2e-test/testutils/clirunner"
 	"github.com/karuppiah7890/tce-e2e-test/testutils/log"
 )
 
-func CollectManagementClusterDiagnostics(managementClusterName string) error {
-	log.Infof("Collecting diagnostics of `%s` management cluster", managementClusterName)
+func CollectManagementClusterDiagnostics(logger *log.Logger, env []string, managementClusterName string) error {
+	logger.Infof("Collecting diagnostics of `%s` management cluster", managementClusterName)
 	// Run `tanzu diagnostics collect --management-cluster-name <management-cluster-name>`
 
-	exitCode, err := clirunner.Run(clirunner.Cmd{
+	result, err := clirunner.Run(clirunner.Cmd{
 		Name: "tanzu",
 		Args: []string{
 			"diagnostics",
@@ -20,26 +20,27 @@ func CollectManagementClusterDiagnostics(managementClusterName string) error {
 			"--management-cluster-name",
 			managementClusterName,
 		},
-		Env:    os.Environ(),
-		Stdout: log.InfoWriter,
-		Stderr: log.ErrorWriter,
+		Env:              environ(env),
+		Stdout:           logger.InfoWriter(),
+		StderrClassifier: log.DefaultStreamClassifier(),
+		Logger:           logger,
 	})
 	if err != nil {
-		return fmt.Errorf("error occurred while collecting diagnostics of `%s` management cluster. exit code: %v. error: %v", managementClusterName, exitCode, err)
+		return fmt.Errorf("error occurred while collecting diagnostics of `%s` management cluster. exit code: %v. error: %v", managementClusterName, result.ExitCode, err)
 	}
 	return nil
 }
 
 // TODO: Convert workload cluster infra from string to a type - say iota or similar to get pre-defined (compile time) constants like azure, aws, vsphere, docker
-func CollectManagementClusterAndWorkloadClusterDiagnostics(managementClusterName string, workloadClusterName string, workloadClusterInfra string) error {
-	log.Infof("Collecting diagnostics of `%s` management cluster and `%s` workload cluster (in `%s` infra)", managementClusterName, workloadClusterName, workloadClusterInfra)
+func CollectManagementClusterAndWorkloadClusterDiagnostics(logger *log.Logger, env []string, managementClusterName string, workloadClusterName string, workloadClusterInfra string) error {
+	logger.Infof("Collecting diagnostics of `%s` management cluster and `%s` workload cluster (in `%s` infra)", managementClusterName, workloadClusterName, workloadClusterInfra)
 	// Run the command
 	// `tanzu diagnostics collect --bootstrap-cluster-skip \
 	//         --management-cluster-name <management-cluster-name> \
 	//         --workload-cluster-infra <workload-cluster-infra> \
 	//         --workload-cluster-name <workload-cluster-name>`
 
-	exitCode, err := clirunner.Run(clirunner.Cmd{
+	result, err := clirunner.Run(clirunner.Cmd{
 		Name: "tanzu",
 		Args: []string{
 			"diagnostics",
@@ -52,13 +53,40 @@ func CollectManagementClusterAndWorkloadClusterDiagnostics(managementClusterName
 			"--workload-cluster-infra",
 			workloadClusterInfra,
 		},
-		Env:    os.Environ(),
-		Stdout: log.InfoWriter,
-		Stderr: log.ErrorWriter,
+		Env:              environ(env),
+		Stdout:           logger.InfoWriter(),
+		StderrClassifier: log.DefaultStreamClassifier(),
+		Logger:           logger,
 	})
 	if err != nil {
 		return fmt.Errorf("error occurred while collecting diagnostics of `%s` management cluster and `%s` workload cluster (in `%s` infra). exit code: %v. error: %v",
-			managementClusterName, workloadClusterName, workloadClusterInfra, exitCode, err)
+			managementClusterName, workloadClusterName, workloadClusterInfra, result.ExitCode, err)
 	}
 	return nil
 }
+
+// DiagnosticsBundles returns the absolute paths of the diagnostics bundles of the clusters that are in
+// the current working directory, which is where `tanzu diagnostics collect` writes them by default.
+// The bundles are named like <bootstrap|management-cluster|workload-cluster>.<cluster-name>.diagnostics.tar.gz
+func DiagnosticsBundles(clusterNames ...string) ([]string, error) {
+	bundles := []string{}
+	for _, clusterName := range clusterNames {
+		if clusterName == "" {
+			continue
+		}
+
+		matches, err := filepath.Glob(fmt.Sprintf("*.%s.diagnostics.tar.gz", clusterName))
+		if err != nil {
+			return nil, fmt.Errorf("error while finding diagnostics bundles of `%s` cluster: %v", clusterName, err)
+		}
+
+		for _, match := range matches {
+			bundle, err := filepath.Abs(match)
+			if err != nil {
+				return nil, fmt.Errorf("error while getting absolute path of diagnostics bundle %s: %v", match, err)
+			}
+			bundles = append(bundles, bundle)
+		}
+	}
+	return bundles, nil
+}
@@ -4,12 +4,14 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/karuppiah7890/tce-e2e-test/testutils/clirunner"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/log"
 	tf "github.com/vmware-tanzu/tanzu-framework/apis/config/v1alpha1"
 )
 
 // TODO: Rename this to a smaller name
-func GetBootstrapClusterDockerContainerNameForManagementCluster(managementClusterName string) (string, error) {
-	clientConfig, err := GetClientConfig()
+func GetBootstrapClusterDockerContainerNameForManagementCluster(env []string, managementClusterName string) (string, error) {
+	clientConfig, err := GetClientConfig(env)
 	if err != nil {
 		return "", fmt.Errorf("error getting tanzu client config: %v", err)
 	}
@@ -30,3 +32,47 @@ func GetBootstrapClusterDockerContainerNameForManagementCluster(managementCluste
 	}
 	return "", fmt.Errorf("could not find the management cluster %s in the tanzu config yaml", managementClusterName)
 }
+
+// FindManagementCluster returns the name of the management cluster known to the tanzu CLI which has the name or
+// whose kube context is the kube context, like test-mgmt-admin@test-mgmt, using the servers in the tanzu config yaml
+// of the environment
+func FindManagementCluster(env []string, nameOrKubeContext string) (string, error) {
+	clientConfig, err := GetClientConfig(env)
+	if err != nil {
+		return "", fmt.Errorf("error getting tanzu client config: %v", err)
+	}
+	for _, server := range clientConfig.KnownServers {
+		if server.Type != tf.ManagementClusterServerType {
+			continue
+		}
+		if server.Name == nameOrKubeContext {
+			return server.Name, nil
+		}
+		if server.ManagementClusterOpts != nil && server.ManagementClusterOpts.Context == nameOrKubeContext {
+			return server.Name, nil
+		}
+	}
+	return "", fmt.Errorf("could not find a management cluster named %s or with kube context %s in the tanzu config yaml", nameOrKubeContext, nameOrKubeContext)
+}
+
+// LoginToManagementCluster makes the management cluster the current server of the tanzu CLI, so that the workload
+// clusters are created in it
+// Runs `tanzu login --server <management-cluster-name>`
+func LoginToManagementCluster(logger *log.Logger, env []string, managementClusterName string) error {
+	result, err := clirunner.Run(clirunner.Cmd{
+		Name: "tanzu",
+		Args: []string{
+			"login",
+			"--server",
+			managementClusterName,
+		},
+		Env:              environ(env),
+		Stdout:           logger.InfoWriter(),
+		StderrClassifier: log.DefaultStreamClassifier(),
+		Logger:           logger,
+	})
+	if err != nil {
+		return fmt.Errorf("error occurred while logging in to `%s` management cluster. exit code: %v. error: %v", managementClusterName, result.ExitCode, err)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package tanzu_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/karuppiah7890/tce-e2e-test/testutils/tanzu"
+)
+
+const tanzuConfigWithManagementCluster = `apiVersion: config.tanzu.vmware.com/v1alpha1
+kind: ClientConfig
+metadata:
+  creationTimestamp: null
+current: test-mgmt
+servers:
+- managementClusterOpts:
+    context: test-mgmt-admin@test-mgmt
+    path: /home/tester/.kube-tkg/config
+  name: test-mgmt
+  type: managementcluster
+`
+
+func TestFindManagementCluster(t *testing.T) {
+	configFilePath := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(configFilePath, []byte(tanzuConfigWithManagementCluster), 0600)
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	env := []string{"HOME=/home/tester", "TANZU_CONFIG=" + configFilePath}
+
+	t.Run("when given the name or the kube context of a known management cluster it should return its name", func(t *testing.T) {
+		for _, nameOrKubeContext := range []string{"test-mgmt", "test-mgmt-admin@test-mgmt"} {
+			name, err := tanzu.FindManagementCluster(env, nameOrKubeContext)
+			if err != nil {
+				t.Fatalf("expected no error for %s but got: %v", nameOrKubeContext, err)
+			}
+			if name != "test-mgmt" {
+				t.Errorf("expected management cluster test-mgmt for %s but got %s", nameOrKubeContext, name)
+			}
+		}
+	})
+
+	t.Run("when the management cluster is not known it should return an error", func(t *testing.T) {
+		_, err := tanzu.FindManagementCluster(env, "other-mgmt")
+		if err == nil {
+			t.Errorf("expected an error but got none")
+		}
+	})
+	t.Run("when the environment has no tanzu config file set it should use the tanzu config in its home directory", func(t *testing.T) {
+		home := t.TempDir()
+		err := os.MkdirAll(filepath.Join(home, ".config", "tanzu"), 0700)
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		err = os.WriteFile(filepath.Join(home, ".config", "tanzu", "config.yaml"), []byte(tanzuConfigWithManagementCluster), 0600)
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+
+		name, err := tanzu.FindManagementCluster([]string{"HOME=" + home}, "test-mgmt")
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		if name != "test-mgmt" {
+			t.Errorf("expected management cluster test-mgmt but got %s", name)
+		}
+	})
+}
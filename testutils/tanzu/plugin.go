@@ -12,7 +12,7 @@ import (
 // Update a repository configuration
 // Runs `tanzu plugin repo update --gcp-bucket-name <gcp-bucket-name> <repo-name>`
 func PluginRepoUpdate(repoName string, gcpBucketName string) error {
-	exitCode, err := clirunner.Run(clirunner.Cmd{
+	result, err := clirunner.Run(clirunner.Cmd{
 		Name: "tanzu",
 		Args: []string{
 			"plugin",
@@ -22,33 +22,31 @@ func PluginRepoUpdate(repoName string, gcpBucketName string) error {
 			gcpBucketName,
 			repoName,
 		},
-		Env:    os.Environ(),
-		Stdout: log.InfoWriter,
-		Stderr: log.ErrorWriter,
+		Env:              os.Environ(),
+		Stdout:           log.InfoWriter,
+		StderrClassifier: log.DefaultStreamClassifier(),
 	})
 	if err != nil {
-		return fmt.Errorf("error occurred while updating repository configuration of `%s` repository with `%s` GCP bucket. Exit code: %v. Error: %v", repoName, gcpBucketName, exitCode, err)
+		return fmt.Errorf("error occurred while updating repository configuration of `%s` repository with `%s` GCP bucket. Exit code: %v. Error: %v", repoName, gcpBucketName, result.ExitCode, err)
 	}
 	return nil
 }
 
-// TODO: Should we rename this to ListPlugins?
-// TODO: Maybe in the future we can parse the data (with -o json JSON output) and return it
-// List available plugins
+// List available plugins. Use Client.ListPlugins to get the plugins
 // Runs `tanzu plugin list`
 func PluginList() error {
-	exitCode, err := clirunner.Run(clirunner.Cmd{
+	result, err := clirunner.Run(clirunner.Cmd{
 		Name: "tanzu",
 		Args: []string{
 			"plugin",
 			"list",
 		},
-		Env:    os.Environ(),
-		Stdout: log.InfoWriter,
-		Stderr: log.ErrorWriter,
+		Env:              os.Environ(),
+		Stdout:           log.InfoWriter,
+		StderrClassifier: log.DefaultStreamClassifier(),
 	})
 	if err != nil {
-		return fmt.Errorf("error occurred while listing available plugins. Exit code: %v. Error: %v", exitCode, err)
+		return fmt.Errorf("error occurred while listing available plugins. Exit code: %v. Error: %v", result.ExitCode, err)
 	}
 	return nil
 }
@@ -58,7 +56,7 @@ func PluginList() error {
 // Install a plugin
 // Runs `tanzu plugin install --local <path-to-local-discovery-or-distribution-source> <plugin-name>`
 func PluginInstall(pluginName string, pathToLocalDiscoveryOrDistributionSource string) error {
-	exitCode, err := clirunner.Run(clirunner.Cmd{
+	result, err := clirunner.Run(clirunner.Cmd{
 		Name: "tanzu",
 		Args: []string{
 			"plugin",
@@ -67,12 +65,12 @@ func PluginInstall(pluginName string, pathToLocalDiscoveryOrDistributionSource s
 			pathToLocalDiscoveryOrDistributionSource,
 			pluginName,
 		},
-		Env:    os.Environ(),
-		Stdout: log.InfoWriter,
-		Stderr: log.ErrorWriter,
+		Env:              os.Environ(),
+		Stdout:           log.InfoWriter,
+		StderrClassifier: log.DefaultStreamClassifier(),
 	})
 	if err != nil {
-		return fmt.Errorf("error occurred while install `%s` plugin from `%s` local source. Exit code: %v. Error: %v", pluginName, pathToLocalDiscoveryOrDistributionSource, exitCode, err)
+		return fmt.Errorf("error occurred while install `%s` plugin from `%s` local source. Exit code: %v. Error: %v", pluginName, pathToLocalDiscoveryOrDistributionSource, result.ExitCode, err)
 	}
 	return nil
 }
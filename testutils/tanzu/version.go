@@ -1,7 +1,9 @@
 package tanzu
 
 import (
+	"bytes"
 	"fmt"
+	"strings"
 
 	"github.com/karuppiah7890/tce-e2e-test/testutils/clirunner"
 	"github.com/karuppiah7890/tce-e2e-test/testutils/log"
@@ -13,18 +15,66 @@ func PrintTanzuVersion() error {
 		Args: []string{
 			"version",
 		},
-		Stdout: log.InfoWriter,
-		Stderr: log.ErrorWriter,
+		Stdout:           log.InfoWriter,
+		StderrClassifier: log.DefaultStreamClassifier(),
 	}
 
-	exitCode, err := clirunner.Run(cmd)
+	result, err := clirunner.Run(cmd)
 	if err != nil {
-		return fmt.Errorf("error occurred while printing tanzu CLI version. Exit code: %v. Error: %v", exitCode, err)
+		return fmt.Errorf("error occurred while printing tanzu CLI version. Exit code: %v. Error: %v", result.ExitCode, err)
 	}
 
 	return nil
 }
 
+// GetTanzuVersion returns the version of the tanzu CLI, which is the version of TF (Tanzu Framework)
+func GetTanzuVersion(logger *log.Logger) (string, error) {
+	var versionOutput bytes.Buffer
+
+	result, err := clirunner.Run(clirunner.Cmd{
+		Name: "tanzu",
+		Args: []string{
+			"version",
+		},
+		Stdout:           &versionOutput,
+		StderrClassifier: log.DefaultStreamClassifier(),
+		Logger:           logger,
+	})
+	if err != nil {
+		return "", fmt.Errorf("error occurred while getting tanzu CLI version. Exit code: %v. Error: %v", result.ExitCode, err)
+	}
+
+	version, err := parseTanzuVersion(versionOutput.String())
+	if err != nil {
+		return "", fmt.Errorf("error occurred while parsing tanzu CLI version: %v", err)
+	}
+
+	return version, nil
+}
+
+// parseTanzuVersion parses the output of `tanzu version`, which looks like
+//
+//	version: v0.11.6
+//	buildDate: 2022-05-20
+//	sha: 4f5d7b5b
+func parseTanzuVersion(output string) (string, error) {
+	for _, line := range strings.Split(output, "\n") {
+		key, value, found := cut(strings.TrimSpace(line), ":")
+		if found && key == "version" {
+			return strings.TrimSpace(value), nil
+		}
+	}
+	return "", fmt.Errorf("could not find version in the output: %s", output)
+}
+
+// cut is the same as strings.Cut which is available only from Go 1.18
+func cut(s, sep string) (before, after string, found bool) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
 // Where to get the list of plugins from?
 // 1. tanzu plugin list . This also has json and yaml output with `-o` flag
 // 2. check artifact (tar ball, zip) for K8s resource yaml files of kind cli.tanzu.vmware.com/v1alpha1/CLIPlugin inside the
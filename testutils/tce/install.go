@@ -52,6 +52,12 @@ func getTceArtifactUrl(version, buildType string) (string, error) {
 // TODO: Should we support Install("v0.11.0") too? Or just have one of them? Which one?
 // Example: Install("0.11.0")
 func Install(version, buildType string) error {
+	return InstallWithEnv(version, buildType, nil)
+}
+
+// InstallWithEnv installs the TCE version like Install, running the install script with the environment, like the
+// environment of a test run which has its own home directory. If env is nil, the environment of the process is used
+func InstallWithEnv(version, buildType string, env []string) error {
 	log.Infof("Starting install of TCE version %s", version)
 	artifactUrl, err := getTceArtifactUrl(version, buildType)
 	if err != nil {
@@ -81,14 +87,14 @@ func Install(version, buildType string) error {
 	// TODO: For windows, after the install.bat script is called, we need to add %PROGRAMFILES%\tanzu to the System PATH
 	// so that `tanzu` CLI command can be used
 
-	return invokeTceInstallScript(targetDirectory)
+	return invokeTceInstallScript(targetDirectory, env)
 }
 
 func getTargetDirectory() string {
 	return filepath.Join(os.TempDir(), fmt.Sprintf("tce-install-%d", time.Now().Unix()))
 }
 
-func invokeTceInstallScript(targetDirectory string) error {
+func invokeTceInstallScript(targetDirectory string, env []string) error {
 	dirEntries, err := os.ReadDir(targetDirectory)
 
 	if err != nil {
@@ -115,9 +121,13 @@ func invokeTceInstallScript(targetDirectory string) error {
 	cmd.Dir = filepath.Join(targetDirectory, tceDir.Name())
 	cmd.Stdout = log.InfoWriter
 	cmd.Stderr = log.ErrorWriter
+	cmd.Env = env
 
 	if operatingSystem == platforms.LINUX || operatingSystem == platforms.DARWIN {
-		cmd.Env = append(os.Environ(), "ALLOW_INSTALL_AS_ROOT=true")
+		if env == nil {
+			env = os.Environ()
+		}
+		cmd.Env = append(env, "ALLOW_INSTALL_AS_ROOT=true")
 	}
 
 	log.Infof("Running the command `%v`", cmd.String())
@@ -3,6 +3,7 @@ package tce
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/karuppiah7890/tce-e2e-test/testutils/clirunner"
 	"github.com/karuppiah7890/tce-e2e-test/testutils/kubeclient"
@@ -15,12 +16,14 @@ type Package struct {
 	ManualCreate bool
 }
 
-func PackageE2Etest(packageDetails Package, workloadClusterKubeContext string) error {
-	err := kubeclient.UseKubeConfigContext(workloadClusterKubeContext)
+// PackageE2Etest runs the E2E test of the package in the workload cluster, with the environment of the test run.
+// If env is nil, the environment of the process is used
+func PackageE2Etest(logger *log.Logger, env []string, packageDetails Package, workloadClusterKubeContext string) error {
+	err := kubeclient.UseKubeConfigContext(logger, env, workloadClusterKubeContext)
 	if err != nil {
 		return fmt.Errorf("error occurred while using the workload cluster context. error: %v", err)
 	}
-	exitCode, err := clirunner.Run(clirunner.Cmd{
+	result, err := clirunner.Run(clirunner.Cmd{
 		Name: "tanzu",
 		Args: []string{
 			"package",
@@ -32,48 +35,43 @@ func PackageE2Etest(packageDetails Package, workloadClusterKubeContext string) e
 			"--namespace",
 			"tanzu-package-repo-global",
 		},
-		Stdout: log.InfoWriter,
-		// TODO: Should we log standard errors as errors in the log? Because tanzu prints other information also
-		// to standard error, which are kind of like information, apart from actual errors, so showing
-		// everything as error is misleading. Gotta think what to do about this. The main problem is
-		// console has only standard output and standard error, and tanzu is using standard output only for
-		// giving output for things like --dry-run when it needs to print yaml content, but everything else
-		// is printed to standard error
-		Stderr: log.ErrorWriter,
+		Env:              env,
+		Stdout:           logger.InfoWriter(),
+		StderrClassifier: log.DefaultStreamClassifier(),
+		Logger:           logger,
 	})
 	if err != nil {
-		return fmt.Errorf("error occurred while adding package repo. exit code: %v. error: %v", exitCode, err)
+		return fmt.Errorf("error occurred while adding package repo. exit code: %v. error: %v", result.ExitCode, err)
 	}
 
 	//Prerequisites(packageDetails)
 	if packageDetails.ManualCreate {
-		err := InstallPackage(packageDetails)
+		err := InstallPackage(logger, env, packageDetails)
 		if err != nil {
 			return fmt.Errorf("%v", err)
 		}
 	}
 
-	err = os.Chdir("community-edition/addons/packages/" + packageDetails.Name + "/" + packageDetails.Version + "/test")
-	if err != nil {
-		return fmt.Errorf("error while changing directory to community-edition: %v", err)
+	// The test is run in the package's test directory without changing the working directory of the
+	// process, as package tests of different test runs can run at the same time
+	testDirectory := filepath.Join("community-edition", "addons", "packages", packageDetails.Name, packageDetails.Version, "test")
+	if _, err := os.Stat(testDirectory); err != nil {
+		return fmt.Errorf("error while finding test directory of %v package in community-edition: %v", packageDetails.Name, err)
 	}
-	exitCode, err = clirunner.Run(clirunner.Cmd{
+	result, err = clirunner.Run(clirunner.Cmd{
 		Name: "make",
 		Args: []string{
 			"e2e-test",
 		},
-		Stdout: log.InfoWriter,
-		// TODO: Should we log standard errors as errors in the log? Because tanzu prints other information also
-		// to standard error, which are kind of like information, apart from actual errors, so showing
-		// everything as error is misleading. Gotta think what to do about this. The main problem is
-		// console has only standard output and standard error, and tanzu is using standard output only for
-		// giving output for things like --dry-run when it needs to print yaml content, but everything else
-		// is printed to standard error
-		Stderr: log.ErrorWriter,
+		Dir:              testDirectory,
+		Env:              env,
+		Stdout:           logger.InfoWriter(),
+		StderrClassifier: log.DefaultStreamClassifier(),
+		Logger:           logger,
 	})
 
 	if packageDetails.ManualCreate {
-		err := DeletePackage(packageDetails)
+		err := DeletePackage(logger, env, packageDetails)
 		if err != nil {
 			return fmt.Errorf("%v", err)
 		}
@@ -81,15 +79,15 @@ func PackageE2Etest(packageDetails Package, workloadClusterKubeContext string) e
 	}
 
 	if err != nil {
-		return fmt.Errorf("error occurred while E2E test for %v. Exit code: %v. Error: %v", packageDetails.Name, exitCode, err)
+		return fmt.Errorf("error occurred while E2E test for %v. Exit code: %v. Error: %v. Failure summary:\n%s", packageDetails.Name, result.ExitCode, err, result.FailureSummary())
 	}
 
 	return nil
 }
 
-func InstallPackage(packageDetails Package) error {
+func InstallPackage(logger *log.Logger, env []string, packageDetails Package) error {
 	wd, _ := os.Getwd()
-	exitCode, err := clirunner.Run(clirunner.Cmd{
+	result, err := clirunner.Run(clirunner.Cmd{
 		Name: "tanzu",
 		Args: []string{
 			"package",
@@ -100,23 +98,19 @@ func InstallPackage(packageDetails Package) error {
 			"--version", packageDetails.Version,
 			"--values-file", wd + "/testutils/tce/testdata/" + packageDetails.Name + "_values.yaml",
 		},
-		Stdout: log.InfoWriter,
-		// TODO: Should we log standard errors as errors in the log? Because tanzu prints other information also
-		// to standard error, which are kind of like information, apart from actual errors, so showing
-		// everything as error is misleading. Gotta think what to do about this. The main problem is
-		// console has only standard output and standard error, and tanzu is using standard output only for
-		// giving output for things like --dry-run when it needs to print yaml content, but everything else
-		// is printed to standard error
-		Stderr: log.ErrorWriter,
+		Env:              env,
+		Stdout:           logger.InfoWriter(),
+		StderrClassifier: log.DefaultStreamClassifier(),
+		Logger:           logger,
 	})
 	if err != nil {
-		return fmt.Errorf("error occurred while installing %v package. exit code: %v. error: %v", packageDetails.Name, exitCode, err)
+		return fmt.Errorf("error occurred while installing %v package. exit code: %v. error: %v", packageDetails.Name, result.ExitCode, err)
 	}
 	return nil
 }
 
-func DeletePackage(packageDetails Package) error {
-	exitCode, err := clirunner.Run(clirunner.Cmd{
+func DeletePackage(logger *log.Logger, env []string, packageDetails Package) error {
+	result, err := clirunner.Run(clirunner.Cmd{
 		Name: "tanzu",
 		Args: []string{
 			"package",
@@ -125,17 +119,13 @@ func DeletePackage(packageDetails Package) error {
 			packageDetails.Name,
 			"-y",
 		},
-		Stdout: log.InfoWriter,
-		// TODO: Should we log standard errors as errors in the log? Because tanzu prints other information also
-		// to standard error, which are kind of like information, apart from actual errors, so showing
-		// everything as error is misleading. Gotta think what to do about this. The main problem is
-		// console has only standard output and standard error, and tanzu is using standard output only for
-		// giving output for things like --dry-run when it needs to print yaml content, but everything else
-		// is printed to standard error
-		Stderr: log.ErrorWriter,
+		Env:              env,
+		Stdout:           logger.InfoWriter(),
+		StderrClassifier: log.DefaultStreamClassifier(),
+		Logger:           logger,
 	})
 	if err != nil {
-		return fmt.Errorf("error occurred while deleting %v package. exit code: %v. error: %v", packageDetails.Name, exitCode, err)
+		return fmt.Errorf("error occurred while deleting %v package. exit code: %v. error: %v", packageDetails.Name, result.ExitCode, err)
 	}
 	return nil
 }
@@ -1,62 +1,158 @@
 package utils
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 	"time"
 
+	"github.com/karuppiah7890/tce-e2e-test/testutils/capi"
 	"github.com/karuppiah7890/tce-e2e-test/testutils/clirunner"
 	"github.com/karuppiah7890/tce-e2e-test/testutils/docker"
 	"github.com/karuppiah7890/tce-e2e-test/testutils/kubeclient"
 	"github.com/karuppiah7890/tce-e2e-test/testutils/log"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/poll"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/scenario"
 	"github.com/karuppiah7890/tce-e2e-test/testutils/tanzu"
 	"k8s.io/client-go/util/homedir"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type ClusterTestRunner interface {
-	RunChecks()
+	RunChecks() error
+	CheckConfig(provider Provider) error
+	GetTanzuConfig(provider Provider, clusterName string, clusterType ClusterType) tanzu.TanzuConfig
 	GetRandomClusterNames() (string, string)
 	GetKubeContextForTanzuCluster(clusterName string) string
 	GetKubeConfigPath() (string, error)
-	RunCluster(clusterName string, provider Provider, clusterType ClusterType) error
-	GetClusterKubeConfig(clusterName string, provider Provider, clusterType ClusterType)
+	RunCluster(ctx context.Context, clusterName string, provider Provider, clusterType ClusterType) error
+	DryRunCluster(ctx context.Context, clusterName string, provider Provider, clusterType ClusterType) ([]byte, error)
+	GetClusterKubeConfig(clusterName string, provider Provider, clusterType ClusterType) error
 	PrintClusterInformation(kubeConfigPath string, kubeContext string) error
-	CheckWorkloadClusterIsRunning(workloadClusterName string) error
-	DeleteCluster(clusterName string, provider Provider, clusterType ClusterType) error
-	WaitForWorkloadClusterDeletion(workloadClusterName string) error
+	CheckWorkloadClusterIsRunning(ctx context.Context, managementClusterName string, workloadClusterName string) error
+	FindManagementCluster(nameOrKubeContext string) (string, error)
+	LoginToManagementCluster(managementClusterName string) error
+	CheckManagementClusterIsHealthy(ctx context.Context, managementClusterName string) error
+	ScaleWorkloadCluster(ctx context.Context, managementClusterName string, workloadClusterName string, workerCount int) error
+	UpgradeWorkloadCluster(ctx context.Context, managementClusterName string, workloadClusterName string, tkr string) error
+	CheckMachineHealthCheckRemediation(ctx context.Context, managementClusterName string, workloadClusterName string) error
+	DeployUpgradeTestWorkload(ctx context.Context, clusterName string) error
+	InstallTCE(version string) error
+	UpgradeManagementCluster(ctx context.Context, managementClusterName string) error
+	CheckUpgradedClusters(ctx context.Context, managementClusterName string, workloadClusterName string) error
+	DeleteCluster(ctx context.Context, clusterName string, provider Provider, clusterType ClusterType) error
+	WaitForWorkloadClusterDeletion(ctx context.Context, managementClusterName string, workloadClusterName string) error
 	CollectManagementClusterDiagnostics(managementClusterName string) error
 	CollectManagementClusterAndWorkloadClusterDiagnostics(managementClusterName string, workloadClusterName string, workloadClusterInfra string) error
 	DeleteContext(kubeConfigPath string, contextName string) error
 	CleanupDockerBootstrapCluster(managementClusterName string) error
+	GetLogger() *log.Logger
+	GetEnv() []string
+	GetTanzuVersion() (string, error)
 }
 
-type DefaultClusterTestRunner struct{}
+// Maximum time given to the tanzu CLI to create, delete or render the manifest of a cluster. The context passed to
+// the runner methods can stop the tanzu CLI earlier
+const (
+	clusterCreationTimeout = 60 * time.Minute
+	clusterDeletionTimeout = 30 * time.Minute
+	clusterDryRunTimeout   = 10 * time.Minute
+)
+
+// Polling of the CAPI objects of a workload cluster in the management cluster, to wait for the workload cluster
+// to be ready or deleted
+var clusterPollOptions = poll.Options{
+	Interval:    10 * time.Second,
+	Timeout:     15 * time.Minute,
+	Backoff:     1.5,
+	MaxInterval: time.Minute,
+}
+
+type DefaultClusterTestRunner struct {
+	// Logger is used for all the logs of the test run, including the output of the commands run.
+	// If Logger is nil, the global logger is used
+	Logger *log.Logger
+	// Scenario has the cluster plan, OS, Kubernetes version and config variables to create the clusters
+	// with. When Scenario is nil, the provider's default config is used
+	Scenario *scenario.Scenario
+	// ClusterNameSuffix is the suffix of the names of the clusters, to tell apart the clusters of test runs
+	// started at the same time. When ClusterNameSuffix is empty, the current unix time is used
+	ClusterNameSuffix string
+	// HomeDir is the home directory of the commands run for the test run, with the kubeconfig and the tanzu CLI
+	// config of the test run, like one created by NewHomeDir. It isolates test runs run at the same time from each
+	// other. When HomeDir is empty, the home directory of the user is used
+	HomeDir string
+}
 
 // This is to ensure that DefaultClusterTestRunner implements ClusterTestRunner interface
 // and if not, compiler level errors will be thrown
 var _ ClusterTestRunner = DefaultClusterTestRunner{}
 
-func (r DefaultClusterTestRunner) RunChecks() {
-	_ = CheckTanzuCLIInstallation()
+// RunChecks checks that all the tools needed for the test run are installed. All the checks are run,
+// and the returned *PreflightError has the errors of all the failed checks, so that every missing
+// tool is known at once
+func (r DefaultClusterTestRunner) RunChecks() error {
+	errs := []error{}
 
-	CheckTanzuClusterCLIPluginInstallation(ManagementClusterType)
+	err := CheckTanzuCLIInstallation(r.Logger)
+	if err != nil {
+		errs = append(errs, err)
+	} else {
+		// The plugins can be checked only when the tanzu CLI is installed
+		for _, clusterType := range []ClusterType{ManagementClusterType, WorkloadClusterType} {
+			err := CheckTanzuClusterCLIPluginInstallation(r.Logger, clusterType)
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
 
-	CheckTanzuClusterCLIPluginInstallation(WorkloadClusterType)
+	err = docker.CheckDockerInstallation()
+	if err != nil {
+		errs = append(errs, err)
+	}
 
-	docker.CheckDockerInstallation()
+	err = CheckKubectlCLIInstallation(r.Logger)
+	if err != nil {
+		errs = append(errs, err)
+	}
 
-	CheckKubectlCLIInstallation()
+	PlatformSupportCheck(r.Logger)
 
-	PlatformSupportCheck()
+	if len(errs) != 0 {
+		return &PreflightError{Errors: errs}
+	}
+	return nil
+}
+
+// CheckConfig checks that the scenario of the test run, if any, is for the provider
+func (r DefaultClusterTestRunner) CheckConfig(provider Provider) error {
+	if r.Scenario != nil {
+		if r.Scenario.Provider != provider.Name() {
+			return fmt.Errorf("scenario %s is for %s provider and not for %s provider", r.Scenario.Name, r.Scenario.Provider, provider.Name())
+		}
+		r.Logger.Infof("Using scenario %s", r.Scenario.Name)
+	}
+	return nil
+}
+
+// GetTanzuConfig returns the tanzu config of the cluster, with the scenario's config, if any, over the provider's
+// default config
+func (r DefaultClusterTestRunner) GetTanzuConfig(provider Provider, clusterName string, clusterType ClusterType) tanzu.TanzuConfig {
+	return ClusterTanzuConfig(provider, r.Scenario, clusterName, clusterType)
 }
 
 func (r DefaultClusterTestRunner) GetRandomClusterNames() (string, string) {
-	clusterNameSuffix := time.Now().Unix()
-	managementClusterName := fmt.Sprintf("test-mgmt-%d", clusterNameSuffix)
-	workloadClusterName := fmt.Sprintf("test-wkld-%d", clusterNameSuffix)
-	log.Infof("Management Cluster Name : %s", managementClusterName)
-	log.Infof("Workload Cluster Name : %s", workloadClusterName)
+	clusterNameSuffix := r.ClusterNameSuffix
+	if clusterNameSuffix == "" {
+		clusterNameSuffix = fmt.Sprintf("%d", time.Now().Unix())
+	}
+	managementClusterName := fmt.Sprintf("test-mgmt-%s", clusterNameSuffix)
+	workloadClusterName := fmt.Sprintf("test-wkld-%s", clusterNameSuffix)
+	r.Logger.Infof("Management Cluster Name : %s", managementClusterName)
+	r.Logger.Infof("Workload Cluster Name : %s", workloadClusterName)
 	return managementClusterName, workloadClusterName
 }
 
@@ -65,6 +161,10 @@ func (r DefaultClusterTestRunner) GetKubeContextForTanzuCluster(clusterName stri
 }
 
 func (r DefaultClusterTestRunner) GetKubeConfigPath() (string, error) {
+	if r.HomeDir != "" {
+		return homeKubeConfigPath(r.HomeDir), nil
+	}
+
 	home := homedir.HomeDir()
 
 	if home == "" {
@@ -74,39 +174,105 @@ func (r DefaultClusterTestRunner) GetKubeConfigPath() (string, error) {
 	return filepath.Join(home, ".kube", "config"), nil
 }
 
-func (r DefaultClusterTestRunner) RunCluster(clusterName string, provider Provider, clusterType ClusterType) error {
-	envVars := tanzu.TanzuConfigToEnvVars(provider.GetTanzuConfig(clusterName))
-	exitCode, err := clirunner.Run(clirunner.Cmd{
+// writeClusterConfigFile writes the tanzu config of the cluster to the cluster config file of the cluster and
+// returns the config along with the path of the file
+func (r DefaultClusterTestRunner) writeClusterConfigFile(clusterName string, provider Provider, clusterType ClusterType) (tanzu.TanzuConfig, string, error) {
+	tanzuConfig := r.GetTanzuConfig(provider, clusterName, clusterType)
+	configFilePath, err := ClusterConfigFilePath(r.Logger.FilePath(), clusterName)
+	if err != nil {
+		return nil, "", err
+	}
+	err = tanzuConfig.WriteFile(configFilePath, provider.SecretEnvVars())
+	if err != nil {
+		return nil, "", err
+	}
+	r.Logger.Infof("Cluster config of %s written to %s", clusterName, configFilePath)
+	return tanzuConfig, configFilePath, nil
+}
+
+// RunCluster creates the cluster using a cluster config file, which is kept next to the log file of the test run
+// so that the cluster creation can be reproduced. Only the config variables with the provider's secret environment
+// variables are passed as environment variables
+func (r DefaultClusterTestRunner) RunCluster(ctx context.Context, clusterName string, provider Provider, clusterType ClusterType) error {
+	tanzuConfig, configFilePath, err := r.writeClusterConfigFile(clusterName, provider, clusterType)
+	if err != nil {
+		return err
+	}
+
+	envVars := tanzu.TanzuConfigToEnvVars(tanzuConfig.Secrets(provider.SecretEnvVars()))
+	result, err := clirunner.RunContext(ctx, clirunner.Cmd{
 		Name: "tanzu",
 		Args: []string{
 			clusterType.TanzuCommand(),
 			"create",
 			clusterName,
+			"--file",
+			configFilePath,
 			// TODO: Should we add verbosity flag and value by default? or
 			// let the user define the verbosity when running the tests maybe?
 			// "-v",
 			// "10",
 		},
-		Env:    append(os.Environ(), envVars...),
-		Stdout: log.InfoWriter,
-		// TODO: Should we log standard errors as errors in the log? Because tanzu prints other information also
-		// to standard error, which are kind of like information, apart from actual errors, so showing
-		// everything as error is misleading. Gotta think what to do about this. The main problem is
-		// console has only standard output and standard error, and tanzu is using standard output only for
-		// giving output for things like --dry-run when it needs to print yaml content, but everything else
-		// is printed to standard error
-		Stderr: log.ErrorWriter,
+		Env:              append(r.GetEnv(), envVars...),
+		Stdout:           r.Logger.InfoWriter(),
+		StderrClassifier: log.DefaultStreamClassifier(),
+		Timeout:          clusterCreationTimeout,
+		Logger:           r.Logger,
 	})
 	if err != nil {
-		return fmt.Errorf("error occurred while deploying %v. exit code: %v. error: %v", clusterName, exitCode, err)
+		return fmt.Errorf("error occurred while deploying %v. exit code: %v. error: %w. failure summary:\n%s", clusterName, result.ExitCode, err, result.FailureSummary())
 	}
 	return nil
 }
 
-func (r DefaultClusterTestRunner) GetClusterKubeConfig(clusterName string, provider Provider, clusterType ClusterType) {
+// DryRunCluster renders the manifest of the cluster using `tanzu ... create --dry-run` with the cluster config
+// file of the cluster, without creating any infrastructure. The manifest is kept next to the log file of the test
+// run, with the secrets redacted, and returned as is
+func (r DefaultClusterTestRunner) DryRunCluster(ctx context.Context, clusterName string, provider Provider, clusterType ClusterType) ([]byte, error) {
+	tanzuConfig, configFilePath, err := r.writeClusterConfigFile(clusterName, provider, clusterType)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest bytes.Buffer
+	envVars := tanzu.TanzuConfigToEnvVars(tanzuConfig.Secrets(provider.SecretEnvVars()))
+	result, err := clirunner.RunContext(ctx, clirunner.Cmd{
+		Name: "tanzu",
+		Args: []string{
+			clusterType.TanzuCommand(),
+			"create",
+			clusterName,
+			"--file",
+			configFilePath,
+			"--dry-run",
+		},
+		Env:              append(r.GetEnv(), envVars...),
+		Stdout:           &manifest,
+		StderrClassifier: log.DefaultStreamClassifier(),
+		Timeout:          clusterDryRunTimeout,
+		Logger:           r.Logger,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error occurred while rendering manifest of %v. exit code: %v. error: %w. failure summary:\n%s", clusterName, result.ExitCode, err, result.FailureSummary())
+	}
+
+	manifestFilePath, err := ClusterManifestFilePath(r.Logger.FilePath(), clusterName)
+	if err != nil {
+		return nil, err
+	}
+	err = os.WriteFile(manifestFilePath, []byte(log.Redact(manifest.String())), 0644)
+	if err != nil {
+		return nil, fmt.Errorf("error while writing manifest of %s to %s: %v", clusterName, manifestFilePath, err)
+	}
+	r.Logger.Infof("Manifest of %s written to %s", clusterName, manifestFilePath)
+
+	return manifest.Bytes(), nil
+}
+
+func (r DefaultClusterTestRunner) GetClusterKubeConfig(clusterName string, provider Provider, clusterType ClusterType) error {
 	// TODO: Do we really need the secrets here?
-	envVars := tanzu.TanzuConfigToEnvVars(provider.GetTanzuConfig(clusterName))
-	exitCode, err := clirunner.Run(clirunner.Cmd{
+	envVars := tanzu.TanzuConfigToEnvVars(r.GetTanzuConfig(provider, clusterName, clusterType))
+	result, err := clirunner.Run(clirunner.Cmd{
 		// TODO: Replace magic strings like "tanzu", "management-cluster" etc
 		Name: "tanzu",
 		Args: []string{
@@ -120,20 +286,16 @@ func (r DefaultClusterTestRunner) GetClusterKubeConfig(clusterName string, provi
 			// "-v",
 			// "9",
 		},
-		Env:    append(os.Environ(), envVars...),
-		Stdout: log.InfoWriter,
-		// TODO: Should we log standard errors as errors in the log? Because tanzu prints other information also
-		// to standard error, which are kind of like information, apart from actual errors, so showing
-		// everything as error is misleading. Gotta think what to do about this. The main problem is
-		// console has only standard output and standard error, and tanzu is using standard output only for
-		// giving output for things like --dry-run when it needs to print yaml content, but everything else
-		// is printed to standard error
-		Stderr: log.ErrorWriter,
+		Env:              append(r.GetEnv(), envVars...),
+		Stdout:           r.Logger.InfoWriter(),
+		StderrClassifier: log.DefaultStreamClassifier(),
+		Logger:           r.Logger,
 	})
 
 	if err != nil {
-		log.Fatalf("Error occurred while getting %v kubeconfig. Exit code: %v. Error: %v", clusterName, exitCode, err)
+		return fmt.Errorf("error occurred while getting %v kubeconfig. exit code: %v. error: %w. failure summary:\n%s", clusterName, result.ExitCode, err, result.FailureSummary())
 	}
+	return nil
 }
 
 func (r DefaultClusterTestRunner) PrintClusterInformation(kubeConfigPath string, kubeContext string) error {
@@ -147,7 +309,7 @@ func (r DefaultClusterTestRunner) PrintClusterInformation(kubeConfigPath string,
 		return fmt.Errorf("error getting kubernetes api server version: %v", err)
 	}
 
-	log.Infof("Kubernetes API server version is %s", versionInfo.String())
+	r.Logger.Infof("Kubernetes API server version is %s", versionInfo.String())
 
 	// TODO: Should we get exact details as `kubectl get pod -A`? Showing age, restart count, how many containers are ready,
 	// pod's phase (running) etc
@@ -159,10 +321,10 @@ func (r DefaultClusterTestRunner) PrintClusterInformation(kubeConfigPath string,
 
 	// TODO: Should we check pods.RemainingItemCount value to see if it is 0 to ensure we have got all the pods?
 
-	log.Info("Pod Name\tPod Namespace\tPod Phase")
+	r.Logger.Info("Pod Name\tPod Namespace\tPod Phase")
 	for _, pod := range pods.Items {
 		// TODO: Use some library to print / format in some sort of table format? With proper spacing
-		log.Infof("%s\t%s\t%s", pod.Name, pod.Namespace, pod.Status.Phase)
+		r.Logger.Infof("%s\t%s\t%s", pod.Name, pod.Namespace, pod.Status.Phase)
 	}
 
 	nodes, err := client.GetAllNodes()
@@ -170,31 +332,46 @@ func (r DefaultClusterTestRunner) PrintClusterInformation(kubeConfigPath string,
 		return fmt.Errorf("error getting all nodes: %v", err)
 	}
 
-	log.Info("\n\nNode Name\tNode Phase")
+	r.Logger.Info("\n\nNode Name\tNode Phase")
 	for _, node := range nodes.Items {
 		// TODO: There is some issue here, node.Status.Phase gives empty string I think
-		log.Infof("%s\t%s", node.Name, node.Status.Phase)
+		r.Logger.Infof("%s\t%s", node.Name, node.Status.Phase)
 	}
 
 	return nil
 }
 
-func (r DefaultClusterTestRunner) CheckWorkloadClusterIsRunning(workloadClusterName string) error {
-	// TODO: Should this function use a loop and wait (with timeout) for workload cluster to show up in the list
-	// of workload clusters and be in running state? Or will Tanzu exit workload cluster creation
-	// command only when workload cluster shows up in the list and is in running state? Gotta check
-	workloadClusters, err := listWorkloadClusters()
+// CheckWorkloadClusterIsRunning waits for the CAPI objects of the workload cluster in the management cluster - the
+// Cluster, KubeadmControlPlane, MachineDeployments and Machines - to be ready, and then checks that the workload
+// cluster is running in the list of workload clusters of the tanzu CLI
+func (r DefaultClusterTestRunner) CheckWorkloadClusterIsRunning(ctx context.Context, managementClusterName string, workloadClusterName string) error {
+	managementClusterClient, err := r.managementClusterClient(managementClusterName)
 	if err != nil {
-		return fmt.Errorf("error occurred while listing workload clusters: %v", err)
+		return err
 	}
 
-	isClusterPresent := false
-	clusterStatus := ""
+	options := clusterPollOptions
+	options.Progress = func(attempt poll.Attempt) {
+		r.Logger.Infof("Waiting for workload cluster %s to be ready, %v elapsed: %v", workloadClusterName, attempt.Elapsed.Round(time.Second), attempt.Err)
+	}
+	err = capi.WaitForClusterReady(ctx, managementClusterClient, workloadClusterName, options)
+	if err != nil {
+		return fmt.Errorf("error while waiting for workload cluster %s to be ready: %v", workloadClusterName, err)
+	}
+
+	workloadClusters, err := tanzu.Client{Logger: r.Logger, Env: r.GetEnv()}.ListClusters(ctx)
+	if err != nil {
+		return err
+	}
 
+	isClusterPresent := false
 	for _, workloadCluster := range workloadClusters {
-		if workloadCluster.Name == workloadClusterName {
-			isClusterPresent = true
-			clusterStatus = workloadCluster.Status
+		if workloadCluster.Name != workloadClusterName {
+			continue
+		}
+		isClusterPresent = true
+		if workloadCluster.Status != tanzu.ClusterStatusRunning {
+			return fmt.Errorf("error: workload cluster %s is %s in the list of workload clusters, expected %s", workloadClusterName, workloadCluster.Status, tanzu.ClusterStatusRunning)
 		}
 	}
 
@@ -202,19 +379,42 @@ func (r DefaultClusterTestRunner) CheckWorkloadClusterIsRunning(workloadClusterN
 		return fmt.Errorf("error: workload cluster %s is not present in the list of workload clusters", workloadClusterName)
 	}
 
-	if clusterStatus != "running" {
-		// Return errors for caller to handle maybe? Instead of abrupt stop?
-		return fmt.Errorf("error: workload cluster %s is not in running status, it is in %s status", workloadClusterName, clusterStatus)
+	r.Logger.Infof("Workload cluster %s is running successfully\n", workloadClusterName)
+	return nil
+}
+
+// FindManagementCluster returns the name of the existing management cluster known to the tanzu CLI which has the
+// name or the kube context
+func (r DefaultClusterTestRunner) FindManagementCluster(nameOrKubeContext string) (string, error) {
+	return tanzu.FindManagementCluster(r.GetEnv(), nameOrKubeContext)
+}
+
+// LoginToManagementCluster makes the tanzu CLI use the existing management cluster for the workload clusters
+func (r DefaultClusterTestRunner) LoginToManagementCluster(managementClusterName string) error {
+	return tanzu.LoginToManagementCluster(r.Logger, r.GetEnv(), managementClusterName)
+}
+
+// CheckManagementClusterIsHealthy checks that the CAPI objects of the management cluster in the management cluster
+// itself - the Cluster, KubeadmControlPlane, MachineDeployments and Machines - are ready
+func (r DefaultClusterTestRunner) CheckManagementClusterIsHealthy(ctx context.Context, managementClusterName string) error {
+	managementClusterClient, err := r.managementClusterClient(managementClusterName)
+	if err != nil {
+		return err
+	}
+
+	err = capi.CheckClusterReady(ctx, managementClusterClient, managementClusterName)
+	if err != nil {
+		return fmt.Errorf("management cluster %s is not healthy: %v", managementClusterName, err)
 	}
 
-	log.Infof("Workload cluster %s is running successfully\n", workloadClusterName)
+	r.Logger.Infof("Management cluster %s is healthy", managementClusterName)
 	return nil
 }
 
-func (r DefaultClusterTestRunner) DeleteCluster(clusterName string, provider Provider, clusterType ClusterType) error {
+func (r DefaultClusterTestRunner) DeleteCluster(ctx context.Context, clusterName string, provider Provider, clusterType ClusterType) error {
 	// TODO: Do we really need the  secrets here?
-	envVars := tanzu.TanzuConfigToEnvVars(provider.GetTanzuConfig(clusterName))
-	exitCode, err := clirunner.Run(clirunner.Cmd{
+	envVars := tanzu.TanzuConfigToEnvVars(r.GetTanzuConfig(provider, clusterName, clusterType))
+	result, err := clirunner.RunContext(ctx, clirunner.Cmd{
 		Name: "tanzu",
 		Args: []string{
 			clusterType.TanzuCommand(),
@@ -226,60 +426,61 @@ func (r DefaultClusterTestRunner) DeleteCluster(clusterName string, provider Pro
 			// "-v",
 			// "10",
 		},
-		Env:    append(os.Environ(), envVars...),
-		Stdout: log.InfoWriter,
-		// TODO: Should we log standard errors as errors in the log? Because tanzu prints other information also
-		// to standard error, which are kind of like information, apart from actual errors, so showing
-		// everything as error is misleading. Gotta think what to do about this. The main problem is
-		// console has only standard output and standard error, and tanzu is using standard output only for
-		// giving output for things like --dry-run when it needs to print yaml content, but everything else
-		// is printed to standard error
-		Stderr: log.ErrorWriter,
+		Env:              append(r.GetEnv(), envVars...),
+		Stdout:           r.Logger.InfoWriter(),
+		StderrClassifier: log.DefaultStreamClassifier(),
+		Timeout:          clusterDeletionTimeout,
+		Logger:           r.Logger,
 	})
 
 	if err != nil {
-		return fmt.Errorf("error occurred while deleting %v. exit code: %v. error: %v", clusterName, exitCode, err)
+		return fmt.Errorf("error occurred while deleting %v. exit code: %v. error: %w. failure summary:\n%s", clusterName, result.ExitCode, err, result.FailureSummary())
 	}
 
 	return nil
 }
 
-func (r DefaultClusterTestRunner) WaitForWorkloadClusterDeletion(workloadClusterName string) error {
-	// TODO: Use timer for timeout and ticker for polling every few seconds
-	// instead of using sleep
-	for i := 0; i < 60; i++ {
-		workloadClusters, err := listWorkloadClusters()
-		if err != nil {
-			return fmt.Errorf("error occurred while listing workload clusters: %v", err)
-		}
-
-		isClusterPresent := false
+// WaitForWorkloadClusterDeletion waits for the CAPI Cluster of the workload cluster to be deleted from the
+// management cluster
+func (r DefaultClusterTestRunner) WaitForWorkloadClusterDeletion(ctx context.Context, managementClusterName string, workloadClusterName string) error {
+	managementClusterClient, err := r.managementClusterClient(managementClusterName)
+	if err != nil {
+		return err
+	}
 
-		for _, workloadCluster := range workloadClusters {
-			if workloadCluster.Name == workloadClusterName {
-				isClusterPresent = true
-			}
-		}
+	options := clusterPollOptions
+	options.Progress = func(attempt poll.Attempt) {
+		r.Logger.Infof("Waiting for workload cluster %s to get deleted, %v elapsed: %v", workloadClusterName, attempt.Elapsed.Round(time.Second), attempt.Err)
+	}
+	err = capi.WaitForClusterDeletion(ctx, managementClusterClient, workloadClusterName, options)
+	if err != nil {
+		return fmt.Errorf("error while waiting for workload cluster %s to get deleted: %v", workloadClusterName, err)
+	}
 
-		if isClusterPresent {
-			log.Info("Waiting for workload cluster to get deleted")
-		} else {
-			log.Infof("Workload cluster %s successfully deleted\n", workloadClusterName)
-			return nil
-		}
+	r.Logger.Infof("Workload cluster %s successfully deleted\n", workloadClusterName)
+	return nil
+}
 
-		time.Sleep(10 * time.Second)
+// managementClusterClient returns a client of the management cluster, using its kube context
+func (r DefaultClusterTestRunner) managementClusterClient(managementClusterName string) (client.Client, error) {
+	kubeConfigPath, err := r.GetKubeConfigPath()
+	if err != nil {
+		return nil, fmt.Errorf("error while getting kubeconfig path: %v", err)
 	}
 
-	return fmt.Errorf("timed out waiting for workload cluster %s to get deleted", workloadClusterName)
+	managementClusterClient, err := kubeclient.GetControllerRuntimeClient(kubeConfigPath, r.GetKubeContextForTanzuCluster(managementClusterName))
+	if err != nil {
+		return nil, fmt.Errorf("error while getting client of management cluster %s: %v", managementClusterName, err)
+	}
+	return managementClusterClient, nil
 }
 
 func (r DefaultClusterTestRunner) CollectManagementClusterDiagnostics(managementClusterName string) error {
-	return tanzu.CollectManagementClusterDiagnostics(managementClusterName)
+	return tanzu.CollectManagementClusterDiagnostics(r.Logger, r.GetEnv(), managementClusterName)
 }
 
 func (r DefaultClusterTestRunner) CollectManagementClusterAndWorkloadClusterDiagnostics(managementClusterName string, workloadClusterName string, workloadClusterInfra string) error {
-	return tanzu.CollectManagementClusterAndWorkloadClusterDiagnostics(managementClusterName, workloadClusterName, workloadClusterInfra)
+	return tanzu.CollectManagementClusterAndWorkloadClusterDiagnostics(r.Logger, r.GetEnv(), managementClusterName, workloadClusterName, workloadClusterInfra)
 }
 
 func (r DefaultClusterTestRunner) DeleteContext(kubeConfigPath string, contextName string) error {
@@ -287,7 +488,7 @@ func (r DefaultClusterTestRunner) DeleteContext(kubeConfigPath string, contextNa
 }
 
 func (r DefaultClusterTestRunner) CleanupDockerBootstrapCluster(managementClusterName string) error {
-	bootstrapClusterDockerContainerName, err := tanzu.GetBootstrapClusterDockerContainerNameForManagementCluster(managementClusterName)
+	bootstrapClusterDockerContainerName, err := tanzu.GetBootstrapClusterDockerContainerNameForManagementCluster(r.GetEnv(), managementClusterName)
 	if err != nil {
 		return fmt.Errorf("error getting bootstrap cluster docker container name for the management cluster %s: %v", managementClusterName, err)
 	}
@@ -299,3 +500,20 @@ func (r DefaultClusterTestRunner) CleanupDockerBootstrapCluster(managementCluste
 
 	return nil
 }
+
+func (r DefaultClusterTestRunner) GetLogger() *log.Logger {
+	return r.Logger
+}
+
+// GetEnv returns the environment of the commands run for the test run - the environment of the process, with the
+// home directory, the kubeconfig and the tanzu CLI config of HomeDir when it's set
+func (r DefaultClusterTestRunner) GetEnv() []string {
+	if r.HomeDir == "" {
+		return os.Environ()
+	}
+	return append(os.Environ(), homeDirEnv(r.HomeDir)...)
+}
+
+func (r DefaultClusterTestRunner) GetTanzuVersion() (string, error) {
+	return tanzu.GetTanzuVersion(r.Logger)
+}
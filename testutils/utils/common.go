@@ -1,35 +1,49 @@
 package utils
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
+	"os/signal"
+	"path/filepath"
 	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
 
 	"github.com/karuppiah7890/tce-e2e-test/testutils"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/cleanup"
 	"github.com/karuppiah7890/tce-e2e-test/testutils/clirunner"
 	"github.com/karuppiah7890/tce-e2e-test/testutils/kubeclient"
 	"github.com/karuppiah7890/tce-e2e-test/testutils/log"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/manifest"
 	"github.com/karuppiah7890/tce-e2e-test/testutils/platforms"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/report"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/scenario"
 	"github.com/karuppiah7890/tce-e2e-test/testutils/tanzu"
 	"github.com/karuppiah7890/tce-e2e-test/testutils/tce"
 )
 
 // TODO: Further move the functions to specifics file/libs accordingly
 
+// TCEVersionEnvVarName is the environment variable with the version of TCE being tested, which is recorded in the test run report
+const TCEVersionEnvVarName = "TCE_VERSION"
+
+// JUnitReportDirEnvVarName is the environment variable with the directory to write the JUnit XML reports of the test runs to
+const JUnitReportDirEnvVarName = "JUNIT_REPORT_DIR"
+
+// DryRunEnvVarName is the environment variable which, when set to true, makes the test runs dry runs
+const DryRunEnvVarName = "DRY_RUN"
+
+// ExistingManagementClusterEnvVarName is the environment variable with the name or the kube context of an existing
+// management cluster to run the test runs against, instead of creating and deleting a management cluster
+const ExistingManagementClusterEnvVarName = "EXISTING_MANAGEMENT_CLUSTER"
+
 type ClusterType struct {
 	Name string
 }
 
-type WorkloadCluster struct {
-	Name   string `json:"name"`
-	Status string `json:"status"`
-}
-type WorkloadClusters []WorkloadCluster
-
 var ManagementClusterType = ClusterType{Name: "management-cluster"}
 var WorkloadClusterType = ClusterType{Name: "cluster"}
 
@@ -37,52 +51,60 @@ func (clusterType ClusterType) TanzuCommand() string {
 	return clusterType.Name
 }
 
-func CheckTanzuCLIInstallation() error {
-	log.Info("Checking tanzu CLI installation")
+func CheckTanzuCLIInstallation(logger *log.Logger) error {
+	logger.Info("Checking tanzu CLI installation")
 	path, err := exec.LookPath("tanzu")
 	if err != nil {
-		log.Fatalf("tanzu CLI is not installed")
-		return err
+		return fmt.Errorf("tanzu CLI is not installed: %v", err)
 	}
-	log.Infof("tanzu CLI is available at path: %s", path)
+	logger.Infof("tanzu CLI is available at path: %s", path)
 	return nil
 }
 
-func CheckKubectlCLIInstallation() {
-	log.Info("Checking kubectl CLI installation")
+func CheckKubectlCLIInstallation(logger *log.Logger) error {
+	logger.Info("Checking kubectl CLI installation")
 
 	path, err := exec.LookPath("kubectl")
 	if err != nil {
-		log.Fatalf("kubectl CLI is not installed")
+		return fmt.Errorf("kubectl CLI is not installed: %v", err)
 	}
-	log.Infof("kubectl CLI is available at path: %s\n", path)
+	logger.Infof("kubectl CLI is available at path: %s\n", path)
+	return nil
 }
 
-func CheckTanzuClusterCLIPluginInstallation(clusterType ClusterType) {
-	log.Info("Checking tanzu management cluster plugin CLI installation")
+func CheckTanzuClusterCLIPluginInstallation(logger *log.Logger, clusterType ClusterType) error {
+	logger.Infof("Checking tanzu %s plugin CLI installation", clusterType.TanzuCommand())
 
-	// TODO: Check for errors and return error?
 	// TODO: Parse version and show warning if version is newer than what's tested by the devs while writing test
 	// Refer - https://github.com/karuppiah7890/tce-e2e-test/issues/1#issuecomment-1094172278
-	exitCode, err := clirunner.Run(clirunner.Cmd{
+	result, err := clirunner.Run(clirunner.Cmd{
 		Name: "tanzu",
 		Args: []string{
 			clusterType.TanzuCommand(),
 			"version",
 		},
-		Stdout: log.InfoWriter,
-		// TODO: Should we log standard errors as errors in the log? Because tanzu prints other information also
-		// to standard error, which are kind of like information, apart from actual errors, so showing
-		// everything as error is misleading. Gotta think what to do about this. The main problem is
-		// console has only standard output and standard error, and tanzu is using standard output only for
-		// giving output for things like --dry-run when it needs to print yaml content, but everything else
-		// is printed to standard error
-		Stderr: log.ErrorWriter,
+		Stdout:           logger.InfoWriter(),
+		Logger:           logger,
+		StderrClassifier: log.DefaultStreamClassifier(),
 	})
 
 	if err != nil {
-		log.Fatalf("Error occurred while checking management cluster CLI plugin installation. Exit code: %v. Error: %v", exitCode, err)
+		return fmt.Errorf("tanzu %s plugin CLI is not installed. Exit code: %v. Error: %v", clusterType.TanzuCommand(), result.ExitCode, err)
 	}
+	return nil
+}
+
+// PreflightError is the error of the preflight checks of a test run, with the errors of all the failed checks
+type PreflightError struct {
+	Errors []error
+}
+
+func (e *PreflightError) Error() string {
+	messages := []string{}
+	for _, err := range e.Errors {
+		messages = append(messages, err.Error())
+	}
+	return fmt.Sprintf("%d preflight checks failed: %s", len(e.Errors), strings.Join(messages, "; "))
 }
 
 func GetClusterNodes(kubeConfigPath string, kubeContext string) ([]string, error) {
@@ -104,289 +126,596 @@ func GetClusterNodes(kubeConfigPath string, kubeContext string) ([]string, error
 	return nodesName, nil
 }
 
-func listWorkloadClusters() (WorkloadClusters, error) {
-	var workloadClusters WorkloadClusters
-
-	var clusterListOutput bytes.Buffer
-
-	exitCode, err := clirunner.Run(clirunner.Cmd{
-		Name: "tanzu",
-		Args: []string{
-			"cluster",
-			"list",
-			"-o",
-			"json",
-		},
-		Env:    os.Environ(),
-		Stdout: &clusterListOutput,
-		// TODO: Should we log standard errors as errors in the log? Because tanzu prints other information also
-		// to standard error, which are kind of like information, apart from actual errors, so showing
-		// everything as error is misleading. Gotta think what to do about this. The main problem is
-		// console has only standard output and standard error, and tanzu is using standard output only for
-		// giving output for things like --dry-run when it needs to print yaml content, but everything else
-		// is printed to standard error
-		// TODO: Do we really want to output to log.ErrorWriter ? Is this
-		// data necessary in the logs? This function will be called
-		// a lot of times. The data in log can help development and also
-		// during actual runs to check if there are any errors from the command, hmm
-		Stderr: log.ErrorWriter,
-	})
-
-	if err != nil {
-		return nil, fmt.Errorf("error occurred while listing workload clusters. Exit code: %v. Error: %v", exitCode, err)
+func PlatformSupportCheck(logger *log.Logger) {
+	if runtime.GOOS == platforms.WINDOWS {
+		logger.Warn("Warning: This test has been tested only on Linux and Mac OS till now. Support for Windows has not been tested, so it's experimental and not guaranteed to work!")
 	}
+}
 
-	// TODO: Parse JSON output from the command.
-	// Check if the workload cluster name exists in the list of workload clusters.
-	// Ideally, there should only be one or zero workload clusters. But let's not
-	// think too much on that, for example, someone could create a separate workload
-	// cluster in the meantime while the first one was being created and verified.
-	// This could be done manually from their local machine to test stuff etc
+// CheckRequiredEnvVars checks if the provider's required environment variables are defined and registers
+// the values of its secret environment variables as secrets so that they are scrubbed from the logs
+func CheckRequiredEnvVars(provider Provider) error {
+	requiredEnvVars := provider.RequiredEnvVars()
+	log.RegisterSecretsFromEnvVars(provider.SecretEnvVars()...)
+	errs := testutils.CheckRequiredEnvVars(requiredEnvVars)
 
-	err = json.NewDecoder(&clusterListOutput).Decode(&workloadClusters)
-	if err != nil {
-		return nil, fmt.Errorf("error occurred while decoding JSON containing list of workload clusters. Exit code: %v. Error: %v", exitCode, err)
+	if len(errs) != 0 {
+		fmt.Printf("abcdjjdjdksdm %v", errs)
+		return fmt.Errorf("%v", errs)
 	}
 
-	return workloadClusters, nil
+	return nil
 }
 
-func PlatformSupportCheck() {
-	if runtime.GOOS == platforms.WINDOWS {
-		log.Warn("Warning: This test has been tested only on Linux and Mac OS till now. Support for Windows has not been tested, so it's experimental and not guaranteed to work!")
-	}
+// RunOptions holds the options of a provider test run
+type RunOptions struct {
+	// JUnitReportDir is the directory to write the JUnit XML report of the test run to, with one
+	// test case for each phase of the test run. No JUnit XML report is written when it's empty
+	JUnitReportDir string
+	// TCEVersion is the version of TCE being tested, which is recorded in the test run report
+	TCEVersion string
+	// DryRun only runs the checks and renders the manifests of the clusters with `tanzu ... create --dry-run`,
+	// validates them and saves them next to the log file, without creating any infrastructure
+	DryRun bool
+	// ResumeStateFile is the state file of a test run to resume, which is written next to the log file of every
+	// test run. The resumed test run uses the same clusters, skips the phases which were completed, and cleans
+	// up the resources which were created and not deleted, updating the same state file
+	ResumeStateFile string
+	// ExistingManagementCluster is the name or the kube context of an existing management cluster known to the
+	// tanzu CLI. When it's set, the management cluster is checked to be healthy and used to create the workload
+	// cluster, instead of creating a management cluster, and it's left in place at the end of the test run
+	ExistingManagementCluster string
+	// Lifecycle has the lifecycle operations to run on the workload cluster, like scaling and upgrading it
+	Lifecycle LifecycleOptions
 }
 
-// TODO: Why pass provider if it's only for vSphere?
-func UpdateVars(provider string, clusterType ClusterType) {
-	if provider == "vsphere" {
-		if clusterType == ManagementClusterType {
-			os.Setenv("VSPHERE_CONTROL_PLANE_ENDPOINT", os.Getenv("VSPHERE_MANAGEMENT_CLUSTER_ENDPOINT"))
-		} else if clusterType == WorkloadClusterType {
-			os.Setenv("VSPHERE_CONTROL_PLANE_ENDPOINT", os.Getenv("VSPHERE_WORKLOAD_CLUSTER_ENDPOINT"))
-		}
+// DefaultRunOptions returns the options of a provider test run set using environment variables
+func DefaultRunOptions() RunOptions {
+	dryRun, _ := strconv.ParseBool(os.Getenv(DryRunEnvVarName))
+	return RunOptions{
+		JUnitReportDir:            os.Getenv(JUnitReportDirEnvVarName),
+		TCEVersion:                os.Getenv(TCEVersionEnvVarName),
+		DryRun:                    dryRun,
+		ResumeStateFile:           os.Getenv(ResumeStateFileEnvVarName),
+		ExistingManagementCluster: os.Getenv(ExistingManagementClusterEnvVarName),
+		Lifecycle:                 DefaultLifecycleOptions(),
 	}
+}
 
+// RunProviderTest runs the provider test with the default run options. Refer RunProviderTestWithOptions
+func RunProviderTest(ctx context.Context, provider Provider, r ClusterTestRunner, packageDetails tce.Package) error {
+	return RunProviderTestWithOptions(ctx, provider, r, packageDetails, DefaultRunOptions())
 }
 
-func ManagementClusterCreationFailureTasks(ctx context.Context, r ClusterTestRunner, managementClusterName, kubeConfigPath, managementClusterKubeContext string, provider Provider) {
-	err := r.CollectManagementClusterDiagnostics(managementClusterName)
-	if err != nil {
-		log.Errorf("error while collecting diagnostics of management cluster: %v", err)
+// RunProviderTestWithOptions creates a management cluster and a workload cluster, runs the package test and
+// deletes the clusters. The context bounds the cluster creation and deletion, and it's cancelled when the
+// process is interrupted. Every resource created during the test run registers a cleanup step in a cleanup
+// stack, which is unwound at the end of the test run - on success, failure, panic or interrupt - with each
+// step getting its own timeout, so that the cleanup still runs when the context's deadline is exceeded.
+// Every phase and cleanup step of the test run is recorded in a report which is written as JSON next to
+// the log file, and as JUnit XML when the options have a JUnit report directory. The phases completed and the
+// resources created are tracked in a state file next to the log file, so that a test run which dies partway
+// through can be resumed using the options, or its resources can be torn down using TeardownFromState
+func RunProviderTestWithOptions(ctx context.Context, provider Provider, r ClusterTestRunner, packageDetails tce.Package, options RunOptions) (err error) {
+	runReport := report.New(provider.Name())
+	runReport.SetOutputRecorder(r.GetLogger())
+	cleanups := cleanup.NewStack(r.GetLogger())
+
+	// Interrupts are handled till the cleanup is done, so that an interrupt stops the running
+	// command but not the cleanup
+	ctx, stopHandlingInterrupts := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stopHandlingInterrupts()
+
+	managementClusterName, workloadClusterName := "", ""
+	defer func() {
+		cleanupFailures := unwindCleanups(runReport, cleanups)
+		writeReport(r, runReport, options, managementClusterName, workloadClusterName)
+		err = withPhaseFailures(err, cleanupFailures)
+	}()
+
+	// Upgrading TCE upgrades the management cluster, which must not happen to an existing management cluster
+	if options.ExistingManagementCluster != "" && options.Lifecycle.UpgradeTCEVersion != "" {
+		err = fmt.Errorf("upgrading to TCE version %s is not supported with the existing management cluster %s, as the existing management cluster is left as is", options.Lifecycle.UpgradeTCEVersion, options.ExistingManagementCluster)
+		r.GetLogger().Errorf("%v", err)
+		return err
 	}
 
-	err = r.CleanupDockerBootstrapCluster(managementClusterName)
+	runState, err := newRunState(provider, r, options)
 	if err != nil {
-		log.Errorf("error while cleaning up docker bootstrap cluster of the management cluster: %v", err)
+		r.GetLogger().Errorf("error while creating the state of the test run: %v", err)
+		return err
 	}
+	resuming := options.ResumeStateFile != ""
+	cleanups.SetTracker(runState)
 
-	err = r.DeleteContext(kubeConfigPath, managementClusterKubeContext)
-	if err != nil {
-		log.Errorf("error while deleting kube context %s at kubeconfig path: %v", managementClusterKubeContext, err)
+	managementClusterName, workloadClusterName = runState.ManagementClusterName, runState.WorkloadClusterName
+	runReport.SetClusterNames(managementClusterName, workloadClusterName)
+	runReport.SetStateFile(runState.Path())
+
+	// The cleanup steps of the clusters are released once the clusters are deleted by the test run
+	var managementClusterCleanup, workloadClusterCleanup *cleanup.Step
+
+	packageTestSkipReason := ""
+	if packageDetails.Name == "" {
+		packageTestSkipReason = "no package to test"
 	}
 
-	err = provider.CleanupCluster(ctx, managementClusterName)
-	if err != nil {
-		log.Errorf("error while cleaning up the management cluster: %v", err)
+	managementClusterPhase := Phase{
+		Name:      report.PhaseManagementClusterCreate,
+		DependsOn: []string{report.PhaseChecks},
+		Run: func() error {
+			var err error
+			managementClusterCleanup, err = createManagementCluster(ctx, provider, r, cleanups, managementClusterName)
+			return err
+		},
+	}
+	managementClusterDeleteSkipReason := ""
+	if options.ExistingManagementCluster != "" {
+		managementClusterPhase = Phase{
+			Name:      report.PhaseManagementClusterCheck,
+			DependsOn: []string{report.PhaseChecks},
+			Run: func() error {
+				return useExistingManagementCluster(ctx, provider, r, managementClusterName)
+			},
+		}
+		managementClusterDeleteSkipReason = "the existing management cluster is left in place"
+	}
+
+	// TODO: Consider testing one basic package or we can do this separately or have
+	// a feature flag to test it when needed and skip it when not needed.
+	// This will give us an idea of how testing packages looks like and give an example
+	// to TCE package owners
+	phases := []Phase{
+		{
+			Name: report.PhaseChecks,
+			Run: func() error {
+				err := setupEnv(provider, r, runReport, cleanups, options, managementClusterName, workloadClusterName)
+				if err != nil || !resuming {
+					return err
+				}
+
+				// The resources of the test run being resumed are cleaned up like the ones created from now on
+				clusterCleanups, err := restoreCleanupSteps(cleanups, provider, r, runState)
+				if err != nil {
+					return fmt.Errorf("error while restoring the cleanup steps of the test run being resumed: %v", err)
+				}
+				managementClusterCleanup, workloadClusterCleanup = clusterCleanups[managementClusterName], clusterCleanups[workloadClusterName]
+				return nil
+			},
+		},
+		managementClusterPhase,
+		{
+			Name:      report.PhaseWorkloadClusterCreate,
+			DependsOn: []string{managementClusterPhase.Name},
+			Run: func() error {
+				var err error
+				workloadClusterCleanup, err = createWorkloadCluster(ctx, provider, r, cleanups, managementClusterName, workloadClusterName)
+				return err
+			},
+		},
+		{
+			Name:       report.PhasePackageTest,
+			DependsOn:  []string{report.PhaseWorkloadClusterCreate},
+			SkipReason: packageTestSkipReason,
+			Run: func() error {
+				return runPackageTest(r, packageDetails, workloadClusterName)
+			},
+		},
+	}
+
+	// The lifecycle operations run after the package test, before the workload cluster is deleted
+	phases = append(phases, lifecyclePhases(ctx, r, options.Lifecycle, managementClusterName, workloadClusterName)...)
+
+	phases = append(phases, []Phase{
+		{
+			Name:      report.PhaseWorkloadClusterDelete,
+			DependsOn: []string{report.PhaseWorkloadClusterCreate},
+			Cleanup:   true,
+			Run: func() error {
+				err := deleteWorkloadCluster(ctx, provider, r, workloadClusterName, managementClusterName)
+				if err == nil {
+					workloadClusterCleanup.Release()
+				}
+				return err
+			},
+		},
+		{
+			// The management cluster is cleaned up along with the workload cluster when the
+			// workload cluster creation fails, so it's deleted only when the workload cluster was created
+			Name:       report.PhaseManagementClusterDelete,
+			DependsOn:  []string{report.PhaseWorkloadClusterCreate},
+			Cleanup:    true,
+			SkipReason: managementClusterDeleteSkipReason,
+			Run: func() error {
+				err := deleteManagementCluster(ctx, provider, r, managementClusterName)
+				if err == nil {
+					managementClusterCleanup.Release()
+				}
+				return err
+			},
+		},
+	}...)
+
+	if options.DryRun {
+		phases = dryRunPhases(ctx, provider, r, runReport, cleanups, options, managementClusterName, workloadClusterName)
 	}
-}
 
-func WorkloadClusterCreationFailureTasks(ctx context.Context, r ClusterTestRunner, managementClusterName, workloadClusterName, kubeConfigPath, managementClusterKubeContext, workloadClusterKubeContext string, provider Provider) {
-	err := r.CollectManagementClusterAndWorkloadClusterDiagnostics(managementClusterName, workloadClusterName, provider.Name())
+	err = RunPhases(runReport, trackPhases(r.GetLogger(), runState, phases, resuming))
 	if err != nil {
-		log.Errorf("error while collecting diagnostics of management cluster and workload cluster: %v", err)
+		r.GetLogger().Errorf("provider test run failed: %v", err)
+		return err
 	}
+	return nil
+}
 
-	err = provider.CleanupCluster(ctx, managementClusterName)
+func setupEnv(provider Provider, r ClusterTestRunner, runReport *report.Report, cleanups *cleanup.Stack, options RunOptions, managementClusterName, workloadClusterName string) error {
+	logger := r.GetLogger()
+	err := r.RunChecks()
 	if err != nil {
-		log.Errorf("error while cleaning up the management cluster: %v", err)
+		return err
 	}
 
-	err = r.DeleteContext(kubeConfigPath, managementClusterKubeContext)
+	tfVersion, err := r.GetTanzuVersion()
 	if err != nil {
-		log.Errorf("error while deleting kube context %s at kubeconfig path: %v", managementClusterKubeContext, err)
+		logger.Errorf("error while getting tanzu CLI version: %v", err)
 	}
+	runReport.SetVersions(options.TCEVersion, tfVersion)
 
-	err = provider.CleanupCluster(ctx, workloadClusterName)
+	err = CheckRequiredEnvVars(provider)
 	if err != nil {
-		log.Errorf("error while cleaning up the workload cluster: %v", err)
+		logger.Errorf("errors while checking required environment variables: %v", err)
+		return fmt.Errorf("errors while checking required environment variables: %v", err)
 	}
 
-	err = r.DeleteContext(kubeConfigPath, workloadClusterKubeContext)
+	err = r.CheckConfig(provider)
+	if err == nil {
+		err = checkClusterConfigs(provider, r, managementClusterName, workloadClusterName)
+	}
 	if err != nil {
-		log.Errorf("error while deleting kube context %s at kubeconfig path: %v", managementClusterKubeContext, err)
+		logger.Errorf("error while checking the cluster config: %v", err)
+		return fmt.Errorf("error while checking the cluster config: %v", err)
 	}
-}
 
-func CheckRequiredEnvVars(provider Provider) error {
-	requiredEnvVars := provider.RequiredEnvVars()
-	errs := testutils.CheckRequiredEnvVars(requiredEnvVars)
+	return initProvider(provider, r, cleanups)
+}
 
-	if len(errs) != 0 {
-		fmt.Printf("abcdjjdjdksdm %v", errs)
-		return fmt.Errorf("%v", errs)
+// checkClusterConfigs validates the final tanzu config of both the clusters, with the provider's default config,
+// the scenario's config and the environment variables layered, as it's passed to the tanzu CLI
+func checkClusterConfigs(provider Provider, r ClusterTestRunner, managementClusterName, workloadClusterName string) error {
+	problems := []string{}
+	for _, cluster := range []struct {
+		name        string
+		clusterType ClusterType
+	}{
+		{name: managementClusterName, clusterType: ManagementClusterType},
+		{name: workloadClusterName, clusterType: WorkloadClusterType},
+	} {
+		err := scenario.ValidateTanzuConfig(r.GetTanzuConfig(provider, cluster.name, cluster.clusterType))
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s cluster %s: %v", cluster.clusterType.Name, cluster.name, err))
+		}
 	}
 
+	if len(problems) != 0 {
+		return fmt.Errorf("errors in the cluster config: %s", strings.Join(problems, "; "))
+	}
 	return nil
 }
 
-func RunProviderTest(provider Provider, r ClusterTestRunner, packageDetails tce.Package) error {
-	// Setup
-	setupEnv(provider, r)
-	// Setup Function complete
-	managementClusterName, workloadClusterName := r.GetRandomClusterNames()
-
-	// createManagementCluster function start
-	createManagementCluster(provider, r, managementClusterName)
-	// createManagementCluster Complete
+// dryRunPhases returns the phases of a dry run - the checks, and then rendering and validating the manifests of
+// the clusters. The provider's pre-cluster creation tasks are not run, as they can create infrastructure.
+// Rendering the manifest of the workload cluster needs the tanzu CLI to be logged in to a management cluster, so
+// it's done only with an existing management cluster, which is logged in to first
+func dryRunPhases(ctx context.Context, provider Provider, r ClusterTestRunner, runReport *report.Report, cleanups *cleanup.Stack, options RunOptions, managementClusterName, workloadClusterName string) []Phase {
+	phases := []Phase{
+		{
+			Name: report.PhaseChecks,
+			Run: func() error {
+				return setupEnv(provider, r, runReport, cleanups, options, managementClusterName, workloadClusterName)
+			},
+		},
+		{
+			Name:      report.PhaseManagementClusterDryRun,
+			DependsOn: []string{report.PhaseChecks},
+			Run: func() error {
+				return dryRunCluster(ctx, provider, r, managementClusterName, ManagementClusterType)
+			},
+		},
+	}
 
-	// Create Wkld Cluster Start
-	createWorkloadCluster(provider, r, managementClusterName, workloadClusterName)
-	// Create Wkld Cluster complete
+	workloadClusterDryRunDependencies := []string{report.PhaseChecks}
+	workloadClusterDryRunSkipReason := "no existing management cluster to render the workload cluster manifest with"
+	if options.ExistingManagementCluster != "" {
+		phases = append(phases, Phase{
+			Name:      report.PhaseManagementClusterCheck,
+			DependsOn: []string{report.PhaseChecks},
+			Run: func() error {
+				return useExistingManagementCluster(ctx, provider, r, managementClusterName)
+			},
+		})
+		workloadClusterDryRunDependencies = []string{report.PhaseManagementClusterCheck}
+		workloadClusterDryRunSkipReason = ""
+	}
+
+	return append(phases, Phase{
+		Name:       report.PhaseWorkloadClusterDryRun,
+		DependsOn:  workloadClusterDryRunDependencies,
+		SkipReason: workloadClusterDryRunSkipReason,
+		Run: func() error {
+			return dryRunCluster(ctx, provider, r, workloadClusterName, WorkloadClusterType)
+		},
+	})
+}
 
-	// package Code
-	runPackageTest(r, packageDetails, workloadClusterName)
-	// Package Code complete
+// dryRunCluster renders the manifest of the cluster, validates it and checks it against the policy of the
+// cluster's tanzu config
+func dryRunCluster(ctx context.Context, provider Provider, r ClusterTestRunner, clusterName string, clusterType ClusterType) error {
+	data, err := r.DryRunCluster(ctx, clusterName, provider, clusterType)
+	if err != nil {
+		return fmt.Errorf("error while rendering manifest of %s cluster: %v", clusterName, err)
+	}
 
-	// TODO: Consider testing one basic package or we can do this separately or have
-	// a feature flag to test it when needed and skip it when not needed.
-	// This will give us an idea of how testing packages looks like and give an example
-	// to TCE package owners
+	clusterManifest, err := manifest.Parse(data)
+	if err != nil {
+		return fmt.Errorf("error while parsing manifest of %s cluster: %v", clusterName, err)
+	}
 
-	// TODO: Handle errors during cluster deletion
-	// and cleanup management cluster and then cleanup workload cluster
-
-	// delete Wkld Cluster start
-	deleteWorkloadCluster(provider, r, workloadClusterName, managementClusterName)
-	// Delete wkld cluster complete
-	// TODO: Handle errors during cluster deletion
-	// and cleanup management cluster
-	// Delete mgmt cluster start
-	deleteManagementCluster(provider, r, managementClusterName)
-	// Delete mgmt cluster complete
-	return nil
-}
+	err = clusterManifest.Validate(clusterName)
+	if err != nil {
+		return err
+	}
 
-func setupEnv(provider Provider, r ClusterTestRunner) {
-	r.RunChecks()
-	err := CheckRequiredEnvVars(provider)
+	err = clusterManifest.CheckPolicy(clusterName, manifest.PolicyForConfig(r.GetTanzuConfig(provider, clusterName, clusterType)))
 	if err != nil {
-		log.Errorf("errors while checking required environment variables: %v", err)
+		return err
 	}
-	provider.Init()
+
+	r.GetLogger().Infof("Manifest of %s cluster with %d objects is valid", clusterName, len(clusterManifest.Objects))
+	return nil
 }
-func createManagementCluster(provider Provider, r ClusterTestRunner, managementClusterName string) {
+
+// createManagementCluster creates the management cluster and returns the cleanup step of the cluster
+func createManagementCluster(ctx context.Context, provider Provider, r ClusterTestRunner, cleanups *cleanup.Stack, managementClusterName string) (*cleanup.Step, error) {
+	logger := r.GetLogger()
 	err := provider.PreClusterCreationTasks(managementClusterName, ManagementClusterType)
 	if err != nil {
-		log.Errorf("error while executing pre-cluster creation tasks for %v cluster: %v", managementClusterName, err)
+		return nil, fmt.Errorf("error while executing pre-cluster creation tasks for %v cluster: %v", managementClusterName, err)
 	}
 
 	managementClusterKubeContext := r.GetKubeContextForTanzuCluster(managementClusterName)
 	kubeConfigPath, err := r.GetKubeConfigPath()
 	if err != nil {
-		log.Errorf("error while getting kubeconfig path: %v", err)
+		return nil, fmt.Errorf("error while getting kubeconfig path: %v", err)
 	}
 
-	err = r.RunCluster(managementClusterName, provider, ManagementClusterType)
+	clusterCleanup := pushClusterCleanupSteps(cleanups, provider, r, managementClusterName, kubeConfigPath, managementClusterKubeContext)
+	// The bootstrap cluster is deleted by the tanzu CLI once the management cluster is created
+	bootstrapClusterCleanup, _ := pushResourceCleanupStep(cleanups, provider, r, kubeConfigPath, cleanup.Resource{Kind: ResourceKindBootstrapCluster, Name: managementClusterName})
+
+	err = r.RunCluster(ctx, managementClusterName, provider, ManagementClusterType)
 	if err != nil {
-		runManagementClusterErr := err
-		log.Errorf("error while running management cluster: %v", runManagementClusterErr)
-		ManagementClusterCreationFailureTasks(context.TODO(), r, managementClusterName, kubeConfigPath, managementClusterKubeContext, provider)
-		log.Errorf("error while running management cluster: %v", runManagementClusterErr)
+		logger.Errorf("error while running management cluster: %v", err)
+
+		diagnosticsErr := r.CollectManagementClusterDiagnostics(managementClusterName)
+		if diagnosticsErr != nil {
+			logger.Errorf("error while collecting diagnostics of management cluster: %v", diagnosticsErr)
+		}
+
+		return clusterCleanup, fmt.Errorf("error while running management cluster: %v", err)
 	}
+	bootstrapClusterCleanup.Release()
 
-	// TODO: Handle errors
-	r.GetClusterKubeConfig(managementClusterName, provider, ManagementClusterType)
+	err = r.GetClusterKubeConfig(managementClusterName, provider, ManagementClusterType)
+	if err != nil {
+		return clusterCleanup, fmt.Errorf("error while getting kubeconfig of %s cluster: %v", managementClusterName, err)
+	}
 
-	log.Infof("Management Cluster %s Information: ", managementClusterName)
+	logger.Infof("Management Cluster %s Information: ", managementClusterName)
 	err = r.PrintClusterInformation(kubeConfigPath, managementClusterKubeContext)
 	if err != nil {
 		// Should we panic here and stop?
-		log.Errorf("error while printing management cluster information: %v", err)
+		logger.Errorf("error while printing management cluster information: %v", err)
+	}
+
+	return clusterCleanup, nil
+}
+
+// useExistingManagementCluster makes the tanzu CLI use the existing management cluster, gets its kubeconfig and
+// checks that it's healthy, so that the workload cluster can be created in it
+func useExistingManagementCluster(ctx context.Context, provider Provider, r ClusterTestRunner, managementClusterName string) error {
+	err := r.LoginToManagementCluster(managementClusterName)
+	if err != nil {
+		return fmt.Errorf("error while logging in to management cluster %s: %v", managementClusterName, err)
 	}
+
+	err = r.GetClusterKubeConfig(managementClusterName, provider, ManagementClusterType)
+	if err != nil {
+		return fmt.Errorf("error while getting kubeconfig of %s cluster: %v", managementClusterName, err)
+	}
+
+	return r.CheckManagementClusterIsHealthy(ctx, managementClusterName)
 }
 
-func createWorkloadCluster(provider Provider, r ClusterTestRunner, managementClusterName, workloadClusterName string) {
+// createWorkloadCluster creates the workload cluster and returns the cleanup step of the cluster
+func createWorkloadCluster(ctx context.Context, provider Provider, r ClusterTestRunner, cleanups *cleanup.Stack, managementClusterName, workloadClusterName string) (*cleanup.Step, error) {
+	logger := r.GetLogger()
 	err := provider.PreClusterCreationTasks(workloadClusterName, WorkloadClusterType)
 	if err != nil {
-		log.Errorf("error while executing pre-cluster creation tasks for %v cluster: %v", err, workloadClusterName)
+		return nil, fmt.Errorf("error while executing pre-cluster creation tasks for %v cluster: %v", workloadClusterName, err)
 	}
 
 	workloadClusterKubeContext := r.GetKubeContextForTanzuCluster(workloadClusterName)
 	kubeConfigPath, err := r.GetKubeConfigPath()
-	managementClusterKubeContext := r.GetKubeContextForTanzuCluster(managementClusterName)
-	err = r.RunCluster(workloadClusterName, provider, WorkloadClusterType)
 	if err != nil {
-		runWorkloadClusterErr := err
-		log.Errorf("error while running workload cluster: %v", runWorkloadClusterErr)
+		return nil, fmt.Errorf("error while getting kubeconfig path: %v", err)
+	}
+
+	clusterCleanup := pushClusterCleanupSteps(cleanups, provider, r, workloadClusterName, kubeConfigPath, workloadClusterKubeContext)
+
+	workloadClusterCreationFailed := func(creationErr error) (*cleanup.Step, error) {
+		logger.Errorf("%v", creationErr)
 
-		WorkloadClusterCreationFailureTasks(context.TODO(), r, managementClusterName, workloadClusterName, kubeConfigPath, managementClusterKubeContext, workloadClusterKubeContext, provider)
+		err := r.CollectManagementClusterAndWorkloadClusterDiagnostics(managementClusterName, workloadClusterName, provider.Name())
+		if err != nil {
+			logger.Errorf("error while collecting diagnostics of management cluster and workload cluster: %v", err)
+		}
 
-		log.Errorf("error while running workload cluster: %v", runWorkloadClusterErr)
+		return clusterCleanup, creationErr
 	}
 
-	r.CheckWorkloadClusterIsRunning(workloadClusterName)
+	err = r.RunCluster(ctx, workloadClusterName, provider, WorkloadClusterType)
+	if err != nil {
+		return workloadClusterCreationFailed(fmt.Errorf("error while running workload cluster: %v", err))
+	}
+
+	err = r.CheckWorkloadClusterIsRunning(ctx, managementClusterName, workloadClusterName)
+	if err != nil {
+		return workloadClusterCreationFailed(fmt.Errorf("error while checking if workload cluster is running: %v", err))
+	}
 
-	// TODO: Handle errors
-	r.GetClusterKubeConfig(workloadClusterName, provider, WorkloadClusterType)
+	err = r.GetClusterKubeConfig(workloadClusterName, provider, WorkloadClusterType)
+	if err != nil {
+		return clusterCleanup, fmt.Errorf("error while getting kubeconfig of %s cluster: %v", workloadClusterName, err)
+	}
 
-	log.Infof("Workload Cluster %s Information: ", workloadClusterName)
+	logger.Infof("Workload Cluster %s Information: ", workloadClusterName)
 	err = r.PrintClusterInformation(kubeConfigPath, workloadClusterKubeContext)
 	if err != nil {
 		// Should we panic here and stop?
-		log.Errorf("error while printing workload cluster information: %v", err)
+		logger.Errorf("error while printing workload cluster information: %v", err)
 	}
+
+	return clusterCleanup, nil
+}
+
+// pushClusterCleanupSteps registers the cleanup steps of a cluster which is about to be created - cleaning
+// up the cluster's resources using the provider, like the Azure resource group of the cluster, and deleting
+// the cluster's kube context. It returns the cleanup step of the cluster's resources, to be released once
+// the test run deletes the cluster. The kube context is always deleted, as the tanzu CLI leaves the kube
+// context of workload clusters behind after deleting them
+func pushClusterCleanupSteps(cleanups *cleanup.Stack, provider Provider, r ClusterTestRunner, clusterName, kubeConfigPath, kubeContext string) *cleanup.Step {
+	// Pushing the cleanup steps of the kinds of resources of the test run never fails
+	clusterCleanup, _ := pushResourceCleanupStep(cleanups, provider, r, kubeConfigPath, cleanup.Resource{Kind: ResourceKindCluster, Name: clusterName})
+	_, _ = pushResourceCleanupStep(cleanups, provider, r, kubeConfigPath, cleanup.Resource{Kind: ResourceKindKubeContext, Name: kubeContext})
+
+	return clusterCleanup
 }
 
-func runPackageTest(r ClusterTestRunner, packageDetails tce.Package, workloadClusterName string) {
+func runPackageTest(r ClusterTestRunner, packageDetails tce.Package, workloadClusterName string) error {
+	logger := r.GetLogger()
 	workloadClusterKubeContext := r.GetKubeContextForTanzuCluster(workloadClusterName)
-	if packageDetails.Name != "" {
-		err := tce.PackageE2Etest(packageDetails, workloadClusterKubeContext)
-		if err != nil {
-			// Should we panic here and stop?
-			log.Errorf("error while running e2e test for %v: %v", packageDetails.Name, err)
-		}
+	err := tce.PackageE2Etest(logger, r.GetEnv(), packageDetails, workloadClusterKubeContext)
+	if err != nil {
+		logger.Errorf("error while running e2e test for %v: %v", packageDetails.Name, err)
+		return fmt.Errorf("error while running e2e test for %v: %v", packageDetails.Name, err)
 	}
+	return nil
 }
 
-func deleteWorkloadCluster(provider Provider, r ClusterTestRunner, workloadClusterName, managementClusterName string) {
-	err := r.DeleteCluster(workloadClusterName, provider, WorkloadClusterType)
+func deleteWorkloadCluster(ctx context.Context, provider Provider, r ClusterTestRunner, workloadClusterName, managementClusterName string) error {
+	logger := r.GetLogger()
+	err := r.DeleteCluster(ctx, workloadClusterName, provider, WorkloadClusterType)
 	if err != nil {
-		log.Errorf("error while deleting workload cluster: %v", err)
+		deleteWorkloadClusterErr := err
+		logger.Errorf("error while deleting workload cluster: %v", deleteWorkloadClusterErr)
 
-		err := tanzu.CollectManagementClusterAndWorkloadClusterDiagnostics(managementClusterName, workloadClusterName, provider.Name())
+		err := r.CollectManagementClusterAndWorkloadClusterDiagnostics(managementClusterName, workloadClusterName, provider.Name())
 		if err != nil {
-			log.Errorf("error while collecting diagnostics of management cluster and workload cluster: %v", err)
+			logger.Errorf("error while collecting diagnostics of management cluster and workload cluster: %v", err)
 		}
 
-		log.Errorf("error while deleting workload cluster: %v", err)
+		return fmt.Errorf("error while deleting workload cluster: %v", deleteWorkloadClusterErr)
 	}
 
-	// TODO: Handle errors during waiting for cluster deletion.
-	// We could retry in some cases, to just list the workload clusters.
-	// If all retries fail, cleanup management cluster and then cleanup workload cluster
-	err = r.WaitForWorkloadClusterDeletion(workloadClusterName)
+	// Errors while reading the CAPI Cluster are retried until the polling times out.
+	// TODO: If waiting fails, cleanup management cluster and then cleanup workload cluster
+	err = r.WaitForWorkloadClusterDeletion(ctx, managementClusterName, workloadClusterName)
 	if err != nil {
-		log.Errorf("error while waiting for workload cluster deletion: %v", err)
+		logger.Errorf("error while waiting for workload cluster deletion: %v", err)
+		return fmt.Errorf("error while waiting for workload cluster deletion: %v", err)
 	}
-	kubeConfigPath, err := r.GetKubeConfigPath()
-	workloadClusterKubeContext := r.GetKubeContextForTanzuCluster(workloadClusterName)
-	managementClusterKubeContext := r.GetKubeContextForTanzuCluster(managementClusterName)
-	err = kubeclient.DeleteContext(kubeConfigPath, workloadClusterKubeContext)
+
+	return nil
+}
+
+func deleteManagementCluster(ctx context.Context, provider Provider, r ClusterTestRunner, managementClusterName string) error {
+	logger := r.GetLogger()
+	err := r.DeleteCluster(ctx, managementClusterName, provider, ManagementClusterType)
 	if err != nil {
-		log.Errorf("error while deleting kube context %s at kubeconfig path: %v", managementClusterKubeContext, err)
+		deleteManagementClusterErr := err
+		logger.Errorf("error while deleting management cluster: %v", deleteManagementClusterErr)
+		err := r.CollectManagementClusterDiagnostics(managementClusterName)
+		if err != nil {
+			logger.Errorf("error while collecting diagnostics of management cluster: %v", err)
+		}
+		return fmt.Errorf("error while deleting management cluster: %v", deleteManagementClusterErr)
 	}
+	return nil
+}
+
+// unwindCleanups runs the cleanup steps of the test run and records each of them in the report.
+// It returns the failures of the cleanup steps
+func unwindCleanups(runReport *report.Report, cleanups *cleanup.Stack) []PhaseFailure {
+	failures := []PhaseFailure{}
+
+	_ = cleanups.Unwind(func(name string, run func() error) error {
+		phaseName := fmt.Sprintf("%s: %s", report.PhaseCleanup, name)
+		err := runReport.RunPhase(phaseName, run)
+		if err != nil {
+			failures = append(failures, PhaseFailure{Phase: phaseName, Err: err})
+		}
+		return err
+	})
 
+	return failures
 }
 
-func deleteManagementCluster(provider Provider, r ClusterTestRunner, managementClusterName string) {
-	err := r.DeleteCluster(managementClusterName, provider, ManagementClusterType)
+// writeReport finishes the report of the test run and writes it as JSON next to the log file, and
+// as JUnit XML to the JUnit report directory if there's one in the options
+func writeReport(r ClusterTestRunner, runReport *report.Report, options RunOptions, managementClusterName, workloadClusterName string) {
+	logger := r.GetLogger()
+
+	diagnosticsBundles, err := tanzu.DiagnosticsBundles(managementClusterName, workloadClusterName)
 	if err != nil {
-		log.Errorf("error while deleting management cluster: %v", err)
-		err := tanzu.CollectManagementClusterDiagnostics(managementClusterName)
+		logger.Errorf("error while finding diagnostics bundles: %v", err)
+	}
+	runReport.AddDiagnosticsBundles(diagnosticsBundles...)
+
+	runReport.LogFile = logger.FilePath()
+	if runReport.LogFile != "" {
+		for _, clusterName := range []string{managementClusterName, workloadClusterName} {
+			configFilePath, _ := ClusterConfigFilePath(runReport.LogFile, clusterName)
+			if _, err := os.Stat(configFilePath); err == nil {
+				runReport.AddClusterConfigFiles(configFilePath)
+			}
+			manifestFilePath, _ := ClusterManifestFilePath(runReport.LogFile, clusterName)
+			if _, err := os.Stat(manifestFilePath); err == nil {
+				runReport.AddClusterManifestFiles(manifestFilePath)
+			}
+		}
+	}
+	runReport.Finish()
+
+	if options.JUnitReportDir != "" {
+		junitReportFilePath := filepath.Join(options.JUnitReportDir, fmt.Sprintf("%s-%s.junit.xml", runReport.Provider, managementClusterName))
+		err := runReport.WriteJUnitFile(junitReportFilePath)
 		if err != nil {
-			log.Errorf("error while collecting diagnostics of management cluster: %v", err)
+			logger.Errorf("error while writing the JUnit report of the test run: %v", err)
+		} else {
+			logger.Infof("JUnit report of the test run written to %s", junitReportFilePath)
 		}
-		log.Errorf("error while deleting management cluster: %v", err)
 	}
+
+	if runReport.LogFile == "" {
+		logger.Warn("Not writing the test run report as there is no log file to write it next to")
+		return
+	}
+
+	reportFilePath := report.FilePathForLogFile(runReport.LogFile)
+	err = runReport.WriteFile(reportFilePath)
+	if err != nil {
+		logger.Errorf("error while writing the test run report: %v", err)
+		return
+	}
+	logger.Infof("Test run report written to %s", reportFilePath)
 }
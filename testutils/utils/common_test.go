@@ -1,10 +1,15 @@
 package utils_test
 
 import (
+	"context"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/golang/mock/gomock"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/log"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/scenario"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/tanzu"
 	"github.com/karuppiah7890/tce-e2e-test/testutils/tce"
 	"github.com/karuppiah7890/tce-e2e-test/testutils/utils"
 	"github.com/karuppiah7890/tce-e2e-test/testutils/utils/mock_utils"
@@ -12,7 +17,39 @@ import (
 
 func TestRunProviderTest(t *testing.T) {
 
-	t.Run("when management cluster creation fails it should collect diagnostics", func(t *testing.T) {
+	t.Run("when preflight checks fail it should not create any cluster", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+
+		defer ctrl.Finish()
+
+		provider := mock_utils.NewMockProvider(ctrl)
+		r := mock_utils.NewMockClusterTestRunner(ctrl)
+
+		r.EXPECT().GetLogger().AnyTimes()
+		r.EXPECT().GetTanzuVersion().AnyTimes()
+		provider.EXPECT().Name().Return("mock-infra").AnyTimes()
+
+		preflightErr := &utils.PreflightError{Errors: []error{
+			fmt.Errorf("tanzu CLI is not installed"),
+			fmt.Errorf("kubectl CLI is not installed"),
+		}}
+
+		gomock.InOrder(
+			r.EXPECT().
+				GetRandomClusterNames().Return("test-mgmt", "test-wkld"),
+
+			r.EXPECT().RunChecks().Return(preflightErr),
+		)
+
+		err := utils.RunProviderTest(context.Background(), provider, r, tce.Package{})
+		expectedError := "2 preflight checks failed: tanzu CLI is not installed; kubectl CLI is not installed"
+		if err == nil || err.Error() != expectedError {
+			t.Logf("expected error to be: %v. But got: %v", expectedError, err)
+			t.Fail()
+		}
+	})
+
+	t.Run("when the environment variables make the cluster config invalid it should not create any cluster", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 
 		defer ctrl.Finish()
@@ -20,16 +57,78 @@ func TestRunProviderTest(t *testing.T) {
 		provider := mock_utils.NewMockProvider(ctrl)
 		r := mock_utils.NewMockClusterTestRunner(ctrl)
 
+		r.EXPECT().GetLogger().AnyTimes()
+		r.EXPECT().GetTanzuVersion().AnyTimes()
+		provider.EXPECT().Name().Return("mock-infra").AnyTimes()
+		provider.EXPECT().GetTanzuConfig(gomock.Any()).DoAndReturn(func(clusterName string) tanzu.TanzuConfig {
+			return tanzu.TanzuConfig{"CLUSTER_NAME": clusterName, "AWS_REGION": "us-east-1", "AWS_NODE_AZ": "us-east-1a"}
+		}).AnyTimes()
+
+		testScenario := &scenario.Scenario{
+			Name:     "aws-us-west-2",
+			Provider: "mock-infra",
+			Config:   map[string]string{"AWS_REGION": "us-west-2", "AWS_NODE_AZ": "us-west-2a"},
+		}
+		getTanzuConfig := func(provider utils.Provider, clusterName string, clusterType utils.ClusterType) tanzu.TanzuConfig {
+			return utils.ClusterTanzuConfig(provider, testScenario, clusterName, clusterType)
+		}
+
+		t.Setenv("AWS_REGION", "us-east-1")
+
 		gomock.InOrder(
+			r.EXPECT().
+				GetRandomClusterNames().Return("test-mgmt", "test-wkld"),
+
 			r.EXPECT().RunChecks(),
 
 			provider.EXPECT().RequiredEnvVars(),
 
-			provider.EXPECT().Init(),
+			provider.EXPECT().SecretEnvVars(),
+
+			r.EXPECT().CheckConfig(provider),
+
+			r.EXPECT().GetTanzuConfig(provider, "test-mgmt", utils.ManagementClusterType).DoAndReturn(getTanzuConfig),
 
+			r.EXPECT().GetTanzuConfig(provider, "test-wkld", utils.WorkloadClusterType).DoAndReturn(getTanzuConfig),
+		)
+
+		err := utils.RunProviderTest(context.Background(), provider, r, tce.Package{})
+		expectedError := "AWS_NODE_AZ us-west-2a is not in AWS_REGION us-east-1"
+		if err == nil || !strings.Contains(err.Error(), expectedError) {
+			t.Errorf("expected error to contain: %v. But got: %v", expectedError, err)
+		}
+	})
+
+	t.Run("when management cluster creation fails it should collect diagnostics", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+
+		defer ctrl.Finish()
+
+		provider := mock_utils.NewMockProvider(ctrl)
+		r := mock_utils.NewMockClusterTestRunner(ctrl)
+
+		r.EXPECT().GetLogger().AnyTimes()
+		r.EXPECT().GetTanzuVersion().AnyTimes()
+		provider.EXPECT().Name().Return("mock-infra").AnyTimes()
+
+		gomock.InOrder(
 			r.EXPECT().
 				GetRandomClusterNames().Return("test-mgmt", "test-wkld"),
 
+			r.EXPECT().RunChecks(),
+
+			provider.EXPECT().RequiredEnvVars(),
+
+			provider.EXPECT().SecretEnvVars(),
+
+			r.EXPECT().CheckConfig(provider),
+
+			r.EXPECT().GetTanzuConfig(provider, "test-mgmt", utils.ManagementClusterType),
+
+			r.EXPECT().GetTanzuConfig(provider, "test-wkld", utils.WorkloadClusterType),
+
+			provider.EXPECT().Init(gomock.Any(), gomock.Any(), gomock.Any()),
+
 			provider.EXPECT().PreClusterCreationTasks("test-mgmt", utils.ManagementClusterType),
 
 			r.EXPECT().GetKubeContextForTanzuCluster("test-mgmt").Return("mock-context"),
@@ -37,7 +136,7 @@ func TestRunProviderTest(t *testing.T) {
 			r.EXPECT().GetKubeConfigPath().Return("mock-config-path", nil),
 
 			r.EXPECT().
-				RunCluster("test-mgmt", provider, utils.ManagementClusterType).
+				RunCluster(gomock.Any(), "test-mgmt", provider, utils.ManagementClusterType).
 				Return(fmt.Errorf("some error in management cluster creation")),
 
 			r.EXPECT().CollectManagementClusterDiagnostics("test-mgmt"),
@@ -46,9 +145,9 @@ func TestRunProviderTest(t *testing.T) {
 			provider.EXPECT().CleanupCluster(gomock.Any(), "test-mgmt"),
 		)
 
-		err := utils.RunProviderTest(provider, r, tce.Package{})
+		err := utils.RunProviderTest(context.Background(), provider, r, tce.Package{})
 		expectedError := "error while running management cluster: some error in management cluster creation"
-		if err.Error() != expectedError {
+		if err == nil || err.Error() != expectedError {
 			t.Logf("expected error to be: %v. But got: %v", expectedError, err)
 			t.Fail()
 		}
@@ -62,17 +161,27 @@ func TestRunProviderTest(t *testing.T) {
 		provider := mock_utils.NewMockProvider(ctrl)
 		r := mock_utils.NewMockClusterTestRunner(ctrl)
 
-		provider.EXPECT().Name().Return("mock-infra")
+		r.EXPECT().GetLogger().AnyTimes()
+		r.EXPECT().GetTanzuVersion().AnyTimes()
+		provider.EXPECT().Name().Return("mock-infra").AnyTimes()
 
 		gomock.InOrder(
+			r.EXPECT().
+				GetRandomClusterNames().Return("test-mgmt", "test-wkld"),
+
 			r.EXPECT().RunChecks(),
 
 			provider.EXPECT().RequiredEnvVars(),
 
-			provider.EXPECT().Init(),
+			provider.EXPECT().SecretEnvVars(),
 
-			r.EXPECT().
-				GetRandomClusterNames().Return("test-mgmt", "test-wkld"),
+			r.EXPECT().CheckConfig(provider),
+
+			r.EXPECT().GetTanzuConfig(provider, "test-mgmt", utils.ManagementClusterType),
+
+			r.EXPECT().GetTanzuConfig(provider, "test-wkld", utils.WorkloadClusterType),
+
+			provider.EXPECT().Init(gomock.Any(), gomock.Any(), gomock.Any()),
 
 			provider.EXPECT().PreClusterCreationTasks("test-mgmt", utils.ManagementClusterType),
 
@@ -81,7 +190,7 @@ func TestRunProviderTest(t *testing.T) {
 			r.EXPECT().GetKubeConfigPath().Return("mock-config-path", nil),
 
 			r.EXPECT().
-				RunCluster("test-mgmt", provider, utils.ManagementClusterType),
+				RunCluster(gomock.Any(), "test-mgmt", provider, utils.ManagementClusterType),
 
 			r.EXPECT().GetClusterKubeConfig("test-mgmt", provider, utils.ManagementClusterType),
 
@@ -91,22 +200,365 @@ func TestRunProviderTest(t *testing.T) {
 
 			r.EXPECT().GetKubeContextForTanzuCluster("test-wkld").Return("mock-context-2"),
 
+			r.EXPECT().GetKubeConfigPath().Return("mock-config-path", nil),
+
 			r.EXPECT().
-				RunCluster("test-wkld", provider, utils.WorkloadClusterType).
+				RunCluster(gomock.Any(), "test-wkld", provider, utils.WorkloadClusterType).
 				Return(fmt.Errorf("some error in workload cluster creation")),
 
 			r.EXPECT().CollectManagementClusterAndWorkloadClusterDiagnostics("test-mgmt", "test-wkld", "mock-infra"),
-			provider.EXPECT().CleanupCluster(gomock.Any(), "test-mgmt"),
-			r.EXPECT().DeleteContext("mock-config-path", "mock-context-1"),
-			provider.EXPECT().CleanupCluster(gomock.Any(), "test-wkld"),
+
+			// cleanup steps run in the reverse order of the creation of the resources
 			r.EXPECT().DeleteContext("mock-config-path", "mock-context-2"),
+			provider.EXPECT().CleanupCluster(gomock.Any(), "test-wkld"),
+			r.EXPECT().DeleteContext("mock-config-path", "mock-context-1"),
+			provider.EXPECT().CleanupCluster(gomock.Any(), "test-mgmt"),
 		)
 
-		err := utils.RunProviderTest(provider, r, tce.Package{})
+		err := utils.RunProviderTest(context.Background(), provider, r, tce.Package{})
 		expectedError := "error while running workload cluster: some error in workload cluster creation"
-		if err.Error() != expectedError {
+		if err == nil || err.Error() != expectedError {
 			t.Logf("expected error to be: %v. But got: %v", expectedError, err)
 			t.Fail()
 		}
 	})
+
+	t.Run("when management cluster creation panics it should still run the cleanup steps", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+
+		defer ctrl.Finish()
+
+		provider := mock_utils.NewMockProvider(ctrl)
+		r := mock_utils.NewMockClusterTestRunner(ctrl)
+
+		r.EXPECT().GetLogger().AnyTimes()
+		r.EXPECT().GetTanzuVersion().AnyTimes()
+		provider.EXPECT().Name().Return("mock-infra").AnyTimes()
+
+		gomock.InOrder(
+			r.EXPECT().
+				GetRandomClusterNames().Return("test-mgmt", "test-wkld"),
+
+			r.EXPECT().RunChecks(),
+
+			provider.EXPECT().RequiredEnvVars(),
+
+			provider.EXPECT().SecretEnvVars(),
+
+			r.EXPECT().CheckConfig(provider),
+
+			r.EXPECT().GetTanzuConfig(provider, "test-mgmt", utils.ManagementClusterType),
+
+			r.EXPECT().GetTanzuConfig(provider, "test-wkld", utils.WorkloadClusterType),
+
+			provider.EXPECT().Init(gomock.Any(), gomock.Any(), gomock.Any()),
+
+			provider.EXPECT().PreClusterCreationTasks("test-mgmt", utils.ManagementClusterType),
+
+			r.EXPECT().GetKubeContextForTanzuCluster("test-mgmt").Return("mock-context"),
+
+			r.EXPECT().GetKubeConfigPath().Return("mock-config-path", nil),
+
+			r.EXPECT().
+				RunCluster(gomock.Any(), "test-mgmt", provider, utils.ManagementClusterType).
+				Do(func(ctx context.Context, clusterName string, provider utils.Provider, clusterType utils.ClusterType) {
+					panic("some panic in management cluster creation")
+				}),
+
+			r.EXPECT().CleanupDockerBootstrapCluster("test-mgmt"),
+			r.EXPECT().DeleteContext("mock-config-path", "mock-context"),
+			provider.EXPECT().CleanupCluster(gomock.Any(), "test-mgmt"),
+		)
+
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("expected the panic to be propagated after the cleanup")
+			}
+		}()
+
+		_ = utils.RunProviderTest(context.Background(), provider, r, tce.Package{})
+	})
+
+	t.Run("when it's a dry run without an existing management cluster it should validate only the manifest of the management cluster", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+
+		defer ctrl.Finish()
+
+		provider := mock_utils.NewMockProvider(ctrl)
+		r := mock_utils.NewMockClusterTestRunner(ctrl)
+
+		r.EXPECT().GetLogger().AnyTimes()
+		r.EXPECT().GetTanzuVersion().AnyTimes()
+		provider.EXPECT().Name().Return("mock-infra").AnyTimes()
+
+		clusterManifest := func(clusterName string) []byte {
+			return []byte(fmt.Sprintf(`apiVersion: cluster.x-k8s.io/v1beta1
+kind: Cluster
+metadata:
+  name: %s
+spec:
+  clusterNetwork:
+    pods:
+      cidrBlocks:
+      - 100.96.0.0/11
+    services:
+      cidrBlocks:
+      - 100.64.0.0/13
+`, clusterName))
+		}
+
+		gomock.InOrder(
+			r.EXPECT().
+				GetRandomClusterNames().Return("test-mgmt", "test-wkld"),
+
+			r.EXPECT().RunChecks(),
+
+			provider.EXPECT().RequiredEnvVars(),
+
+			provider.EXPECT().SecretEnvVars(),
+
+			r.EXPECT().CheckConfig(provider),
+
+			r.EXPECT().GetTanzuConfig(provider, "test-mgmt", utils.ManagementClusterType),
+
+			r.EXPECT().GetTanzuConfig(provider, "test-wkld", utils.WorkloadClusterType),
+
+			provider.EXPECT().Init(gomock.Any(), gomock.Any(), gomock.Any()),
+
+			r.EXPECT().
+				DryRunCluster(gomock.Any(), "test-mgmt", provider, utils.ManagementClusterType).
+				Return(clusterManifest("test-mgmt"), nil),
+
+			r.EXPECT().
+				GetTanzuConfig(provider, "test-mgmt", utils.ManagementClusterType).
+				Return(tanzu.TanzuConfig{"CLUSTER_PLAN": "dev"}),
+		)
+
+		err := utils.RunProviderTestWithOptions(context.Background(), provider, r, tce.Package{}, utils.RunOptions{DryRun: true})
+		if err != nil {
+			t.Errorf("expected no error but got: %v", err)
+		}
+	})
+
+	t.Run("when it's a dry run with an existing management cluster it should log in to it and validate the manifests of the clusters without creating them", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+
+		defer ctrl.Finish()
+
+		provider := mock_utils.NewMockProvider(ctrl)
+		r := mock_utils.NewMockClusterTestRunner(ctrl)
+
+		r.EXPECT().GetLogger().AnyTimes()
+		r.EXPECT().GetTanzuVersion().AnyTimes()
+		provider.EXPECT().Name().Return("mock-infra").AnyTimes()
+
+		clusterManifest := func(clusterName string) []byte {
+			return []byte(fmt.Sprintf(`apiVersion: cluster.x-k8s.io/v1beta1
+kind: Cluster
+metadata:
+  name: %s
+spec:
+  clusterNetwork:
+    pods:
+      cidrBlocks:
+      - 100.96.0.0/11
+    services:
+      cidrBlocks:
+      - 100.64.0.0/13
+`, clusterName))
+		}
+
+		gomock.InOrder(
+			r.EXPECT().
+				GetRandomClusterNames().Return("test-mgmt", "test-wkld"),
+
+			r.EXPECT().
+				FindManagementCluster("existing-mgmt").Return("existing-mgmt", nil),
+
+			r.EXPECT().RunChecks(),
+
+			provider.EXPECT().RequiredEnvVars(),
+
+			provider.EXPECT().SecretEnvVars(),
+
+			r.EXPECT().CheckConfig(provider),
+
+			r.EXPECT().GetTanzuConfig(provider, "existing-mgmt", utils.ManagementClusterType),
+
+			r.EXPECT().GetTanzuConfig(provider, "test-wkld", utils.WorkloadClusterType),
+
+			provider.EXPECT().Init(gomock.Any(), gomock.Any(), gomock.Any()),
+
+			r.EXPECT().
+				DryRunCluster(gomock.Any(), "existing-mgmt", provider, utils.ManagementClusterType).
+				Return(clusterManifest("existing-mgmt"), nil),
+
+			r.EXPECT().
+				GetTanzuConfig(provider, "existing-mgmt", utils.ManagementClusterType).
+				Return(tanzu.TanzuConfig{"CLUSTER_PLAN": "dev"}),
+
+			r.EXPECT().LoginToManagementCluster("existing-mgmt"),
+
+			r.EXPECT().GetClusterKubeConfig("existing-mgmt", provider, utils.ManagementClusterType),
+
+			r.EXPECT().CheckManagementClusterIsHealthy(gomock.Any(), "existing-mgmt"),
+
+			r.EXPECT().
+				DryRunCluster(gomock.Any(), "test-wkld", provider, utils.WorkloadClusterType).
+				Return(clusterManifest("existing-mgmt"), nil),
+		)
+
+		err := utils.RunProviderTestWithOptions(context.Background(), provider, r, tce.Package{}, utils.RunOptions{DryRun: true, ExistingManagementCluster: "existing-mgmt"})
+		if err == nil {
+			t.Fatalf("expected an error but got none")
+		}
+		expectedError := "invalid manifest of cluster test-wkld: Cluster object is named existing-mgmt, expected test-wkld"
+		if !strings.Contains(err.Error(), expectedError) {
+			t.Errorf("expected error to contain: %v. But got: %v", expectedError, err)
+		}
+	})
+
+	t.Run("when using an existing management cluster it should create and delete only the workload cluster", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+
+		defer ctrl.Finish()
+
+		provider := mock_utils.NewMockProvider(ctrl)
+		r := mock_utils.NewMockClusterTestRunner(ctrl)
+
+		r.EXPECT().GetLogger().AnyTimes()
+		r.EXPECT().GetTanzuVersion().AnyTimes()
+		provider.EXPECT().Name().Return("mock-infra").AnyTimes()
+
+		gomock.InOrder(
+			r.EXPECT().
+				GetRandomClusterNames().Return("test-mgmt", "test-wkld"),
+
+			r.EXPECT().
+				FindManagementCluster("existing-mgmt-admin@existing-mgmt").Return("existing-mgmt", nil),
+
+			r.EXPECT().RunChecks(),
+
+			provider.EXPECT().RequiredEnvVars(),
+
+			provider.EXPECT().SecretEnvVars(),
+
+			r.EXPECT().CheckConfig(provider),
+
+			r.EXPECT().GetTanzuConfig(provider, "existing-mgmt", utils.ManagementClusterType),
+
+			r.EXPECT().GetTanzuConfig(provider, "test-wkld", utils.WorkloadClusterType),
+
+			provider.EXPECT().Init(gomock.Any(), gomock.Any(), gomock.Any()),
+
+			r.EXPECT().LoginToManagementCluster("existing-mgmt"),
+
+			r.EXPECT().GetClusterKubeConfig("existing-mgmt", provider, utils.ManagementClusterType),
+
+			r.EXPECT().CheckManagementClusterIsHealthy(gomock.Any(), "existing-mgmt"),
+
+			provider.EXPECT().PreClusterCreationTasks("test-wkld", utils.WorkloadClusterType),
+
+			r.EXPECT().GetKubeContextForTanzuCluster("test-wkld").Return("mock-context-2"),
+
+			r.EXPECT().GetKubeConfigPath().Return("mock-config-path", nil),
+
+			r.EXPECT().
+				RunCluster(gomock.Any(), "test-wkld", provider, utils.WorkloadClusterType),
+
+			r.EXPECT().CheckWorkloadClusterIsRunning(gomock.Any(), "existing-mgmt", "test-wkld"),
+
+			r.EXPECT().GetClusterKubeConfig("test-wkld", provider, utils.WorkloadClusterType),
+
+			r.EXPECT().PrintClusterInformation("mock-config-path", "mock-context-2"),
+
+			r.EXPECT().DeleteCluster(gomock.Any(), "test-wkld", provider, utils.WorkloadClusterType),
+
+			r.EXPECT().WaitForWorkloadClusterDeletion(gomock.Any(), "existing-mgmt", "test-wkld"),
+
+			// the workload cluster's kube context is left behind by the tanzu CLI, and nothing of the
+			// management cluster is cleaned up
+			r.EXPECT().DeleteContext("mock-config-path", "mock-context-2"),
+		)
+
+		err := utils.RunProviderTestWithOptions(context.Background(), provider, r, tce.Package{}, utils.RunOptions{ExistingManagementCluster: "existing-mgmt-admin@existing-mgmt"})
+		if err != nil {
+			t.Errorf("expected no error but got: %v", err)
+		}
+	})
+
+	t.Run("when the existing management cluster is not healthy it should not create the workload cluster", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+
+		defer ctrl.Finish()
+
+		provider := mock_utils.NewMockProvider(ctrl)
+		r := mock_utils.NewMockClusterTestRunner(ctrl)
+
+		r.EXPECT().GetLogger().AnyTimes()
+		r.EXPECT().GetTanzuVersion().AnyTimes()
+		provider.EXPECT().Name().Return("mock-infra").AnyTimes()
+
+		gomock.InOrder(
+			r.EXPECT().
+				GetRandomClusterNames().Return("test-mgmt", "test-wkld"),
+
+			r.EXPECT().
+				FindManagementCluster("existing-mgmt").Return("existing-mgmt", nil),
+
+			r.EXPECT().RunChecks(),
+
+			provider.EXPECT().RequiredEnvVars(),
+
+			provider.EXPECT().SecretEnvVars(),
+
+			r.EXPECT().CheckConfig(provider),
+
+			r.EXPECT().GetTanzuConfig(provider, "existing-mgmt", utils.ManagementClusterType),
+
+			r.EXPECT().GetTanzuConfig(provider, "test-wkld", utils.WorkloadClusterType),
+
+			provider.EXPECT().Init(gomock.Any(), gomock.Any(), gomock.Any()),
+
+			r.EXPECT().LoginToManagementCluster("existing-mgmt"),
+
+			r.EXPECT().GetClusterKubeConfig("existing-mgmt", provider, utils.ManagementClusterType),
+
+			r.EXPECT().
+				CheckManagementClusterIsHealthy(gomock.Any(), "existing-mgmt").
+				Return(fmt.Errorf("management cluster existing-mgmt is not healthy")),
+		)
+
+		err := utils.RunProviderTestWithOptions(context.Background(), provider, r, tce.Package{}, utils.RunOptions{ExistingManagementCluster: "existing-mgmt"})
+		expectedError := "management cluster existing-mgmt is not healthy"
+		if err == nil || !strings.Contains(err.Error(), expectedError) {
+			t.Errorf("expected error to contain: %v. But got: %v", expectedError, err)
+		}
+	})
+}
+
+func TestCheckRequiredEnvVars(t *testing.T) {
+	t.Run("it should register only the values of the secret environment variables as secrets", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+
+		defer ctrl.Finish()
+
+		provider := mock_utils.NewMockProvider(ctrl)
+		provider.EXPECT().RequiredEnvVars().Return([]string{"MOCK_INFRA_REGION", "MOCK_INFRA_PASSWORD"})
+		provider.EXPECT().SecretEnvVars().Return([]string{"MOCK_INFRA_PASSWORD"})
+
+		t.Setenv("MOCK_INFRA_REGION", "mock-region-1")
+		t.Setenv("MOCK_INFRA_PASSWORD", "mock-infra-s3cr3t")
+
+		err := utils.CheckRequiredEnvVars(provider)
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+
+		redacted := log.Redact("region mock-region-1 password mock-infra-s3cr3t")
+		expected := "region mock-region-1 password " + log.RedactedValue
+		if redacted != expected {
+			t.Errorf("expected %q but got %q", expected, redacted)
+		}
+	})
 }
@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/karuppiah7890/tce-e2e-test/testutils/scenario"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/tanzu"
+)
+
+// Config variables that are set only by the test run, and not by a scenario or an environment variable
+var testRunConfigVariables = map[string]bool{
+	"CLUSTER_NAME":            true,
+	"INFRASTRUCTURE_PROVIDER": true,
+}
+
+// ScenarioRole returns the role of the cluster type in a scenario
+func (clusterType ClusterType) ScenarioRole() scenario.ClusterRole {
+	if clusterType == ManagementClusterType {
+		return scenario.ManagementCluster
+	}
+	return scenario.WorkloadCluster
+}
+
+// ClusterTanzuConfig returns the tanzu config of a cluster in layers - the provider's default config, then
+// the scenario's config when there's a scenario, and then the environment variables named after the config
+// variables, so that a value can be changed for one run without changing the scenario
+func ClusterTanzuConfig(provider Provider, testScenario *scenario.Scenario, clusterName string, clusterType ClusterType) tanzu.TanzuConfig {
+	config := provider.GetTanzuConfig(clusterName)
+
+	if testScenario != nil {
+		for name, value := range testScenario.TanzuConfig(clusterType.ScenarioRole()) {
+			config[name] = value
+		}
+	}
+
+	for name := range config {
+		if testRunConfigVariables[name] {
+			continue
+		}
+		value, ok := os.LookupEnv(name)
+		if ok && value != "" {
+			config[name] = value
+		}
+	}
+
+	return config
+}
+
+// ClusterConfigFilePath returns the path of the cluster config file of the cluster, which is next to the log file of
+// the test run so that it's kept with the other artifacts of the test run. When there's no log file, the cluster
+// config file is in a new temporary directory
+func ClusterConfigFilePath(logFilePath string, clusterName string) (string, error) {
+	return clusterArtifactFilePath(logFilePath, clusterName, "cluster-config.yaml")
+}
+
+// ClusterManifestFilePath returns the path of the manifest of the cluster rendered in dry run mode. Like the
+// cluster config file, it's next to the log file of the test run, or in a new temporary directory
+func ClusterManifestFilePath(logFilePath string, clusterName string) (string, error) {
+	return clusterArtifactFilePath(logFilePath, clusterName, "manifest.yaml")
+}
+
+func clusterArtifactFilePath(logFilePath string, clusterName string, suffix string) (string, error) {
+	if logFilePath != "" {
+		return fmt.Sprintf("%s.%s.%s", strings.TrimSuffix(logFilePath, ".log"), clusterName, suffix), nil
+	}
+
+	dir, err := os.MkdirTemp("", strings.TrimSuffix(suffix, ".yaml"))
+	if err != nil {
+		return "", fmt.Errorf("error while creating directory for %s file: %v", suffix, err)
+	}
+	return filepath.Join(dir, fmt.Sprintf("%s.%s", clusterName, suffix)), nil
+}
@@ -0,0 +1,73 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/scenario"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/tanzu"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/utils"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/utils/mock_utils"
+)
+
+func TestClusterTanzuConfig(t *testing.T) {
+	t.Run("it should override the provider's config with the scenario and then the environment variables", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+
+		defer ctrl.Finish()
+
+		provider := mock_utils.NewMockProvider(ctrl)
+		provider.EXPECT().GetTanzuConfig("test-mgmt").Return(tanzu.TanzuConfig{
+			"CLUSTER_NAME":               "test-mgmt",
+			"CLUSTER_PLAN":               "dev",
+			"AWS_REGION":                 "us-east-1",
+			"AWS_NODE_AZ":                "us-east-1a",
+			"CONTROL_PLANE_MACHINE_TYPE": "m5.xlarge",
+		})
+
+		testScenario := &scenario.Scenario{
+			Name:     "aws-prod",
+			Provider: "aws",
+			Plan:     "prod",
+			Config: map[string]string{
+				"AWS_REGION":  "us-west-2",
+				"AWS_NODE_AZ": "us-west-2a",
+			},
+			Clusters: map[scenario.ClusterRole]map[string]string{
+				scenario.ManagementCluster: {"CONTROL_PLANE_MACHINE_TYPE": "m5.2xlarge"},
+			},
+		}
+
+		t.Setenv("AWS_NODE_AZ", "us-west-2b")
+		t.Setenv("CLUSTER_NAME", "some-other-cluster")
+
+		config := utils.ClusterTanzuConfig(provider, testScenario, "test-mgmt", utils.ManagementClusterType)
+
+		expectedConfig := tanzu.TanzuConfig{
+			"CLUSTER_NAME":               "test-mgmt",
+			"CLUSTER_PLAN":               "prod",
+			"AWS_REGION":                 "us-west-2",
+			"AWS_NODE_AZ":                "us-west-2b",
+			"CONTROL_PLANE_MACHINE_TYPE": "m5.2xlarge",
+		}
+		for name, value := range expectedConfig {
+			if config[name] != value {
+				t.Errorf("expected %s to be %s but got %s", name, value, config[name])
+			}
+		}
+	})
+}
+
+func TestClusterConfigFilePath(t *testing.T) {
+	t.Run("when there is a log file it should be next to the log file", func(t *testing.T) {
+		configFilePath, err := utils.ClusterConfigFilePath("/tmp/2022-05-01-logs/aws-mgmt-wkld-e2e.log", "test-mgmt")
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+
+		expectedConfigFilePath := "/tmp/2022-05-01-logs/aws-mgmt-wkld-e2e.test-mgmt.cluster-config.yaml"
+		if configFilePath != expectedConfigFilePath {
+			t.Errorf("expected cluster config file path to be %s but got %s", expectedConfigFilePath, configFilePath)
+		}
+	})
+}
@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// Maximum time given to the cleanup steps of the resources created during a test run.
+// providerResourceCleanupTimeout is the longest timeout of the cleanup step of a resource created by a provider,
+// like the AWS CloudFormation stack or the vSphere VM folder
+const (
+	clusterCleanupTimeout          = 30 * time.Minute
+	bootstrapClusterCleanupTimeout = 5 * time.Minute
+	kubeContextCleanupTimeout      = 1 * time.Minute
+	providerResourceCleanupTimeout = 15 * time.Minute
+)
+
+// DefaultCleanupMargin is the time kept aside before the test binary's deadline
+// (go test -timeout) for failure handling and cleanup to run. The cleanup steps run one after
+// the other, so it's the sum of the timeouts of the cleanup steps of a test run - the management
+// and workload clusters and their kube contexts, the bootstrap cluster and a provider resource
+const DefaultCleanupMargin = 2*clusterCleanupTimeout + 2*kubeContextCleanupTimeout + bootstrapClusterCleanupTimeout + providerResourceCleanupTimeout
+
+// ContextForTest returns a context which is done cleanupMargin before the test binary's
+// deadline, so that a hung command is stopped while there's still time for failure handling
+// and cleanup, instead of the whole test binary getting killed by go test with no cleanup.
+// When the test binary has no deadline, the context is only done when it's cancelled. The margin
+// is capped at half of the remaining time so that short deadlines still leave time for the test, so
+// the deadline has to be more than twice DefaultCleanupMargin for the whole margin to be kept
+func ContextForTest(t *testing.T, cleanupMargin time.Duration) (context.Context, context.CancelFunc) {
+	deadline, ok := t.Deadline()
+	if !ok {
+		return context.WithCancel(context.Background())
+	}
+
+	if remaining := time.Until(deadline); cleanupMargin > remaining/2 {
+		cleanupMargin = remaining / 2
+	}
+
+	return context.WithDeadline(context.Background(), deadline.Add(-cleanupMargin))
+}
@@ -0,0 +1,10 @@
+package utils
+
+// ResetProviderRegistry removes all the registered providers, so that a test can register providers again when
+// it's run more than once
+func ResetProviderRegistry() {
+	providerRegistry.mutex.Lock()
+	defer providerRegistry.mutex.Unlock()
+
+	providerRegistry.providers = map[string]registeredProvider{}
+}
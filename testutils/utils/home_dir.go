@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/karuppiah7890/tce-e2e-test/testutils/tanzu"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/homedir"
+)
+
+// NewHomeDir creates the home directory of a test run, next to the log file of the test run so that it's kept with
+// the other artifacts of the test run. When there's no log file, the home directory is a new temporary directory.
+// The home directory starts with copies of the tanzu CLI config and the kubeconfig of the user, so that the test
+// run knows the management clusters of the user without changing the files of the user
+func NewHomeDir(logFilePath string) (string, error) {
+	homeDir := fmt.Sprintf("%s.home", strings.TrimSuffix(logFilePath, ".log"))
+	if logFilePath == "" {
+		var err error
+		homeDir, err = os.MkdirTemp("", "home")
+		if err != nil {
+			return "", fmt.Errorf("error while creating home directory: %v", err)
+		}
+	}
+
+	err := os.MkdirAll(filepath.Dir(homeTanzuConfigPath(homeDir)), 0700)
+	if err != nil {
+		return "", fmt.Errorf("error while creating tanzu config directory in home directory %s: %v", homeDir, err)
+	}
+	err = os.MkdirAll(filepath.Dir(homeKubeConfigPath(homeDir)), 0700)
+	if err != nil {
+		return "", fmt.Errorf("error while creating kubeconfig directory in home directory %s: %v", homeDir, err)
+	}
+
+	userTanzuConfigPath, err := tanzu.ClientConfigPath(nil)
+	if err != nil {
+		return "", err
+	}
+	tanzuConfig, err := os.ReadFile(userTanzuConfigPath)
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("error while reading tanzu config %s: %v", userTanzuConfigPath, err)
+	}
+	if err == nil {
+		err = os.WriteFile(homeTanzuConfigPath(homeDir), tanzuConfig, 0600)
+		if err != nil {
+			return "", fmt.Errorf("error while copying tanzu config %s to home directory %s: %v", userTanzuConfigPath, homeDir, err)
+		}
+	}
+
+	// The kubeconfig of the user can be more than one file, which are merged like kubectl does
+	kubeConfigPaths := filepath.SplitList(os.Getenv(clientcmd.RecommendedConfigPathEnvVar))
+	if len(kubeConfigPaths) == 0 {
+		kubeConfigPaths = []string{homeKubeConfigPath(homedir.HomeDir())}
+	}
+	kubeConfig, err := (&clientcmd.ClientConfigLoadingRules{Precedence: kubeConfigPaths}).Load()
+	if err != nil {
+		return "", fmt.Errorf("error while reading kubeconfig: %v", err)
+	}
+	err = clientcmd.WriteToFile(*kubeConfig, homeKubeConfigPath(homeDir))
+	if err != nil {
+		return "", fmt.Errorf("error while copying kubeconfig to home directory %s: %v", homeDir, err)
+	}
+
+	return homeDir, nil
+}
+
+// homeDirEnv returns the environment variables which make the commands use the home directory, along with its
+// kubeconfig and tanzu CLI config. The tanzu CLI plugins and the docker config stay the ones of the user, as they
+// are installed and set up for the user and aren't changed by the test runs
+func homeDirEnv(homeDir string) []string {
+	userHomeDir := homedir.HomeDir()
+	return []string{
+		"HOME=" + homeDir,
+		"KUBECONFIG=" + homeKubeConfigPath(homeDir),
+		"TANZU_CONFIG=" + homeTanzuConfigPath(homeDir),
+		"XDG_CONFIG_HOME=" + envOrDefault("XDG_CONFIG_HOME", filepath.Join(userHomeDir, ".config")),
+		"XDG_DATA_HOME=" + envOrDefault("XDG_DATA_HOME", filepath.Join(userHomeDir, ".local", "share")),
+		"DOCKER_CONFIG=" + envOrDefault("DOCKER_CONFIG", filepath.Join(userHomeDir, ".docker")),
+	}
+}
+
+func homeKubeConfigPath(homeDir string) string {
+	return filepath.Join(homeDir, ".kube", "config")
+}
+
+func homeTanzuConfigPath(homeDir string) string {
+	return filepath.Join(homeDir, ".config", "tanzu", "config.yaml")
+}
+
+// envOrDefault returns the value of the environment variable of the process, or the default value when it's not set
+func envOrDefault(name string, defaultValue string) string {
+	value := os.Getenv(name)
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
@@ -0,0 +1,97 @@
+package utils_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/karuppiah7890/tce-e2e-test/testutils/tanzu"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/utils"
+)
+
+const tanzuConfigWithManagementCluster = `apiVersion: config.tanzu.vmware.com/v1alpha1
+kind: ClientConfig
+metadata:
+  creationTimestamp: null
+current: test-mgmt
+servers:
+- managementClusterOpts:
+    context: test-mgmt-admin@test-mgmt
+    path: /home/tester/.kube-tkg/config
+  name: test-mgmt
+  type: managementcluster
+`
+
+func TestNewHomeDir(t *testing.T) {
+	t.Run("it should create the home directory next to the log file with a copy of the tanzu CLI config of the user", func(t *testing.T) {
+		userHomeDir := t.TempDir()
+		t.Setenv("HOME", userHomeDir)
+		t.Setenv("KUBECONFIG", "")
+		t.Setenv("XDG_DATA_HOME", "")
+		t.Setenv("TANZU_CONFIG", filepath.Join(userHomeDir, "tanzu-config.yaml"))
+		err := os.WriteFile(filepath.Join(userHomeDir, "tanzu-config.yaml"), []byte(tanzuConfigWithManagementCluster), 0600)
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+
+		logFilePath := filepath.Join(t.TempDir(), "aws-e2e.log")
+		homeDir, err := utils.NewHomeDir(logFilePath)
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		if homeDir != strings.TrimSuffix(logFilePath, ".log")+".home" {
+			t.Errorf("expected home directory next to the log file but got %s", homeDir)
+		}
+
+		r := utils.DefaultClusterTestRunner{HomeDir: homeDir}
+		env := r.GetEnv()
+		managementClusterName, err := tanzu.FindManagementCluster(env, "test-mgmt-admin@test-mgmt")
+		if err != nil {
+			t.Fatalf("expected the management cluster of the user to be known but got: %v", err)
+		}
+		if managementClusterName != "test-mgmt" {
+			t.Errorf("expected management cluster test-mgmt but got %s", managementClusterName)
+		}
+
+		tanzuConfigPath, err := tanzu.ClientConfigPath(env)
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		if !strings.HasPrefix(tanzuConfigPath, homeDir) {
+			t.Errorf("expected the tanzu CLI config to be in the home directory %s but got %s", homeDir, tanzuConfigPath)
+		}
+
+		kubeConfigPath, err := r.GetKubeConfigPath()
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		if kubeConfigPath != filepath.Join(homeDir, ".kube", "config") {
+			t.Errorf("expected the kubeconfig to be in the home directory %s but got %s", homeDir, kubeConfigPath)
+		}
+		if _, err := os.Stat(kubeConfigPath); err != nil {
+			t.Errorf("expected the kubeconfig to be created but got: %v", err)
+		}
+		for _, envVar := range []string{"HOME=" + homeDir, "KUBECONFIG=" + kubeConfigPath, "XDG_DATA_HOME=" + filepath.Join(userHomeDir, ".local", "share")} {
+			if !containsEnvVar(env, envVar) {
+				t.Errorf("expected environment to have %s", envVar)
+			}
+		}
+	})
+
+	t.Run("when there's no home directory it should use the environment of the process", func(t *testing.T) {
+		env := utils.DefaultClusterTestRunner{}.GetEnv()
+		if len(env) != len(os.Environ()) {
+			t.Errorf("expected the environment of the process but got %v", env)
+		}
+	})
+}
+
+func containsEnvVar(env []string, envVar string) bool {
+	for _, value := range env {
+		if value == envVar {
+			return true
+		}
+	}
+	return false
+}
@@ -3,18 +3,33 @@ package utils
 import (
 	"context"
 
+	"github.com/karuppiah7890/tce-e2e-test/testutils/cleanup"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/log"
 	"github.com/karuppiah7890/tce-e2e-test/testutils/tanzu"
 )
 
 // Question: Move this to a package named infrastructure?
 // Say something like providers or infra providers? Or it looks weird when calling utils.Provider from a caller perspective
 
+// TanzuConfigFunc returns the tanzu config a cluster of the test run is created with
+type TanzuConfigFunc func(clusterName string, clusterType ClusterType) tanzu.TanzuConfig
+
 // TODO: Change name?
 type Provider interface {
 	Name() string
-	Init() error
+	// Init initializes the provider for a test run. The provider registers the cleanup steps of
+	// the resources it creates during the test run in the cleanup stack, and uses tanzuConfig for
+	// any tasks that need the tanzu config of the clusters, like a dry run of the cluster creation
+	Init(logger *log.Logger, cleanups *cleanup.Stack, tanzuConfig TanzuConfigFunc) error
 	RequiredEnvVars() []string
+	// SecretEnvVars returns the required environment variables which have credentials, like passwords and
+	// access keys. Their values are scrubbed from the logs, the manifests and the cluster config files
+	SecretEnvVars() []string
 	PreClusterCreationTasks(clusterName string, clusterType ClusterType) error
+	// CleanupCluster cleans up the infrastructure resources of the cluster, like the Azure resource group
+	// of the cluster. It runs as a cleanup step when the test run could not delete the cluster
 	CleanupCluster(ctx context.Context, clusterName string) error
+	// GetTanzuConfig returns the default tanzu config of the cluster, which a scenario and environment
+	// variables can override
 	GetTanzuConfig(clusterName string) tanzu.TanzuConfig
 }
@@ -0,0 +1,271 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/karuppiah7890/tce-e2e-test/testutils/capi"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/clirunner"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/kubeclient"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/log"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/manifest"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/poll"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/report"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Environment variables with the lifecycle operations to run on the workload cluster, see LifecycleOptions
+const (
+	// ScaleWorkerCountsEnvVarName has the comma separated worker counts to scale the workload cluster to, like 3,1
+	ScaleWorkerCountsEnvVarName = "SCALE_WORKER_COUNTS"
+	// UpgradeTKrEnvVarName has the TKr to upgrade the workload cluster to, like v1.22.8---vmware.1-tkg.1
+	UpgradeTKrEnvVarName = "UPGRADE_TKR"
+	// UpgradeTCEVersionEnvVarName has the TCE version to install and upgrade the management cluster and the workload
+	// cluster with, like 0.12.0, which is the version after the installed one
+	UpgradeTCEVersionEnvVarName = "UPGRADE_TCE_VERSION"
+	// MachineHealthCheckEnvVarName, when set to true, makes the test check the MachineHealthCheck of the workload cluster
+	MachineHealthCheckEnvVarName = "CHECK_MACHINE_HEALTH_CHECK"
+)
+
+// Maximum time given to each lifecycle operation on the workload cluster, including waiting for its result
+const (
+	clusterScaleTimeout       = 30 * time.Minute
+	clusterUpgradeTimeout     = 90 * time.Minute
+	machineRemediationTimeout = 30 * time.Minute
+)
+
+// LifecycleOptions holds the lifecycle operations to run on the workload cluster once it's created, before it's
+// deleted. Each operation is a phase of the test run, which is skipped when it's not set
+type LifecycleOptions struct {
+	// ScaleWorkerCounts are the worker counts to scale the workload cluster to, one after the other
+	ScaleWorkerCounts []int
+	// UpgradeTKr is the TKr to upgrade the Kubernetes version of the workload cluster to
+	UpgradeTKr string
+	// UpgradeTCEVersion is the TCE version to install over the installed one, before upgrading the management
+	// cluster and the workload cluster with it. The workload cluster is upgraded to the UpgradeTKr if it's set,
+	// or else to the default TKr of the TCE version. It's not supported with an existing management cluster, which
+	// is left as is
+	UpgradeTCEVersion string
+	// MachineHealthCheck deletes the node of a worker Machine of the workload cluster, to check that the
+	// MachineHealthCheck of the workload cluster replaces the Machine
+	MachineHealthCheck bool
+}
+
+// DefaultLifecycleOptions returns the lifecycle operations set using environment variables. Worker counts which
+// are not positive numbers are ignored
+func DefaultLifecycleOptions() LifecycleOptions {
+	workerCounts := []int{}
+	for _, value := range strings.Split(os.Getenv(ScaleWorkerCountsEnvVarName), ",") {
+		workerCount, err := strconv.Atoi(strings.TrimSpace(value))
+		if err == nil && workerCount > 0 {
+			workerCounts = append(workerCounts, workerCount)
+		}
+	}
+	machineHealthCheck, _ := strconv.ParseBool(os.Getenv(MachineHealthCheckEnvVarName))
+
+	return LifecycleOptions{
+		ScaleWorkerCounts:  workerCounts,
+		UpgradeTKr:         os.Getenv(UpgradeTKrEnvVarName),
+		UpgradeTCEVersion:  os.Getenv(UpgradeTCEVersionEnvVarName),
+		MachineHealthCheck: machineHealthCheck,
+	}
+}
+
+// lifecyclePhases returns the phases of the lifecycle operations on the workload cluster - scaling it, replacing
+// a failed worker using its MachineHealthCheck and upgrading it along with TCE, in that order
+func lifecyclePhases(ctx context.Context, r ClusterTestRunner, options LifecycleOptions, managementClusterName, workloadClusterName string) []Phase {
+	phases := []Phase{
+		{
+			Name:       report.PhaseWorkloadClusterScale,
+			DependsOn:  []string{report.PhaseWorkloadClusterCreate},
+			SkipReason: skipReasonIf(len(options.ScaleWorkerCounts) == 0, "no worker counts to scale to"),
+			Run: func() error {
+				for _, workerCount := range options.ScaleWorkerCounts {
+					err := r.ScaleWorkloadCluster(ctx, managementClusterName, workloadClusterName, workerCount)
+					if err != nil {
+						return fmt.Errorf("error while scaling workload cluster to %d workers: %v", workerCount, err)
+					}
+				}
+				return nil
+			},
+		},
+		{
+			Name:       report.PhaseWorkloadClusterMachineHealthCheck,
+			DependsOn:  []string{report.PhaseWorkloadClusterCreate},
+			SkipReason: skipReasonIf(!options.MachineHealthCheck, "MachineHealthCheck is not to be checked"),
+			Run: func() error {
+				err := r.CheckMachineHealthCheckRemediation(ctx, managementClusterName, workloadClusterName)
+				if err != nil {
+					return fmt.Errorf("error while checking MachineHealthCheck of workload cluster: %v", err)
+				}
+				return nil
+			},
+		},
+	}
+	return append(phases, upgradePhases(ctx, r, options, managementClusterName, workloadClusterName)...)
+}
+
+// skipReasonIf returns the skip reason of a phase, which is empty when the phase is not to be skipped
+func skipReasonIf(skip bool, reason string) string {
+	if skip {
+		return reason
+	}
+	return ""
+}
+
+// ScaleWorkloadCluster scales the workers of the workload cluster to the worker count using `tanzu cluster scale`,
+// and waits for the CAPI worker Machines and the nodes of the workload cluster to match the worker count
+func (r DefaultClusterTestRunner) ScaleWorkloadCluster(ctx context.Context, managementClusterName string, workloadClusterName string, workerCount int) error {
+	ctx, cancel := context.WithTimeout(ctx, clusterScaleTimeout)
+	defer cancel()
+
+	err := r.runTanzuCommand(ctx, WorkloadClusterType, clusterScaleTimeout, "scale", workloadClusterName, "--worker-machine-count", strconv.Itoa(workerCount))
+	if err != nil {
+		return err
+	}
+
+	return r.waitForCluster(ctx, managementClusterName, workloadClusterName, clusterScaleTimeout, fmt.Sprintf("to have %d workers", workerCount), func(ctx context.Context, managementClusterClient client.Client, workloadClusterClient *kubeclient.KubeClient) error {
+		err := capi.CheckWorkerMachines(ctx, managementClusterClient, workloadClusterName, int32(workerCount))
+		if err != nil {
+			return err
+		}
+		return workloadClusterClient.CheckNodes(ctx, workerCount, "")
+	})
+}
+
+// UpgradeWorkloadCluster upgrades the Kubernetes version of the workload cluster using `tanzu cluster upgrade`, to
+// the one of the TKr or, when the TKr is empty, to the default one of the installed TCE. It checks that the
+// Kubernetes version changed, and waits for the CAPI objects and the nodes of the workload cluster to be at it
+func (r DefaultClusterTestRunner) UpgradeWorkloadCluster(ctx context.Context, managementClusterName string, workloadClusterName string, tkr string) error {
+	args := []string{"upgrade", workloadClusterName, "--yes"}
+	version := ""
+	if tkr != "" {
+		args = append(args, "--tkr", tkr)
+		version = manifest.KubernetesVersionOfTKr(tkr)
+	}
+	return r.upgradeCluster(ctx, managementClusterName, workloadClusterName, WorkloadClusterType, version, args...)
+}
+
+// CheckMachineHealthCheckRemediation deletes the node of a worker Machine of the workload cluster, like when the
+// worker fails, and waits for the MachineHealthCheck of the workload cluster to replace the Machine with a new
+// one and for the workload cluster to have as many ready workers as before. The node is deleted instead of the
+// Machine, as the MachineSet of a deleted Machine replaces it without the MachineHealthCheck
+func (r DefaultClusterTestRunner) CheckMachineHealthCheckRemediation(ctx context.Context, managementClusterName string, workloadClusterName string) error {
+	ctx, cancel := context.WithTimeout(ctx, machineRemediationTimeout)
+	defer cancel()
+
+	managementClusterClient, err := r.managementClusterClient(managementClusterName)
+	if err != nil {
+		return err
+	}
+	workloadClusterClient, err := r.clusterKubeClient(workloadClusterName)
+	if err != nil {
+		return err
+	}
+
+	err = capi.CheckMachineHealthCheck(ctx, managementClusterClient, workloadClusterName)
+	if err != nil {
+		return err
+	}
+
+	workers, err := capi.WorkerMachines(ctx, managementClusterClient, workloadClusterName)
+	if err != nil {
+		return err
+	}
+	if len(workers) == 0 || workers[0].Status.NodeRef == nil {
+		return fmt.Errorf("workload cluster %s has no worker Machine with a node", workloadClusterName)
+	}
+	machine := workers[0]
+
+	r.Logger.Infof("Deleting node %s of Machine %s of workload cluster %s", machine.Status.NodeRef.Name, machine.Name, workloadClusterName)
+	err = workloadClusterClient.DeleteNode(ctx, machine.Status.NodeRef.Name)
+	if err != nil {
+		return err
+	}
+
+	return r.waitForCluster(ctx, managementClusterName, workloadClusterName, machineRemediationTimeout, fmt.Sprintf("to replace Machine %s", machine.Name), func(ctx context.Context, managementClusterClient client.Client, workloadClusterClient *kubeclient.KubeClient) error {
+		err := capi.CheckMachineReplaced(ctx, managementClusterClient, workloadClusterName, machine.Name, int32(len(workers)))
+		if err != nil {
+			return err
+		}
+		return workloadClusterClient.CheckNodes(ctx, len(workers), "")
+	})
+}
+
+// runTanzuCommand runs the `tanzu cluster` or `tanzu management-cluster` command of the cluster type with the
+// arguments
+func (r DefaultClusterTestRunner) runTanzuCommand(ctx context.Context, clusterType ClusterType, timeout time.Duration, args ...string) error {
+	result, err := clirunner.RunContext(ctx, clirunner.Cmd{
+		Name:             "tanzu",
+		Args:             append([]string{clusterType.TanzuCommand()}, args...),
+		Env:              r.GetEnv(),
+		Stdout:           r.Logger.InfoWriter(),
+		StderrClassifier: log.DefaultStreamClassifier(),
+		Timeout:          timeout,
+		Logger:           r.Logger,
+	})
+	if err != nil {
+		return fmt.Errorf("error occurred while running tanzu %s %s. exit code: %v. error: %w. failure summary:\n%s", clusterType.TanzuCommand(), strings.Join(args, " "), result.ExitCode, err, result.FailureSummary())
+	}
+	return nil
+}
+
+// waitForCluster polls the condition with clients of the management cluster and of the cluster, which can be the
+// management cluster itself, until it's met or the timeout
+func (r DefaultClusterTestRunner) waitForCluster(ctx context.Context, managementClusterName string, clusterName string, timeout time.Duration, what string, condition func(ctx context.Context, managementClusterClient client.Client, clusterClient *kubeclient.KubeClient) error) error {
+	managementClusterClient, err := r.managementClusterClient(managementClusterName)
+	if err != nil {
+		return err
+	}
+	clusterClient, err := r.clusterKubeClient(clusterName)
+	if err != nil {
+		return err
+	}
+
+	options := clusterPollOptions
+	options.Timeout = timeout
+	options.Progress = func(attempt poll.Attempt) {
+		r.Logger.Infof("Waiting for cluster %s %s, %v elapsed: %v", clusterName, what, attempt.Elapsed.Round(time.Second), attempt.Err)
+	}
+	err = poll.Until(ctx, options, func(ctx context.Context) error {
+		return condition(ctx, managementClusterClient, clusterClient)
+	})
+	if err != nil {
+		return fmt.Errorf("error while waiting for cluster %s %s: %v", clusterName, what, err)
+	}
+
+	r.Logger.Infof("Done waiting for cluster %s %s", clusterName, what)
+	return nil
+}
+
+// clusterKubeClient returns a client of the cluster, using its kube context
+func (r DefaultClusterTestRunner) clusterKubeClient(clusterName string) (*kubeclient.KubeClient, error) {
+	kubeConfigPath, err := r.GetKubeConfigPath()
+	if err != nil {
+		return nil, fmt.Errorf("error while getting kubeconfig path: %v", err)
+	}
+
+	clusterClient, err := kubeclient.GetKubeClient(kubeConfigPath, r.GetKubeContextForTanzuCluster(clusterName))
+	if err != nil {
+		return nil, fmt.Errorf("error while getting client of cluster %s: %v", clusterName, err)
+	}
+	return clusterClient, nil
+}
+
+// clusterRuntimeClient returns a controller-runtime client of the cluster, using its kube context
+func (r DefaultClusterTestRunner) clusterRuntimeClient(clusterName string) (client.Client, error) {
+	kubeConfigPath, err := r.GetKubeConfigPath()
+	if err != nil {
+		return nil, fmt.Errorf("error while getting kubeconfig path: %v", err)
+	}
+
+	clusterClient, err := kubeclient.GetControllerRuntimeClient(kubeConfigPath, r.GetKubeContextForTanzuCluster(clusterName))
+	if err != nil {
+		return nil, fmt.Errorf("error while getting client of cluster %s: %v", clusterName, err)
+	}
+	return clusterClient, nil
+}
@@ -0,0 +1,102 @@
+package utils_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/tce"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/utils"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/utils/mock_utils"
+)
+
+func TestRunProviderTestLifecycle(t *testing.T) {
+	t.Run("when lifecycle operations are set it should run them on the workload cluster before deleting it", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+
+		defer ctrl.Finish()
+
+		provider := mock_utils.NewMockProvider(ctrl)
+		r := mock_utils.NewMockClusterTestRunner(ctrl)
+
+		r.EXPECT().GetLogger().AnyTimes()
+		r.EXPECT().GetTanzuVersion().AnyTimes()
+		provider.EXPECT().Name().Return("mock-infra").AnyTimes()
+
+		gomock.InOrder(
+			r.EXPECT().
+				GetRandomClusterNames().Return("test-mgmt", "test-wkld"),
+
+			r.EXPECT().
+				FindManagementCluster("existing-mgmt").Return("existing-mgmt", nil),
+
+			r.EXPECT().RunChecks(),
+
+			provider.EXPECT().RequiredEnvVars(),
+
+			provider.EXPECT().SecretEnvVars(),
+
+			r.EXPECT().CheckConfig(provider),
+
+			r.EXPECT().GetTanzuConfig(provider, "existing-mgmt", utils.ManagementClusterType),
+
+			r.EXPECT().GetTanzuConfig(provider, "test-wkld", utils.WorkloadClusterType),
+
+			provider.EXPECT().Init(gomock.Any(), gomock.Any(), gomock.Any()),
+
+			r.EXPECT().LoginToManagementCluster("existing-mgmt"),
+
+			r.EXPECT().GetClusterKubeConfig("existing-mgmt", provider, utils.ManagementClusterType),
+
+			r.EXPECT().CheckManagementClusterIsHealthy(gomock.Any(), "existing-mgmt"),
+
+			provider.EXPECT().PreClusterCreationTasks("test-wkld", utils.WorkloadClusterType),
+
+			r.EXPECT().GetKubeContextForTanzuCluster("test-wkld").Return("mock-context-2"),
+
+			r.EXPECT().GetKubeConfigPath().Return("mock-config-path", nil),
+
+			r.EXPECT().
+				RunCluster(gomock.Any(), "test-wkld", provider, utils.WorkloadClusterType),
+
+			r.EXPECT().CheckWorkloadClusterIsRunning(gomock.Any(), "existing-mgmt", "test-wkld"),
+
+			r.EXPECT().GetClusterKubeConfig("test-wkld", provider, utils.WorkloadClusterType),
+
+			r.EXPECT().PrintClusterInformation("mock-config-path", "mock-context-2"),
+
+			r.EXPECT().ScaleWorkloadCluster(gomock.Any(), "existing-mgmt", "test-wkld", 3),
+
+			r.EXPECT().ScaleWorkloadCluster(gomock.Any(), "existing-mgmt", "test-wkld", 1),
+
+			r.EXPECT().CheckMachineHealthCheckRemediation(gomock.Any(), "existing-mgmt", "test-wkld"),
+
+			r.EXPECT().DeployUpgradeTestWorkload(gomock.Any(), "test-wkld"),
+
+			r.EXPECT().
+				UpgradeWorkloadCluster(gomock.Any(), "existing-mgmt", "test-wkld", "v1.22.8---vmware.1-tkg.1").
+				Return(fmt.Errorf("some error in upgrade")),
+
+			r.EXPECT().DeleteCluster(gomock.Any(), "test-wkld", provider, utils.WorkloadClusterType),
+
+			r.EXPECT().WaitForWorkloadClusterDeletion(gomock.Any(), "existing-mgmt", "test-wkld"),
+
+			r.EXPECT().DeleteContext("mock-config-path", "mock-context-2"),
+		)
+
+		err := utils.RunProviderTestWithOptions(context.Background(), provider, r, tce.Package{}, utils.RunOptions{
+			ExistingManagementCluster: "existing-mgmt",
+			Lifecycle: utils.LifecycleOptions{
+				ScaleWorkerCounts:  []int{3, 1},
+				UpgradeTKr:         "v1.22.8---vmware.1-tkg.1",
+				MachineHealthCheck: true,
+			},
+		})
+		expectedError := "error while upgrading workload cluster to TKr v1.22.8---vmware.1-tkg.1: some error in upgrade"
+		if err == nil || !strings.Contains(err.Error(), expectedError) {
+			t.Errorf("expected error to contain: %v. But got: %v", expectedError, err)
+		}
+	})
+}
@@ -5,9 +5,12 @@
 package mock_utils
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	log "github.com/karuppiah7890/tce-e2e-test/testutils/log"
+	tanzu "github.com/karuppiah7890/tce-e2e-test/testutils/tanzu"
 	utils "github.com/karuppiah7890/tce-e2e-test/testutils/utils"
 )
 
@@ -34,18 +37,74 @@ func (m *MockClusterTestRunner) EXPECT() *MockClusterTestRunnerMockRecorder {
 	return m.recorder
 }
 
+// CheckConfig mocks base method.
+func (m *MockClusterTestRunner) CheckConfig(provider utils.Provider) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckConfig", provider)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckConfig indicates an expected call of CheckConfig.
+func (mr *MockClusterTestRunnerMockRecorder) CheckConfig(provider interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckConfig", reflect.TypeOf((*MockClusterTestRunner)(nil).CheckConfig), provider)
+}
+
+// CheckMachineHealthCheckRemediation mocks base method.
+func (m *MockClusterTestRunner) CheckMachineHealthCheckRemediation(ctx context.Context, managementClusterName, workloadClusterName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckMachineHealthCheckRemediation", ctx, managementClusterName, workloadClusterName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckMachineHealthCheckRemediation indicates an expected call of CheckMachineHealthCheckRemediation.
+func (mr *MockClusterTestRunnerMockRecorder) CheckMachineHealthCheckRemediation(ctx, managementClusterName, workloadClusterName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckMachineHealthCheckRemediation", reflect.TypeOf((*MockClusterTestRunner)(nil).CheckMachineHealthCheckRemediation), ctx, managementClusterName, workloadClusterName)
+}
+
+// CheckManagementClusterIsHealthy mocks base method.
+func (m *MockClusterTestRunner) CheckManagementClusterIsHealthy(ctx context.Context, managementClusterName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckManagementClusterIsHealthy", ctx, managementClusterName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckManagementClusterIsHealthy indicates an expected call of CheckManagementClusterIsHealthy.
+func (mr *MockClusterTestRunnerMockRecorder) CheckManagementClusterIsHealthy(ctx, managementClusterName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckManagementClusterIsHealthy", reflect.TypeOf((*MockClusterTestRunner)(nil).CheckManagementClusterIsHealthy), ctx, managementClusterName)
+}
+
+// CheckUpgradedClusters mocks base method.
+func (m *MockClusterTestRunner) CheckUpgradedClusters(ctx context.Context, managementClusterName, workloadClusterName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckUpgradedClusters", ctx, managementClusterName, workloadClusterName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckUpgradedClusters indicates an expected call of CheckUpgradedClusters.
+func (mr *MockClusterTestRunnerMockRecorder) CheckUpgradedClusters(ctx, managementClusterName, workloadClusterName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckUpgradedClusters", reflect.TypeOf((*MockClusterTestRunner)(nil).CheckUpgradedClusters), ctx, managementClusterName, workloadClusterName)
+}
+
 // CheckWorkloadClusterIsRunning mocks base method.
-func (m *MockClusterTestRunner) CheckWorkloadClusterIsRunning(workloadClusterName string) error {
+func (m *MockClusterTestRunner) CheckWorkloadClusterIsRunning(ctx context.Context, managementClusterName, workloadClusterName string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CheckWorkloadClusterIsRunning", workloadClusterName)
+	ret := m.ctrl.Call(m, "CheckWorkloadClusterIsRunning", ctx, managementClusterName, workloadClusterName)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CheckWorkloadClusterIsRunning indicates an expected call of CheckWorkloadClusterIsRunning.
-func (mr *MockClusterTestRunnerMockRecorder) CheckWorkloadClusterIsRunning(workloadClusterName interface{}) *gomock.Call {
+func (mr *MockClusterTestRunnerMockRecorder) CheckWorkloadClusterIsRunning(ctx, managementClusterName, workloadClusterName interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckWorkloadClusterIsRunning", reflect.TypeOf((*MockClusterTestRunner)(nil).CheckWorkloadClusterIsRunning), workloadClusterName)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckWorkloadClusterIsRunning", reflect.TypeOf((*MockClusterTestRunner)(nil).CheckWorkloadClusterIsRunning), ctx, managementClusterName, workloadClusterName)
 }
 
 // CleanupDockerBootstrapCluster mocks base method.
@@ -91,17 +150,17 @@ func (mr *MockClusterTestRunnerMockRecorder) CollectManagementClusterDiagnostics
 }
 
 // DeleteCluster mocks base method.
-func (m *MockClusterTestRunner) DeleteCluster(clusterName string, provider utils.Provider, clusterType utils.ClusterType) error {
+func (m *MockClusterTestRunner) DeleteCluster(ctx context.Context, clusterName string, provider utils.Provider, clusterType utils.ClusterType) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteCluster", clusterName, provider, clusterType)
+	ret := m.ctrl.Call(m, "DeleteCluster", ctx, clusterName, provider, clusterType)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteCluster indicates an expected call of DeleteCluster.
-func (mr *MockClusterTestRunnerMockRecorder) DeleteCluster(clusterName, provider, clusterType interface{}) *gomock.Call {
+func (mr *MockClusterTestRunnerMockRecorder) DeleteCluster(ctx, clusterName, provider, clusterType interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCluster", reflect.TypeOf((*MockClusterTestRunner)(nil).DeleteCluster), clusterName, provider, clusterType)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCluster", reflect.TypeOf((*MockClusterTestRunner)(nil).DeleteCluster), ctx, clusterName, provider, clusterType)
 }
 
 // DeleteContext mocks base method.
@@ -118,10 +177,56 @@ func (mr *MockClusterTestRunnerMockRecorder) DeleteContext(kubeConfigPath, conte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteContext", reflect.TypeOf((*MockClusterTestRunner)(nil).DeleteContext), kubeConfigPath, contextName)
 }
 
+// DeployUpgradeTestWorkload mocks base method.
+func (m *MockClusterTestRunner) DeployUpgradeTestWorkload(ctx context.Context, clusterName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeployUpgradeTestWorkload", ctx, clusterName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeployUpgradeTestWorkload indicates an expected call of DeployUpgradeTestWorkload.
+func (mr *MockClusterTestRunnerMockRecorder) DeployUpgradeTestWorkload(ctx, clusterName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeployUpgradeTestWorkload", reflect.TypeOf((*MockClusterTestRunner)(nil).DeployUpgradeTestWorkload), ctx, clusterName)
+}
+
+// DryRunCluster mocks base method.
+func (m *MockClusterTestRunner) DryRunCluster(ctx context.Context, clusterName string, provider utils.Provider, clusterType utils.ClusterType) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DryRunCluster", ctx, clusterName, provider, clusterType)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DryRunCluster indicates an expected call of DryRunCluster.
+func (mr *MockClusterTestRunnerMockRecorder) DryRunCluster(ctx, clusterName, provider, clusterType interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DryRunCluster", reflect.TypeOf((*MockClusterTestRunner)(nil).DryRunCluster), ctx, clusterName, provider, clusterType)
+}
+
+// FindManagementCluster mocks base method.
+func (m *MockClusterTestRunner) FindManagementCluster(nameOrKubeContext string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindManagementCluster", nameOrKubeContext)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindManagementCluster indicates an expected call of FindManagementCluster.
+func (mr *MockClusterTestRunnerMockRecorder) FindManagementCluster(nameOrKubeContext interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindManagementCluster", reflect.TypeOf((*MockClusterTestRunner)(nil).FindManagementCluster), nameOrKubeContext)
+}
+
 // GetClusterKubeConfig mocks base method.
-func (m *MockClusterTestRunner) GetClusterKubeConfig(clusterName string, provider utils.Provider, clusterType utils.ClusterType) {
+func (m *MockClusterTestRunner) GetClusterKubeConfig(clusterName string, provider utils.Provider, clusterType utils.ClusterType) error {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "GetClusterKubeConfig", clusterName, provider, clusterType)
+	ret := m.ctrl.Call(m, "GetClusterKubeConfig", clusterName, provider, clusterType)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
 // GetClusterKubeConfig indicates an expected call of GetClusterKubeConfig.
@@ -130,6 +235,20 @@ func (mr *MockClusterTestRunnerMockRecorder) GetClusterKubeConfig(clusterName, p
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClusterKubeConfig", reflect.TypeOf((*MockClusterTestRunner)(nil).GetClusterKubeConfig), clusterName, provider, clusterType)
 }
 
+// GetEnv mocks base method.
+func (m *MockClusterTestRunner) GetEnv() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEnv")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// GetEnv indicates an expected call of GetEnv.
+func (mr *MockClusterTestRunnerMockRecorder) GetEnv() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEnv", reflect.TypeOf((*MockClusterTestRunner)(nil).GetEnv))
+}
+
 // GetKubeConfigPath mocks base method.
 func (m *MockClusterTestRunner) GetKubeConfigPath() (string, error) {
 	m.ctrl.T.Helper()
@@ -159,6 +278,20 @@ func (mr *MockClusterTestRunnerMockRecorder) GetKubeContextForTanzuCluster(clust
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKubeContextForTanzuCluster", reflect.TypeOf((*MockClusterTestRunner)(nil).GetKubeContextForTanzuCluster), clusterName)
 }
 
+// GetLogger mocks base method.
+func (m *MockClusterTestRunner) GetLogger() *log.Logger {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLogger")
+	ret0, _ := ret[0].(*log.Logger)
+	return ret0
+}
+
+// GetLogger indicates an expected call of GetLogger.
+func (mr *MockClusterTestRunnerMockRecorder) GetLogger() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLogger", reflect.TypeOf((*MockClusterTestRunner)(nil).GetLogger))
+}
+
 // GetRandomClusterNames mocks base method.
 func (m *MockClusterTestRunner) GetRandomClusterNames() (string, string) {
 	m.ctrl.T.Helper()
@@ -174,6 +307,63 @@ func (mr *MockClusterTestRunnerMockRecorder) GetRandomClusterNames() *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRandomClusterNames", reflect.TypeOf((*MockClusterTestRunner)(nil).GetRandomClusterNames))
 }
 
+// GetTanzuConfig mocks base method.
+func (m *MockClusterTestRunner) GetTanzuConfig(provider utils.Provider, clusterName string, clusterType utils.ClusterType) tanzu.TanzuConfig {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTanzuConfig", provider, clusterName, clusterType)
+	ret0, _ := ret[0].(tanzu.TanzuConfig)
+	return ret0
+}
+
+// GetTanzuConfig indicates an expected call of GetTanzuConfig.
+func (mr *MockClusterTestRunnerMockRecorder) GetTanzuConfig(provider, clusterName, clusterType interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTanzuConfig", reflect.TypeOf((*MockClusterTestRunner)(nil).GetTanzuConfig), provider, clusterName, clusterType)
+}
+
+// GetTanzuVersion mocks base method.
+func (m *MockClusterTestRunner) GetTanzuVersion() (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTanzuVersion")
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTanzuVersion indicates an expected call of GetTanzuVersion.
+func (mr *MockClusterTestRunnerMockRecorder) GetTanzuVersion() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTanzuVersion", reflect.TypeOf((*MockClusterTestRunner)(nil).GetTanzuVersion))
+}
+
+// InstallTCE mocks base method.
+func (m *MockClusterTestRunner) InstallTCE(version string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InstallTCE", version)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InstallTCE indicates an expected call of InstallTCE.
+func (mr *MockClusterTestRunnerMockRecorder) InstallTCE(version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InstallTCE", reflect.TypeOf((*MockClusterTestRunner)(nil).InstallTCE), version)
+}
+
+// LoginToManagementCluster mocks base method.
+func (m *MockClusterTestRunner) LoginToManagementCluster(managementClusterName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoginToManagementCluster", managementClusterName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LoginToManagementCluster indicates an expected call of LoginToManagementCluster.
+func (mr *MockClusterTestRunnerMockRecorder) LoginToManagementCluster(managementClusterName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoginToManagementCluster", reflect.TypeOf((*MockClusterTestRunner)(nil).LoginToManagementCluster), managementClusterName)
+}
+
 // PrintClusterInformation mocks base method.
 func (m *MockClusterTestRunner) PrintClusterInformation(kubeConfigPath, kubeContext string) error {
 	m.ctrl.T.Helper()
@@ -189,9 +379,11 @@ func (mr *MockClusterTestRunnerMockRecorder) PrintClusterInformation(kubeConfigP
 }
 
 // RunChecks mocks base method.
-func (m *MockClusterTestRunner) RunChecks() {
+func (m *MockClusterTestRunner) RunChecks() error {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "RunChecks")
+	ret := m.ctrl.Call(m, "RunChecks")
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
 // RunChecks indicates an expected call of RunChecks.
@@ -201,29 +393,71 @@ func (mr *MockClusterTestRunnerMockRecorder) RunChecks() *gomock.Call {
 }
 
 // RunCluster mocks base method.
-func (m *MockClusterTestRunner) RunCluster(clusterName string, provider utils.Provider, clusterType utils.ClusterType) error {
+func (m *MockClusterTestRunner) RunCluster(ctx context.Context, clusterName string, provider utils.Provider, clusterType utils.ClusterType) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RunCluster", clusterName, provider, clusterType)
+	ret := m.ctrl.Call(m, "RunCluster", ctx, clusterName, provider, clusterType)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // RunCluster indicates an expected call of RunCluster.
-func (mr *MockClusterTestRunnerMockRecorder) RunCluster(clusterName, provider, clusterType interface{}) *gomock.Call {
+func (mr *MockClusterTestRunnerMockRecorder) RunCluster(ctx, clusterName, provider, clusterType interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunCluster", reflect.TypeOf((*MockClusterTestRunner)(nil).RunCluster), ctx, clusterName, provider, clusterType)
+}
+
+// ScaleWorkloadCluster mocks base method.
+func (m *MockClusterTestRunner) ScaleWorkloadCluster(ctx context.Context, managementClusterName, workloadClusterName string, workerCount int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScaleWorkloadCluster", ctx, managementClusterName, workloadClusterName, workerCount)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ScaleWorkloadCluster indicates an expected call of ScaleWorkloadCluster.
+func (mr *MockClusterTestRunnerMockRecorder) ScaleWorkloadCluster(ctx, managementClusterName, workloadClusterName, workerCount interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScaleWorkloadCluster", reflect.TypeOf((*MockClusterTestRunner)(nil).ScaleWorkloadCluster), ctx, managementClusterName, workloadClusterName, workerCount)
+}
+
+// UpgradeManagementCluster mocks base method.
+func (m *MockClusterTestRunner) UpgradeManagementCluster(ctx context.Context, managementClusterName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpgradeManagementCluster", ctx, managementClusterName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpgradeManagementCluster indicates an expected call of UpgradeManagementCluster.
+func (mr *MockClusterTestRunnerMockRecorder) UpgradeManagementCluster(ctx, managementClusterName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpgradeManagementCluster", reflect.TypeOf((*MockClusterTestRunner)(nil).UpgradeManagementCluster), ctx, managementClusterName)
+}
+
+// UpgradeWorkloadCluster mocks base method.
+func (m *MockClusterTestRunner) UpgradeWorkloadCluster(ctx context.Context, managementClusterName, workloadClusterName, tkr string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpgradeWorkloadCluster", ctx, managementClusterName, workloadClusterName, tkr)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpgradeWorkloadCluster indicates an expected call of UpgradeWorkloadCluster.
+func (mr *MockClusterTestRunnerMockRecorder) UpgradeWorkloadCluster(ctx, managementClusterName, workloadClusterName, tkr interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunCluster", reflect.TypeOf((*MockClusterTestRunner)(nil).RunCluster), clusterName, provider, clusterType)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpgradeWorkloadCluster", reflect.TypeOf((*MockClusterTestRunner)(nil).UpgradeWorkloadCluster), ctx, managementClusterName, workloadClusterName, tkr)
 }
 
 // WaitForWorkloadClusterDeletion mocks base method.
-func (m *MockClusterTestRunner) WaitForWorkloadClusterDeletion(workloadClusterName string) error {
+func (m *MockClusterTestRunner) WaitForWorkloadClusterDeletion(ctx context.Context, managementClusterName, workloadClusterName string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "WaitForWorkloadClusterDeletion", workloadClusterName)
+	ret := m.ctrl.Call(m, "WaitForWorkloadClusterDeletion", ctx, managementClusterName, workloadClusterName)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // WaitForWorkloadClusterDeletion indicates an expected call of WaitForWorkloadClusterDeletion.
-func (mr *MockClusterTestRunnerMockRecorder) WaitForWorkloadClusterDeletion(workloadClusterName interface{}) *gomock.Call {
+func (mr *MockClusterTestRunnerMockRecorder) WaitForWorkloadClusterDeletion(ctx, managementClusterName, workloadClusterName interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitForWorkloadClusterDeletion", reflect.TypeOf((*MockClusterTestRunner)(nil).WaitForWorkloadClusterDeletion), workloadClusterName)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitForWorkloadClusterDeletion", reflect.TypeOf((*MockClusterTestRunner)(nil).WaitForWorkloadClusterDeletion), ctx, managementClusterName, workloadClusterName)
 }
@@ -9,6 +9,8 @@ import (
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	cleanup "github.com/karuppiah7890/tce-e2e-test/testutils/cleanup"
+	log "github.com/karuppiah7890/tce-e2e-test/testutils/log"
 	tanzu "github.com/karuppiah7890/tce-e2e-test/testutils/tanzu"
 	utils "github.com/karuppiah7890/tce-e2e-test/testutils/utils"
 )
@@ -65,17 +67,17 @@ func (mr *MockProviderMockRecorder) GetTanzuConfig(clusterName interface{}) *gom
 }
 
 // Init mocks base method.
-func (m *MockProvider) Init() error {
+func (m *MockProvider) Init(logger *log.Logger, cleanups *cleanup.Stack, tanzuConfig utils.TanzuConfigFunc) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Init")
+	ret := m.ctrl.Call(m, "Init", logger, cleanups, tanzuConfig)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Init indicates an expected call of Init.
-func (mr *MockProviderMockRecorder) Init() *gomock.Call {
+func (mr *MockProviderMockRecorder) Init(logger, cleanups, tanzuConfig interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Init", reflect.TypeOf((*MockProvider)(nil).Init))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Init", reflect.TypeOf((*MockProvider)(nil).Init), logger, cleanups, tanzuConfig)
 }
 
 // Name mocks base method.
@@ -119,3 +121,17 @@ func (mr *MockProviderMockRecorder) RequiredEnvVars() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequiredEnvVars", reflect.TypeOf((*MockProvider)(nil).RequiredEnvVars))
 }
+
+// SecretEnvVars mocks base method.
+func (m *MockProvider) SecretEnvVars() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SecretEnvVars")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// SecretEnvVars indicates an expected call of SecretEnvVars.
+func (mr *MockProviderMockRecorder) SecretEnvVars() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SecretEnvVars", reflect.TypeOf((*MockProvider)(nil).SecretEnvVars))
+}
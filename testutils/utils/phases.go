@@ -0,0 +1,133 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/karuppiah7890/tce-e2e-test/testutils/report"
+)
+
+// Phase is a phase of a provider test run, like creating the management cluster or running the package test
+type Phase struct {
+	// Name is the name of the phase, as recorded in the test run report
+	Name string
+	// DependsOn has the names of the phases that must pass for the phase to run. The phase is
+	// skipped if any of them failed or was skipped
+	DependsOn []string
+	// Cleanup phases still run after a phase they don't depend on fails, for example to delete the
+	// clusters when the package test fails. Other phases are skipped once any phase fails
+	Cleanup bool
+	// SkipReason skips the phase with the reason when it's not empty
+	SkipReason string
+	// Completed marks the phase as completed by the test run being resumed. It's skipped, with the
+	// reason recorded in the report, and it counts as passed for the phases which depend on it
+	Completed bool
+	// Run runs the phase and returns its error
+	Run func() error
+}
+
+// PhaseFailure is the error of a failed phase
+type PhaseFailure struct {
+	Phase string
+	Err   error
+}
+
+// PhasesError is the error of a test run in which one or more phases failed
+type PhasesError struct {
+	Failures []PhaseFailure
+}
+
+func (e *PhasesError) Error() string {
+	if len(e.Failures) == 1 {
+		return e.Failures[0].Err.Error()
+	}
+
+	messages := []string{}
+	for _, failure := range e.Failures {
+		messages = append(messages, fmt.Sprintf("%s phase: %v", failure.Phase, failure.Err))
+	}
+	return fmt.Sprintf("%d phases failed. %s", len(e.Failures), strings.Join(messages, "; "))
+}
+
+// Unwrap returns the error of the first failed phase
+func (e *PhasesError) Unwrap() error {
+	return e.Failures[0].Err
+}
+
+// RunPhases runs the phases in order and records each of them in the report. A phase is skipped, with
+// the reason recorded in the report, when any of the phases it depends on did not pass, or when an earlier
+// phase failed and the phase is not a cleanup phase. A completed phase is skipped too, but counts as
+// passed for the phases which depend on it. It returns a *PhasesError with the errors of all the
+// failed phases, or nil if no phase failed
+func RunPhases(runReport *report.Report, phases []Phase) error {
+	outcomes := map[string]report.Outcome{}
+	failures := []PhaseFailure{}
+
+	for _, phase := range phases {
+		if phase.Completed {
+			runReport.SkipPhase(phase.Name, "skipped as the phase was completed by the test run being resumed")
+			outcomes[phase.Name] = report.OutcomePassed
+			continue
+		}
+
+		skipReason := phaseSkipReason(phase, outcomes, failures)
+		if skipReason != "" {
+			runReport.SkipPhase(phase.Name, skipReason)
+			outcomes[phase.Name] = report.OutcomeSkipped
+			continue
+		}
+
+		err := runReport.RunPhase(phase.Name, phase.Run)
+		if err != nil {
+			outcomes[phase.Name] = report.OutcomeFailed
+			failures = append(failures, PhaseFailure{Phase: phase.Name, Err: err})
+			continue
+		}
+		outcomes[phase.Name] = report.OutcomePassed
+	}
+
+	if len(failures) != 0 {
+		return &PhasesError{Failures: failures}
+	}
+	return nil
+}
+
+// withPhaseFailures returns the error of the test run along with the failures. The error is
+// either nil or a *PhasesError returned by RunPhases
+func withPhaseFailures(err error, failures []PhaseFailure) error {
+	if len(failures) == 0 {
+		return err
+	}
+
+	phasesErr := &PhasesError{}
+	if err != nil && !errors.As(err, &phasesErr) {
+		phasesErr = &PhasesError{Failures: []PhaseFailure{{Err: err}}}
+	}
+	phasesErr.Failures = append(phasesErr.Failures, failures...)
+	return phasesErr
+}
+
+func phaseSkipReason(phase Phase, outcomes map[string]report.Outcome, failures []PhaseFailure) string {
+	if phase.SkipReason != "" {
+		return phase.SkipReason
+	}
+
+	for _, dependency := range phase.DependsOn {
+		switch outcomes[dependency] {
+		case report.OutcomePassed:
+		case report.OutcomeFailed:
+			return fmt.Sprintf("skipped as the %s phase it depends on failed", dependency)
+		case report.OutcomeSkipped:
+			return fmt.Sprintf("skipped as the %s phase it depends on was skipped", dependency)
+		default:
+			return fmt.Sprintf("skipped as the %s phase it depends on did not run before it", dependency)
+		}
+	}
+
+	if !phase.Cleanup && len(failures) != 0 {
+		return fmt.Sprintf("skipped as the %s phase failed", failures[0].Phase)
+	}
+
+	return ""
+}
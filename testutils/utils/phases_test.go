@@ -0,0 +1,89 @@
+package utils_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/karuppiah7890/tce-e2e-test/testutils/report"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/utils"
+)
+
+func TestRunPhases(t *testing.T) {
+	t.Run("when a phase fails it should skip the phases after it except the cleanup phases it doesn't block", func(t *testing.T) {
+		runReport := report.New("mock-infra")
+		ran := []string{}
+		phase := func(name string, err error) func() error {
+			return func() error {
+				ran = append(ran, name)
+				return err
+			}
+		}
+
+		err := utils.RunPhases(runReport, []utils.Phase{
+			{Name: "create", Run: phase("create", nil)},
+			{Name: "test", DependsOn: []string{"create"}, Run: phase("test", fmt.Errorf("some error in test"))},
+			{Name: "more-test", DependsOn: []string{"create"}, Run: phase("more-test", nil)},
+			{Name: "dependent-cleanup", DependsOn: []string{"test"}, Cleanup: true, Run: phase("dependent-cleanup", nil)},
+			{Name: "delete", DependsOn: []string{"create"}, Cleanup: true, Run: phase("delete", fmt.Errorf("some error in delete"))},
+		})
+
+		expectedRan := []string{"create", "test", "delete"}
+		if fmt.Sprint(ran) != fmt.Sprint(expectedRan) {
+			t.Errorf("expected phases %v to run but got %v", expectedRan, ran)
+		}
+
+		expectedError := "2 phases failed. test phase: some error in test; delete phase: some error in delete"
+		if err == nil || err.Error() != expectedError {
+			t.Errorf("expected error to be: %v. But got: %v", expectedError, err)
+		}
+
+		expectedOutcomes := []report.Outcome{report.OutcomePassed, report.OutcomeFailed, report.OutcomeSkipped, report.OutcomeSkipped, report.OutcomeFailed}
+		if len(runReport.Phases) != len(expectedOutcomes) {
+			t.Fatalf("expected %d phases in the report but got %d", len(expectedOutcomes), len(runReport.Phases))
+		}
+		for i, phase := range runReport.Phases {
+			if phase.Outcome != expectedOutcomes[i] {
+				t.Errorf("expected phase %s to have outcome %v but got %v", phase.Name, expectedOutcomes[i], phase.Outcome)
+			}
+		}
+		if runReport.Phases[2].Error != "skipped as the test phase failed" {
+			t.Errorf("expected skip reason of the phase after the failure but got %q", runReport.Phases[2].Error)
+		}
+		if runReport.Phases[3].Error != "skipped as the test phase it depends on failed" {
+			t.Errorf("expected skip reason of the phase depending on the failed phase but got %q", runReport.Phases[3].Error)
+		}
+	})
+
+	t.Run("when all the phases pass it should return no error", func(t *testing.T) {
+		err := utils.RunPhases(report.New("mock-infra"), []utils.Phase{
+			{Name: "create", Run: func() error { return nil }},
+			{Name: "test", SkipReason: "nothing to test", Run: func() error { return fmt.Errorf("skipped phase should not run") }},
+			{Name: "delete", DependsOn: []string{"create"}, Cleanup: true, Run: func() error { return nil }},
+		})
+		if err != nil {
+			t.Errorf("expected no error but got: %v", err)
+		}
+	})
+
+	t.Run("when a phase was completed by the test run being resumed it should skip it and run the phases depending on it", func(t *testing.T) {
+		runReport := report.New("mock-infra")
+		ran := []string{}
+
+		err := utils.RunPhases(runReport, []utils.Phase{
+			{Name: "create", Completed: true, Run: func() error { return fmt.Errorf("completed phase should not run") }},
+			{Name: "test", DependsOn: []string{"create"}, Run: func() error {
+				ran = append(ran, "test")
+				return nil
+			}},
+		})
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		if fmt.Sprint(ran) != "[test]" {
+			t.Errorf("expected only the test phase to run but got %v", ran)
+		}
+		if runReport.Phases[0].Outcome != report.OutcomeSkipped || runReport.Phases[0].Error != "skipped as the phase was completed by the test run being resumed" {
+			t.Errorf("expected the completed phase to be skipped but got %+v", runReport.Phases[0])
+		}
+	})
+}
@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ProviderInfo describes a registered provider
+type ProviderInfo struct {
+	Name            string
+	Description     string
+	RequiredEnvVars []string
+}
+
+type registeredProvider struct {
+	info        ProviderInfo
+	newProvider func() Provider
+}
+
+var providerRegistry = struct {
+	mutex     sync.RWMutex
+	providers map[string]registeredProvider
+}{providers: map[string]registeredProvider{}}
+
+// RegisterProvider registers a provider so that it can be looked up by its name. newProvider returns a new
+// instance of the provider, as a provider holds the state of a test run. It's meant to be called from the
+// init function of the provider's package, and it panics if a provider with the same name is already registered
+func RegisterProvider(description string, newProvider func() Provider) {
+	provider := newProvider()
+	info := ProviderInfo{
+		Name:            provider.Name(),
+		Description:     description,
+		RequiredEnvVars: provider.RequiredEnvVars(),
+	}
+
+	providerRegistry.mutex.Lock()
+	defer providerRegistry.mutex.Unlock()
+
+	if _, ok := providerRegistry.providers[info.Name]; ok {
+		panic(fmt.Sprintf("provider %s is already registered", info.Name))
+	}
+	providerRegistry.providers[info.Name] = registeredProvider{info: info, newProvider: newProvider}
+}
+
+// LookupProvider returns a new instance of the registered provider with the name
+func LookupProvider(name string) (Provider, error) {
+	providerRegistry.mutex.RLock()
+	registered, ok := providerRegistry.providers[name]
+	providerRegistry.mutex.RUnlock()
+
+	if !ok {
+		names := []string{}
+		for _, info := range RegisteredProviders() {
+			names = append(names, info.Name)
+		}
+		return nil, fmt.Errorf("unknown provider %q, available providers: %s", name, strings.Join(names, ", "))
+	}
+
+	return registered.newProvider(), nil
+}
+
+// RegisteredProviders returns the details of all the registered providers, sorted by name
+func RegisteredProviders() []ProviderInfo {
+	providerRegistry.mutex.RLock()
+	defer providerRegistry.mutex.RUnlock()
+
+	infos := make([]ProviderInfo, 0, len(providerRegistry.providers))
+	for _, registered := range providerRegistry.providers {
+		infos = append(infos, registered.info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+	return infos
+}
@@ -0,0 +1,64 @@
+package utils_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/utils"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/utils/mock_utils"
+)
+
+func TestProviderRegistry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	newProvider := func() utils.Provider {
+		provider := mock_utils.NewMockProvider(ctrl)
+		provider.EXPECT().Name().Return("mock-registry-infra").AnyTimes()
+		provider.EXPECT().RequiredEnvVars().Return([]string{"MOCK_INFRA_TOKEN"}).AnyTimes()
+		return provider
+	}
+
+	utils.ResetProviderRegistry()
+	t.Cleanup(utils.ResetProviderRegistry)
+	utils.RegisterProvider("mock infrastructure", newProvider)
+
+	t.Run("when the provider is registered it should return a new instance of it", func(t *testing.T) {
+		first, err := utils.LookupProvider("mock-registry-infra")
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		second, err := utils.LookupProvider("mock-registry-infra")
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		if first == second {
+			t.Errorf("expected a new instance of the provider on each lookup")
+		}
+	})
+
+	t.Run("when the provider is not registered it should return an error with the available providers", func(t *testing.T) {
+		_, err := utils.LookupProvider("some-unknown-infra")
+		if err == nil || !strings.Contains(err.Error(), "available providers: mock-registry-infra") {
+			t.Errorf("expected error with the available providers but got: %v", err)
+		}
+	})
+
+	t.Run("it should list the registered providers with their required environment variables", func(t *testing.T) {
+		providers := utils.RegisteredProviders()
+		if len(providers) != 1 || providers[0].Description != "mock infrastructure" || providers[0].RequiredEnvVars[0] != "MOCK_INFRA_TOKEN" {
+			t.Errorf("expected the registered provider but got %+v", providers)
+		}
+	})
+
+	t.Run("when a provider with the same name is registered again it should panic", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("expected registering the provider again to panic")
+			}
+		}()
+		utils.RegisterProvider("mock infrastructure", newProvider)
+	})
+}
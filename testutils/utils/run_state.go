@@ -0,0 +1,213 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/karuppiah7890/tce-e2e-test/testutils/cleanup"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/kubeclient"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/log"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/report"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/state"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/tanzu"
+)
+
+// ResumeStateFileEnvVarName is the environment variable with the state file of a test run to resume
+const ResumeStateFileEnvVarName = "RESUME_STATE_FILE"
+
+// Kinds of the resources created by a provider test run, as tracked in the state file of the test run. Providers
+// track the resources they create themselves with their own kinds, like the AWS CloudFormation stack
+const (
+	// ResourceKindCluster is a cluster, whose infrastructure is cleaned up using the provider's CleanupCluster
+	ResourceKindCluster = "cluster"
+	// ResourceKindKubeContext is a kube context in the kubeconfig file
+	ResourceKindKubeContext = "kube-context"
+	// ResourceKindBootstrapCluster is the bootstrap cluster of a management cluster, named after the management cluster
+	ResourceKindBootstrapCluster = "bootstrap-cluster"
+)
+
+// ResourceTearer is implemented by the providers which create resources of their own kinds during a test run, like
+// the AWS CloudFormation stack, so that the resources can be torn down using only the state file of the test run
+type ResourceTearer interface {
+	// TeardownResource deletes the resource of one of the provider's own kinds
+	TeardownResource(ctx context.Context, resource cleanup.Resource) error
+}
+
+// newRunState creates the state of a new test run, written next to the log file when there's one, or reads the
+// state of the test run to resume when the options have a state file. A new test run uses the existing management
+// cluster of the options, if any
+func newRunState(provider Provider, r ClusterTestRunner, options RunOptions) (*state.State, error) {
+	if options.ResumeStateFile != "" {
+		runState, err := state.ReadFile(options.ResumeStateFile)
+		if err != nil {
+			return nil, err
+		}
+		if runState.Provider != provider.Name() {
+			return nil, fmt.Errorf("state file %s is of a test run on %s provider, not %s provider", options.ResumeStateFile, runState.Provider, provider.Name())
+		}
+		r.GetLogger().Infof("Resuming test run of clusters %s and %s, which completed phases %v", runState.ManagementClusterName, runState.WorkloadClusterName, runState.CompletedPhases)
+		return runState, nil
+	}
+
+	managementClusterName, workloadClusterName := r.GetRandomClusterNames()
+	if options.ExistingManagementCluster != "" {
+		var err error
+		managementClusterName, err = r.FindManagementCluster(options.ExistingManagementCluster)
+		if err != nil {
+			return nil, err
+		}
+		r.GetLogger().Infof("Using existing management cluster %s", managementClusterName)
+	}
+
+	stateFilePath := ""
+	if logFilePath := r.GetLogger().FilePath(); logFilePath != "" {
+		stateFilePath = state.FilePathForLogFile(logFilePath)
+	}
+	return state.New(stateFilePath, provider.Name(), managementClusterName, workloadClusterName)
+}
+
+// trackPhases records the start and the completion of each phase in the state of the test run. When resuming a
+// test run, the phases it completed are marked as completed, except the checks which set up the test run
+func trackPhases(logger *log.Logger, runState *state.State, phases []Phase, resuming bool) []Phase {
+	// Tracking the phases must not stop the test run, so errors are only logged
+	track := func(err error) {
+		if err != nil {
+			logger.Errorf("error while tracking phases in the state of the test run: %v", err)
+		}
+	}
+
+	tracked := make([]Phase, 0, len(phases))
+	for _, phase := range phases {
+		phase := phase
+		run := phase.Run
+		phase.Completed = resuming && phase.Name != report.PhaseChecks && runState.IsPhaseCompleted(phase.Name)
+		phase.Run = func() error {
+			track(runState.StartPhase(phase.Name))
+			err := run()
+			if err == nil {
+				track(runState.CompletePhase(phase.Name))
+			}
+			return err
+		}
+		tracked = append(tracked, phase)
+	}
+	return tracked
+}
+
+// pushResourceCleanupStep registers the cleanup step of a resource created during the test run, which is tracked
+// in the state of the test run
+func pushResourceCleanupStep(cleanups *cleanup.Stack, provider Provider, r ClusterTestRunner, kubeConfigPath string, resource cleanup.Resource) (*cleanup.Step, error) {
+	switch resource.Kind {
+	case ResourceKindCluster:
+		return cleanups.PushResource(fmt.Sprintf("cleanup cluster %s", resource.Name), resource, clusterCleanupTimeout, func(ctx context.Context) error {
+			return provider.CleanupCluster(ctx, resource.Name)
+		}), nil
+	case ResourceKindKubeContext:
+		return cleanups.PushResource(fmt.Sprintf("delete kube context %s", resource.Name), resource, kubeContextCleanupTimeout, func(ctx context.Context) error {
+			err := r.DeleteContext(kubeConfigPath, resource.Name)
+			if errors.Is(err, kubeclient.ErrContextNotFound) {
+				return nil
+			}
+			return err
+		}), nil
+	case ResourceKindBootstrapCluster:
+		return cleanups.PushResource(fmt.Sprintf("cleanup bootstrap cluster of management cluster %s", resource.Name), resource, bootstrapClusterCleanupTimeout, func(ctx context.Context) error {
+			return r.CleanupDockerBootstrapCluster(resource.Name)
+		}), nil
+	}
+
+	tearer, ok := provider.(ResourceTearer)
+	if !ok {
+		return nil, fmt.Errorf("%s provider can't tear down %s", provider.Name(), resource)
+	}
+	return cleanups.PushResource(fmt.Sprintf("delete %s", resource), resource, cleanup.DefaultTimeout, func(ctx context.Context) error {
+		return tearer.TeardownResource(ctx, resource)
+	}), nil
+}
+
+// restoreCleanupSteps registers the cleanup steps of the resources in the state of the test run which are not
+// deleted, in the order of their creation. It returns the cleanup steps of the clusters by cluster name
+func restoreCleanupSteps(cleanups *cleanup.Stack, provider Provider, r ClusterTestRunner, runState *state.State) (map[string]*cleanup.Step, error) {
+	kubeConfigPath, err := r.GetKubeConfigPath()
+	if err != nil {
+		return nil, fmt.Errorf("error while getting kubeconfig path: %v", err)
+	}
+
+	clusterCleanups := map[string]*cleanup.Step{}
+	for _, resource := range runState.RemainingResources() {
+		step, err := pushResourceCleanupStep(cleanups, provider, r, kubeConfigPath, resource)
+		if err != nil {
+			return nil, err
+		}
+		if resource.Kind == ResourceKindCluster {
+			clusterCleanups[resource.Name] = step
+		}
+	}
+	return clusterCleanups, nil
+}
+
+// initProvider initializes the provider for the test run, with the tanzu config of the clusters from the runner
+func initProvider(provider Provider, r ClusterTestRunner, cleanups *cleanup.Stack) error {
+	logger := r.GetLogger()
+
+	tanzuConfig := func(clusterName string, clusterType ClusterType) tanzu.TanzuConfig {
+		return r.GetTanzuConfig(provider, clusterName, clusterType)
+	}
+	err := provider.Init(logger, cleanups, tanzuConfig)
+	if err != nil {
+		logger.Errorf("error while initializing %s provider: %v", provider.Name(), err)
+		return fmt.Errorf("error while initializing %s provider: %v", provider.Name(), err)
+	}
+
+	return nil
+}
+
+// TeardownFromState tears down the resources created by a test run which are not deleted yet, like its clusters,
+// kube contexts and cloud resources, using the state file of the test run. It's meant for test runs which died
+// partway through, for example when the CI runner was preempted, and tears down only what the test run created.
+// The teardown is recorded in a report written next to the log file, and in the state file
+func TeardownFromState(provider Provider, r ClusterTestRunner, stateFilePath string) (err error) {
+	runState, err := state.ReadFile(stateFilePath)
+	if err != nil {
+		return err
+	}
+	if runState.Provider != provider.Name() {
+		return fmt.Errorf("state file %s is of a test run on %s provider, not %s provider", stateFilePath, runState.Provider, provider.Name())
+	}
+
+	runReport := report.New(provider.Name())
+	runReport.SetOutputRecorder(r.GetLogger())
+	runReport.SetClusterNames(runState.ManagementClusterName, runState.WorkloadClusterName)
+	runReport.SetStateFile(stateFilePath)
+	cleanups := cleanup.NewStack(r.GetLogger())
+	cleanups.SetTracker(runState)
+
+	defer func() {
+		cleanupFailures := unwindCleanups(runReport, cleanups)
+		writeReport(r, runReport, RunOptions{}, runState.ManagementClusterName, runState.WorkloadClusterName)
+		err = withPhaseFailures(err, cleanupFailures)
+	}()
+
+	return RunPhases(runReport, []Phase{
+		{
+			Name: report.PhaseChecks,
+			Run: func() error {
+				err := CheckRequiredEnvVars(provider)
+				if err != nil {
+					return fmt.Errorf("errors while checking required environment variables: %v", err)
+				}
+				err = initProvider(provider, r, cleanups)
+				if err != nil {
+					return err
+				}
+				_, err = restoreCleanupSteps(cleanups, provider, r, runState)
+				if err != nil {
+					return err
+				}
+				r.GetLogger().Infof("Tearing down %d resources of the test run of clusters %s and %s", cleanups.Len(), runState.ManagementClusterName, runState.WorkloadClusterName)
+				return nil
+			},
+		},
+	})
+}
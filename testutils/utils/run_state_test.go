@@ -0,0 +1,170 @@
+package utils_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/cleanup"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/report"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/state"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/tce"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/utils"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/utils/mock_utils"
+)
+
+var (
+	managementCluster   = cleanup.Resource{Kind: utils.ResourceKindCluster, Name: "test-mgmt"}
+	managementContext   = cleanup.Resource{Kind: utils.ResourceKindKubeContext, Name: "test-mgmt-admin@test-mgmt"}
+	managementBootstrap = cleanup.Resource{Kind: utils.ResourceKindBootstrapCluster, Name: "test-mgmt"}
+	workloadCluster     = cleanup.Resource{Kind: utils.ResourceKindCluster, Name: "test-wkld"}
+)
+
+// writeStateOfDeadTestRun writes the state of a test run which died while creating the workload cluster
+func writeStateOfDeadTestRun(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "mock-infra-mgmt-wkld-e2e.state.json")
+	runState, err := state.New(path, "mock-infra", "test-mgmt", "test-wkld")
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+
+	for _, err := range []error{
+		runState.CompletePhase(report.PhaseChecks),
+		runState.StartPhase(report.PhaseManagementClusterCreate),
+		runState.Created(managementCluster),
+		runState.Created(managementContext),
+		runState.Created(managementBootstrap),
+		runState.Deleted(managementBootstrap),
+		runState.CompletePhase(report.PhaseManagementClusterCreate),
+		runState.StartPhase(report.PhaseWorkloadClusterCreate),
+		runState.Created(workloadCluster),
+	} {
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+	}
+	return path
+}
+
+func TestRunProviderTestResume(t *testing.T) {
+	t.Run("when resuming a test run it should skip the completed phases and clean up the resources of the test run", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+
+		defer ctrl.Finish()
+
+		provider := mock_utils.NewMockProvider(ctrl)
+		r := mock_utils.NewMockClusterTestRunner(ctrl)
+
+		r.EXPECT().GetLogger().AnyTimes()
+		r.EXPECT().GetTanzuVersion().AnyTimes()
+		provider.EXPECT().Name().Return("mock-infra").AnyTimes()
+
+		stateFilePath := writeStateOfDeadTestRun(t)
+
+		gomock.InOrder(
+			r.EXPECT().RunChecks(),
+
+			provider.EXPECT().RequiredEnvVars(),
+
+			provider.EXPECT().SecretEnvVars(),
+
+			r.EXPECT().CheckConfig(provider),
+
+			r.EXPECT().GetTanzuConfig(provider, "test-mgmt", utils.ManagementClusterType),
+
+			r.EXPECT().GetTanzuConfig(provider, "test-wkld", utils.WorkloadClusterType),
+
+			provider.EXPECT().Init(gomock.Any(), gomock.Any(), gomock.Any()),
+
+			r.EXPECT().GetKubeConfigPath().Return("mock-config-path", nil),
+
+			provider.EXPECT().
+				PreClusterCreationTasks("test-wkld", utils.WorkloadClusterType).
+				Return(fmt.Errorf("some error in pre-cluster creation tasks")),
+
+			provider.EXPECT().CleanupCluster(gomock.Any(), "test-wkld"),
+			r.EXPECT().DeleteContext("mock-config-path", "test-mgmt-admin@test-mgmt"),
+			provider.EXPECT().CleanupCluster(gomock.Any(), "test-mgmt"),
+		)
+
+		err := utils.RunProviderTestWithOptions(context.Background(), provider, r, tce.Package{}, utils.RunOptions{ResumeStateFile: stateFilePath})
+		if err == nil || !strings.Contains(err.Error(), "some error in pre-cluster creation tasks") {
+			t.Errorf("expected error of the workload cluster creation but got: %v", err)
+		}
+
+		runState, err := state.ReadFile(stateFilePath)
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		if len(runState.RemainingResources()) != 0 {
+			t.Errorf("expected all the resources to be deleted but got: %v", runState.RemainingResources())
+		}
+	})
+
+	t.Run("when the state file is of another provider it should not resume the test run", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+
+		defer ctrl.Finish()
+
+		provider := mock_utils.NewMockProvider(ctrl)
+		r := mock_utils.NewMockClusterTestRunner(ctrl)
+
+		r.EXPECT().GetLogger().AnyTimes()
+		r.EXPECT().GetTanzuVersion().AnyTimes()
+		provider.EXPECT().Name().Return("other-infra").AnyTimes()
+
+		err := utils.RunProviderTestWithOptions(context.Background(), provider, r, tce.Package{}, utils.RunOptions{ResumeStateFile: writeStateOfDeadTestRun(t)})
+		if err == nil || !strings.Contains(err.Error(), "is of a test run on mock-infra provider, not other-infra provider") {
+			t.Errorf("expected error about the provider of the state file but got: %v", err)
+		}
+	})
+}
+
+func TestTeardownFromState(t *testing.T) {
+	t.Run("when a test run died it should tear down only the resources it created which are not deleted", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+
+		defer ctrl.Finish()
+
+		provider := mock_utils.NewMockProvider(ctrl)
+		r := mock_utils.NewMockClusterTestRunner(ctrl)
+
+		r.EXPECT().GetLogger().AnyTimes()
+		provider.EXPECT().Name().Return("mock-infra").AnyTimes()
+
+		stateFilePath := writeStateOfDeadTestRun(t)
+
+		gomock.InOrder(
+			provider.EXPECT().RequiredEnvVars(),
+
+			provider.EXPECT().SecretEnvVars(),
+
+			provider.EXPECT().Init(gomock.Any(), gomock.Any(), gomock.Any()),
+
+			r.EXPECT().GetKubeConfigPath().Return("mock-config-path", nil),
+
+			provider.EXPECT().CleanupCluster(gomock.Any(), "test-wkld"),
+			r.EXPECT().DeleteContext("mock-config-path", "test-mgmt-admin@test-mgmt"),
+			provider.EXPECT().
+				CleanupCluster(gomock.Any(), "test-mgmt").
+				Return(fmt.Errorf("some error in cleanup")),
+		)
+
+		err := utils.TeardownFromState(provider, r, stateFilePath)
+		if err == nil || !strings.Contains(err.Error(), "some error in cleanup") {
+			t.Errorf("expected error of the failed cleanup step but got: %v", err)
+		}
+
+		runState, err := state.ReadFile(stateFilePath)
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		expectedRemaining := []cleanup.Resource{managementCluster}
+		if fmt.Sprint(runState.RemainingResources()) != fmt.Sprint(expectedRemaining) {
+			t.Errorf("expected remaining resources %v but got %v", expectedRemaining, runState.RemainingResources())
+		}
+	})
+}
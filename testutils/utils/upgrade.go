@@ -0,0 +1,236 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/karuppiah7890/tce-e2e-test/testutils/capi"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/kubeclient"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/poll"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/report"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/tce"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// The test workload deployed to the workload cluster before it's upgraded, to check that workloads survive the
+// upgrade. The image is pinned so that the test workload doesn't change across the upgrade
+const (
+	upgradeTestNamespace      = "tce-e2e-upgrade-test"
+	upgradeTestDeploymentName = "upgrade-test"
+	upgradeTestImage          = "k8s.gcr.io/pause:3.6"
+	upgradeTestReplicas       = 2
+)
+
+// Maximum time given to each upgrade operation, including waiting for its result
+const (
+	managementClusterUpgradeTimeout = 90 * time.Minute
+	upgradeTestWorkloadTimeout      = 10 * time.Minute
+	upgradeCheckTimeout             = 30 * time.Minute
+)
+
+// tceUpgradeBuildType is the build type of the TCE version installed for the upgrade
+const tceUpgradeBuildType = "release"
+
+// upgradePhases returns the phases of the upgrade of the workload cluster. When a TCE version to upgrade to is
+// set, the test workload is deployed to the workload cluster, the TCE version is installed over the installed one
+// and the management cluster is upgraded, before the workload cluster is upgraded. The test workload is then
+// checked to be still available, and the package installs of both clusters to be still reconciled
+func upgradePhases(ctx context.Context, r ClusterTestRunner, options LifecycleOptions, managementClusterName, workloadClusterName string) []Phase {
+	upgrading := options.UpgradeTKr != "" || options.UpgradeTCEVersion != ""
+	upgradeSkipReason := skipReasonIf(!upgrading, "no TKr or TCE version to upgrade to")
+	tceUpgradeSkipReason := skipReasonIf(options.UpgradeTCEVersion == "", "no TCE version to upgrade to")
+
+	workloadClusterUpgradeDependencies := []string{report.PhaseUpgradeTestWorkloadDeploy}
+	if options.UpgradeTCEVersion != "" {
+		workloadClusterUpgradeDependencies = append(workloadClusterUpgradeDependencies, report.PhaseManagementClusterUpgrade)
+	}
+	workloadClusterUpgradeTarget := fmt.Sprintf("TKr %s", options.UpgradeTKr)
+	if options.UpgradeTKr == "" {
+		workloadClusterUpgradeTarget = fmt.Sprintf("default TKr of TCE version %s", options.UpgradeTCEVersion)
+	}
+
+	return []Phase{
+		{
+			Name:       report.PhaseUpgradeTestWorkloadDeploy,
+			DependsOn:  []string{report.PhaseWorkloadClusterCreate},
+			SkipReason: upgradeSkipReason,
+			Run: func() error {
+				err := r.DeployUpgradeTestWorkload(ctx, workloadClusterName)
+				if err != nil {
+					return fmt.Errorf("error while deploying upgrade test workload to workload cluster: %v", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name:       report.PhaseTCEUpgrade,
+			DependsOn:  []string{report.PhaseUpgradeTestWorkloadDeploy},
+			SkipReason: tceUpgradeSkipReason,
+			Run: func() error {
+				err := r.InstallTCE(options.UpgradeTCEVersion)
+				if err != nil {
+					return fmt.Errorf("error while installing TCE version %s: %v", options.UpgradeTCEVersion, err)
+				}
+				return nil
+			},
+		},
+		{
+			Name:       report.PhaseManagementClusterUpgrade,
+			DependsOn:  []string{report.PhaseTCEUpgrade},
+			SkipReason: tceUpgradeSkipReason,
+			Run: func() error {
+				err := r.UpgradeManagementCluster(ctx, managementClusterName)
+				if err != nil {
+					return fmt.Errorf("error while upgrading management cluster with TCE version %s: %v", options.UpgradeTCEVersion, err)
+				}
+				return nil
+			},
+		},
+		{
+			Name:       report.PhaseWorkloadClusterUpgrade,
+			DependsOn:  workloadClusterUpgradeDependencies,
+			SkipReason: upgradeSkipReason,
+			Run: func() error {
+				err := r.UpgradeWorkloadCluster(ctx, managementClusterName, workloadClusterName, options.UpgradeTKr)
+				if err != nil {
+					return fmt.Errorf("error while upgrading workload cluster to %s: %v", workloadClusterUpgradeTarget, err)
+				}
+				return nil
+			},
+		},
+		{
+			Name:       report.PhaseUpgradeCheck,
+			DependsOn:  []string{report.PhaseWorkloadClusterUpgrade},
+			SkipReason: upgradeSkipReason,
+			Run: func() error {
+				err := r.CheckUpgradedClusters(ctx, managementClusterName, workloadClusterName)
+				if err != nil {
+					return fmt.Errorf("error while checking upgraded clusters: %v", err)
+				}
+				return nil
+			},
+		},
+	}
+}
+
+// InstallTCE installs the TCE version over the installed one, which replaces the tanzu CLI and its plugins. The
+// install script runs with the environment of the test run, so that it updates the tanzu CLI config of the test run
+func (r DefaultClusterTestRunner) InstallTCE(version string) error {
+	r.Logger.Infof("Installing TCE version %s", version)
+	return tce.InstallWithEnv(version, tceUpgradeBuildType, r.GetEnv())
+}
+
+// UpgradeManagementCluster upgrades the management cluster using `tanzu management-cluster upgrade` of the
+// installed TCE. It checks that the Kubernetes version changed, and waits for the CAPI objects and the nodes of
+// the management cluster to be at it
+func (r DefaultClusterTestRunner) UpgradeManagementCluster(ctx context.Context, managementClusterName string) error {
+	return r.upgradeCluster(ctx, managementClusterName, managementClusterName, ManagementClusterType, "", "upgrade", managementClusterName, "--yes")
+}
+
+// DeployUpgradeTestWorkload deploys the test workload to the cluster and waits for it to be available, so that it
+// can be checked to survive the upgrade of the cluster
+func (r DefaultClusterTestRunner) DeployUpgradeTestWorkload(ctx context.Context, clusterName string) error {
+	clusterClient, err := r.clusterKubeClient(clusterName)
+	if err != nil {
+		return err
+	}
+
+	r.Logger.Infof("Deploying upgrade test workload %s to namespace %s of cluster %s", upgradeTestDeploymentName, upgradeTestNamespace, clusterName)
+	err = clusterClient.CreateDeployment(ctx, upgradeTestNamespace, upgradeTestDeploymentName, upgradeTestImage, upgradeTestReplicas)
+	if err != nil {
+		return err
+	}
+
+	options := clusterPollOptions
+	options.Timeout = upgradeTestWorkloadTimeout
+	options.Progress = func(attempt poll.Attempt) {
+		r.Logger.Infof("Waiting for upgrade test workload of cluster %s to be available, %v elapsed: %v", clusterName, attempt.Elapsed.Round(time.Second), attempt.Err)
+	}
+	err = poll.Until(ctx, options, func(ctx context.Context) error {
+		return clusterClient.CheckDeploymentAvailable(ctx, upgradeTestNamespace, upgradeTestDeploymentName)
+	})
+	if err != nil {
+		return fmt.Errorf("error while waiting for upgrade test workload of cluster %s to be available: %v", clusterName, err)
+	}
+	return nil
+}
+
+// CheckUpgradedClusters waits for the test workload deployed before the upgrade to be available in the workload
+// cluster, and for the package installs of both the management cluster and the workload cluster to be reconciled
+func (r DefaultClusterTestRunner) CheckUpgradedClusters(ctx context.Context, managementClusterName string, workloadClusterName string) error {
+	ctx, cancel := context.WithTimeout(ctx, upgradeCheckTimeout)
+	defer cancel()
+
+	workloadClusterClient, err := r.clusterRuntimeClient(workloadClusterName)
+	if err != nil {
+		return err
+	}
+
+	return r.waitForCluster(ctx, managementClusterName, workloadClusterName, upgradeCheckTimeout, "to have the upgrade test workload available and package installs reconciled", func(ctx context.Context, managementClusterClient client.Client, workloadClusterKubeClient *kubeclient.KubeClient) error {
+		err := workloadClusterKubeClient.CheckDeploymentAvailable(ctx, upgradeTestNamespace, upgradeTestDeploymentName)
+		if err != nil {
+			return err
+		}
+		err = kubeclient.CheckPackageInstallsReconciled(ctx, managementClusterClient)
+		if err != nil {
+			return fmt.Errorf("management cluster %s: %v", managementClusterName, err)
+		}
+		err = kubeclient.CheckPackageInstallsReconciled(ctx, workloadClusterClient)
+		if err != nil {
+			return fmt.Errorf("workload cluster %s: %v", workloadClusterName, err)
+		}
+		return nil
+	})
+}
+
+// upgradeCluster upgrades the cluster using the `tanzu cluster` or `tanzu management-cluster` command of the
+// cluster type with the arguments. It checks that the Kubernetes version of the cluster changed and, when the
+// expected version is not empty, that it's the expected version. It then waits for the CAPI objects and the nodes
+// of the cluster to be at the Kubernetes version
+func (r DefaultClusterTestRunner) upgradeCluster(ctx context.Context, managementClusterName string, clusterName string, clusterType ClusterType, expectedVersion string, args ...string) error {
+	timeout := clusterUpgradeTimeout
+	if clusterType == ManagementClusterType {
+		timeout = managementClusterUpgradeTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	managementClusterClient, err := r.managementClusterClient(managementClusterName)
+	if err != nil {
+		return err
+	}
+	previousVersion, err := capi.ClusterVersion(ctx, managementClusterClient, clusterName)
+	if err != nil {
+		return err
+	}
+
+	r.Logger.Infof("Upgrading cluster %s from Kubernetes version %s", clusterName, previousVersion)
+	err = r.runTanzuCommand(ctx, clusterType, timeout, args...)
+	if err != nil {
+		return err
+	}
+
+	version, err := capi.ClusterVersion(ctx, managementClusterClient, clusterName)
+	if err != nil {
+		return err
+	}
+	if version == previousVersion {
+		return fmt.Errorf("cluster %s is still at Kubernetes version %s after the upgrade", clusterName, version)
+	}
+	if expectedVersion != "" && version != expectedVersion {
+		return fmt.Errorf("cluster %s is upgraded to Kubernetes version %s, expected %s", clusterName, version, expectedVersion)
+	}
+
+	return r.waitForCluster(ctx, managementClusterName, clusterName, timeout, fmt.Sprintf("to be at Kubernetes version %s", version), func(ctx context.Context, managementClusterClient client.Client, clusterClient *kubeclient.KubeClient) error {
+		err := capi.CheckClusterVersion(ctx, managementClusterClient, clusterName, version)
+		if err != nil {
+			return err
+		}
+		workers, err := capi.WorkerMachines(ctx, managementClusterClient, clusterName)
+		if err != nil {
+			return err
+		}
+		return clusterClient.CheckNodes(ctx, len(workers), version)
+	})
+}
@@ -0,0 +1,130 @@
+package utils_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/tce"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/utils"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/utils/mock_utils"
+)
+
+func TestRunProviderTestUpgrade(t *testing.T) {
+	t.Run("when a TCE version to upgrade to is set it should upgrade TCE and the management cluster before the workload cluster and check them", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+
+		defer ctrl.Finish()
+
+		provider := mock_utils.NewMockProvider(ctrl)
+		r := mock_utils.NewMockClusterTestRunner(ctrl)
+
+		r.EXPECT().GetLogger().AnyTimes()
+		r.EXPECT().GetTanzuVersion().AnyTimes()
+		provider.EXPECT().Name().Return("mock-infra").AnyTimes()
+
+		gomock.InOrder(
+			r.EXPECT().
+				GetRandomClusterNames().Return("test-mgmt", "test-wkld"),
+
+			r.EXPECT().RunChecks(),
+
+			provider.EXPECT().RequiredEnvVars(),
+
+			provider.EXPECT().SecretEnvVars(),
+
+			r.EXPECT().CheckConfig(provider),
+
+			r.EXPECT().GetTanzuConfig(provider, "test-mgmt", utils.ManagementClusterType),
+
+			r.EXPECT().GetTanzuConfig(provider, "test-wkld", utils.WorkloadClusterType),
+
+			provider.EXPECT().Init(gomock.Any(), gomock.Any(), gomock.Any()),
+
+			provider.EXPECT().PreClusterCreationTasks("test-mgmt", utils.ManagementClusterType),
+
+			r.EXPECT().GetKubeContextForTanzuCluster("test-mgmt").Return("mock-context-1"),
+
+			r.EXPECT().GetKubeConfigPath().Return("mock-config-path", nil),
+
+			r.EXPECT().
+				RunCluster(gomock.Any(), "test-mgmt", provider, utils.ManagementClusterType),
+
+			r.EXPECT().GetClusterKubeConfig("test-mgmt", provider, utils.ManagementClusterType),
+
+			r.EXPECT().PrintClusterInformation("mock-config-path", "mock-context-1"),
+
+			provider.EXPECT().PreClusterCreationTasks("test-wkld", utils.WorkloadClusterType),
+
+			r.EXPECT().GetKubeContextForTanzuCluster("test-wkld").Return("mock-context-2"),
+
+			r.EXPECT().GetKubeConfigPath().Return("mock-config-path", nil),
+
+			r.EXPECT().
+				RunCluster(gomock.Any(), "test-wkld", provider, utils.WorkloadClusterType),
+
+			r.EXPECT().CheckWorkloadClusterIsRunning(gomock.Any(), "test-mgmt", "test-wkld"),
+
+			r.EXPECT().GetClusterKubeConfig("test-wkld", provider, utils.WorkloadClusterType),
+
+			r.EXPECT().PrintClusterInformation("mock-config-path", "mock-context-2"),
+
+			r.EXPECT().DeployUpgradeTestWorkload(gomock.Any(), "test-wkld"),
+
+			r.EXPECT().InstallTCE("0.12.0"),
+
+			r.EXPECT().UpgradeManagementCluster(gomock.Any(), "test-mgmt"),
+
+			r.EXPECT().UpgradeWorkloadCluster(gomock.Any(), "test-mgmt", "test-wkld", ""),
+
+			r.EXPECT().
+				CheckUpgradedClusters(gomock.Any(), "test-mgmt", "test-wkld").
+				Return(fmt.Errorf("package install default/cert-manager is not reconciled")),
+
+			r.EXPECT().DeleteCluster(gomock.Any(), "test-wkld", provider, utils.WorkloadClusterType),
+
+			r.EXPECT().WaitForWorkloadClusterDeletion(gomock.Any(), "test-mgmt", "test-wkld"),
+
+			r.EXPECT().DeleteCluster(gomock.Any(), "test-mgmt", provider, utils.ManagementClusterType),
+
+			// cleanup steps run in the reverse order of the creation of the resources
+			r.EXPECT().DeleteContext("mock-config-path", "mock-context-2"),
+			r.EXPECT().DeleteContext("mock-config-path", "mock-context-1"),
+		)
+
+		err := utils.RunProviderTestWithOptions(context.Background(), provider, r, tce.Package{}, utils.RunOptions{
+			Lifecycle: utils.LifecycleOptions{
+				UpgradeTCEVersion: "0.12.0",
+			},
+		})
+		expectedError := "error while checking upgraded clusters: package install default/cert-manager is not reconciled"
+		if err == nil || !strings.Contains(err.Error(), expectedError) {
+			t.Errorf("expected error to contain: %v. But got: %v", expectedError, err)
+		}
+	})
+	t.Run("when an existing management cluster is used with a TCE version to upgrade to it should return an error without running anything", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+
+		defer ctrl.Finish()
+
+		provider := mock_utils.NewMockProvider(ctrl)
+		r := mock_utils.NewMockClusterTestRunner(ctrl)
+
+		r.EXPECT().GetLogger().AnyTimes()
+		r.EXPECT().GetTanzuVersion().AnyTimes()
+		provider.EXPECT().Name().Return("mock-infra").AnyTimes()
+
+		err := utils.RunProviderTestWithOptions(context.Background(), provider, r, tce.Package{}, utils.RunOptions{
+			ExistingManagementCluster: "existing-mgmt",
+			Lifecycle: utils.LifecycleOptions{
+				UpgradeTCEVersion: "0.12.0",
+			},
+		})
+		expectedError := "upgrading to TCE version 0.12.0 is not supported with the existing management cluster existing-mgmt"
+		if err == nil || !strings.Contains(err.Error(), expectedError) {
+			t.Errorf("expected error to contain: %v. But got: %v", expectedError, err)
+		}
+	})
+}
@@ -72,7 +72,7 @@ const (
 )
 
 // Rename to RetrieveAndDownload
-func RetrieveAndDownload(version, dir, fileName string) {
+func RetrieveAndDownload(version, dir, fileName string) error {
 
 	url := fmt.Sprintf("https://download3.vmware.com/software/TCE-%s/%s", version, fileName)
 	log.Infof(url)
@@ -83,27 +83,34 @@ func RetrieveAndDownload(version, dir, fileName string) {
 		log.Infof("File exist, Skipping download")
 	} else {
 		log.Infof("Downloading file at %s from %s", downloadFile, url)
-		download.DownloadFileFromUrl(url, downloadFile)
+		err := download.DownloadFileFromUrl(url, downloadFile)
+		if err != nil {
+			return fmt.Errorf("error while downloading %s: %v", url, err)
+		}
 	}
 
+	return nil
 }
 func fileExists(path string) bool {
 	_, err := os.Stat(path)
 	return !os.IsNotExist(err)
 }
-func RetriveVersion(version string) []string {
+func RetriveVersion(version string) ([]string, error) {
 	url := fmt.Sprintf("https://customerconnect.vmware.com/channel/public/api/v1.0/dlg/details?downloadGroup=TCE-%s", version)
 	response, err := http.Get(url)
 	if err != nil {
-		log.Fatal(err)
+		return nil, fmt.Errorf("error while fetching download details of TCE %s: %v", version, err)
 	}
 	defer response.Body.Close()
 	responseData, err := ioutil.ReadAll(response.Body)
 	if err != nil {
-		log.Fatal(err)
+		return nil, fmt.Errorf("error while reading download details of TCE %s: %v", version, err)
 	}
 	jsonMap := Files{}
-	json.Unmarshal(responseData, &jsonMap)
+	err = json.Unmarshal(responseData, &jsonMap)
+	if err != nil {
+		return nil, fmt.Errorf("error while decoding download details of TCE %s: %v", version, err)
+	}
 
 	ovaFiles := []string{}
 	for i := range jsonMap.DownloadFiles {
@@ -112,17 +119,17 @@ func RetriveVersion(version string) []string {
 			ovaFiles = append(ovaFiles, jsonMap.DownloadFiles[i].FileName)
 		}
 	}
-	return ovaFiles
+	return ovaFiles, nil
 }
-func GetOvaFileNameFromTanzuFramework() []string {
+func GetOvaFileNameFromTanzuFramework() ([]string, error) {
 	bomDir, err := GetTanzuBomConfigPath()
 	if err != nil {
-		log.Errorf("failed to resolve Tanzu Bom config path. Error: %s", err.Error())
+		return nil, fmt.Errorf("failed to resolve Tanzu Bom config path. Error: %s", err.Error())
 	}
 	log.Infof("Tanzu Framework tkg bom home dir %s", bomDir)
 	bomFiles, err := ioutil.ReadDir(bomDir)
 	if err != nil {
-		log.Fatal(err)
+		return nil, fmt.Errorf("failed to read Tanzu Bom config directory %s: %v", bomDir, err)
 	}
 	ovaFiles := []string{}
 	for _, file := range bomFiles {
@@ -134,14 +141,14 @@ func GetOvaFileNameFromTanzuFramework() []string {
 		OvaFilesMap := &Tkr{}
 		err2 := yaml.Unmarshal(fileData, &OvaFilesMap)
 		if err2 != nil {
-			log.Fatal(err2)
+			return nil, fmt.Errorf("failed to decode Tanzu Bom file %s: %v", file.Name(), err2)
 		}
 		for _, value := range OvaFilesMap.Ova {
 			log.Infof("%s-%s-%s-%s", value.Osinfo.Name, value.Osinfo.Version, "kube", value.Version)
 			ovaFiles = append(ovaFiles, fmt.Sprintf("%s-%s-%s-%s", value.Osinfo.Name, value.Osinfo.Version, "kube", value.Version))
 		}
 	}
-	return ovaFiles
+	return ovaFiles, nil
 }
 
 //Todo: to be moved to common utils file
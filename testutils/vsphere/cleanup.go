@@ -0,0 +1,208 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/karuppiah7890/tce-e2e-test/testutils/cleanup"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/view"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// ClusterResources has the inventory paths of the vSphere VMs, folders and resource pools of a cluster
+type ClusterResources struct {
+	VMs           []string
+	Folders       []string
+	ResourcePools []string
+}
+
+// Count returns the number of resources
+func (resources ClusterResources) Count() int {
+	return len(resources.VMs) + len(resources.Folders) + len(resources.ResourcePools)
+}
+
+func (resources ClusterResources) String() string {
+	return fmt.Sprintf("VMs: [%s], folders: [%s], resource pools: [%s]",
+		strings.Join(resources.VMs, ", "), strings.Join(resources.Folders, ", "), strings.Join(resources.ResourcePools, ", "))
+}
+
+// DeleteClusterResources powers off and destroys the VMs of the cluster - the ones named after the cluster,
+// like test-mgmt-1652000000-control-plane-x7rtz for the test-mgmt-1652000000 cluster - and then destroys the
+// resource pools and folders created for the cluster, which are named after the cluster too. VM templates are
+// never destroyed. When the datacenter is not empty, only its inventory is searched.
+//
+// It keeps going when a resource can't be destroyed and returns the destroyed resources along with the
+// leftovers - the resources of the cluster found after the deletion. It returns an error when anything is left
+func DeleteClusterResources(ctx context.Context, client *vim25.Client, datacenter string, clusterName string) (deleted ClusterResources, leftovers ClusterResources, err error) {
+	root := client.ServiceContent.RootFolder
+	if datacenter != "" {
+		dc, err := find.NewFinder(client).Datacenter(ctx, datacenter)
+		if err != nil {
+			return deleted, leftovers, fmt.Errorf("error while finding datacenter %s: %v", datacenter, err)
+		}
+		root = dc.Reference()
+	}
+
+	errs := []string{}
+
+	vms, err := findClusterObjects(ctx, client, root, "VirtualMachine", clusterName)
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+	for _, vm := range vms {
+		err := destroyVM(ctx, object.NewVirtualMachine(client, vm.ref))
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("error while deleting VM %s: %v", vm.path, err))
+			continue
+		}
+		deleted.VMs = append(deleted.VMs, vm.path)
+	}
+
+	// Resource pools are destroyed before folders, as destroying a folder destroys everything in it. Destroying a
+	// resource pool moves anything left in it to its parent resource pool
+	pools, err := findClusterObjects(ctx, client, root, "ResourcePool", clusterName)
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+	for _, pool := range pools {
+		err := destroy(ctx, object.NewResourcePool(client, pool.ref).Destroy)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("error while deleting resource pool %s: %v", pool.path, err))
+			continue
+		}
+		deleted.ResourcePools = append(deleted.ResourcePools, pool.path)
+	}
+
+	folders, err := findClusterObjects(ctx, client, root, "Folder", clusterName)
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+	for _, folder := range folders {
+		err := destroy(ctx, object.NewFolder(client, folder.ref).Destroy)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("error while deleting folder %s: %v", folder.path, err))
+			continue
+		}
+		deleted.Folders = append(deleted.Folders, folder.path)
+	}
+
+	leftovers, err = findClusterResources(ctx, client, root, clusterName)
+	if err != nil {
+		errs = append(errs, err.Error())
+	} else if leftovers.Count() != 0 {
+		errs = append(errs, fmt.Sprintf("resources left behind: %s", leftovers))
+	}
+
+	if len(errs) != 0 {
+		return deleted, leftovers, fmt.Errorf("errors while deleting vSphere resources of cluster %s: %s", clusterName, strings.Join(errs, "; "))
+	}
+	return deleted, leftovers, nil
+}
+
+// findClusterResources returns the VMs, resource pools and folders of the cluster
+func findClusterResources(ctx context.Context, client *vim25.Client, root types.ManagedObjectReference, clusterName string) (ClusterResources, error) {
+	resources := ClusterResources{}
+	for _, kind := range []string{"VirtualMachine", "ResourcePool", "Folder"} {
+		objects, err := findClusterObjects(ctx, client, root, kind, clusterName)
+		if err != nil {
+			return resources, err
+		}
+		for _, o := range objects {
+			switch kind {
+			case "VirtualMachine":
+				resources.VMs = append(resources.VMs, o.path)
+			case "ResourcePool":
+				resources.ResourcePools = append(resources.ResourcePools, o.path)
+			case "Folder":
+				resources.Folders = append(resources.Folders, o.path)
+			}
+		}
+	}
+	return resources, nil
+}
+
+// clusterObject is a managed object of a cluster along with its inventory path
+type clusterObject struct {
+	ref  types.ManagedObjectReference
+	path string
+}
+
+// findClusterObjects returns the managed objects of the kind, like VirtualMachine, under the root which are
+// named after the cluster. VM templates are left out
+func findClusterObjects(ctx context.Context, client *vim25.Client, root types.ManagedObjectReference, kind string, clusterName string) ([]clusterObject, error) {
+	containerView, err := view.NewManager(client).CreateContainerView(ctx, root, []string{kind}, true)
+	if err != nil {
+		return nil, fmt.Errorf("error while listing %s objects: %v", kind, err)
+	}
+	defer containerView.Destroy(ctx)
+
+	// config.template is only a property of VMs, retrieving it for other kinds is an error
+	properties := []string{"name"}
+	if kind == "VirtualMachine" {
+		properties = append(properties, "config.template")
+	}
+
+	var contents []types.ObjectContent
+	err = containerView.Retrieve(ctx, []string{kind}, properties, &contents)
+	if err != nil {
+		return nil, fmt.Errorf("error while listing %s objects: %v", kind, err)
+	}
+
+	objects := []clusterObject{}
+	for _, content := range contents {
+		name, template := "", false
+		for _, property := range content.PropSet {
+			switch property.Name {
+			case "name":
+				name, _ = property.Val.(string)
+			case "config.template":
+				template, _ = property.Val.(bool)
+			}
+		}
+		if template || !cleanup.IsNamedForCluster(name, clusterName) {
+			continue
+		}
+
+		path := name
+		element, err := find.NewFinder(client).Element(ctx, content.Obj)
+		if err == nil {
+			path = element.Path
+		}
+		objects = append(objects, clusterObject{ref: content.Obj, path: path})
+	}
+	return objects, nil
+}
+
+// destroyVM powers off the VM if it's powered on and destroys it
+func destroyVM(ctx context.Context, vm *object.VirtualMachine) error {
+	state, err := vm.PowerState(ctx)
+	if err != nil {
+		return fmt.Errorf("error while getting power state: %v", err)
+	}
+
+	if state == types.VirtualMachinePowerStatePoweredOn {
+		task, err := vm.PowerOff(ctx)
+		if err != nil {
+			return fmt.Errorf("error while powering off: %v", err)
+		}
+		err = task.Wait(ctx)
+		if err != nil {
+			return fmt.Errorf("error while powering off: %v", err)
+		}
+	}
+
+	return destroy(ctx, vm.Destroy)
+}
+
+// destroy runs the destroy task of a managed object and waits for it to finish
+func destroy(ctx context.Context, destroyTask func(ctx context.Context) (*object.Task, error)) error {
+	task, err := destroyTask(ctx)
+	if err != nil {
+		return err
+	}
+	return task.Wait(ctx)
+}
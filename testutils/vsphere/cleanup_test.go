@@ -0,0 +1,120 @@
+package vsphere_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/karuppiah7890/tce-e2e-test/testutils/vsphere"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// renameVM renames the VM at the path in the simulator inventory
+func renameVM(ctx context.Context, t *testing.T, finder *find.Finder, path string, name string) *object.VirtualMachine {
+	vm, err := finder.VirtualMachine(ctx, path)
+	if err != nil {
+		t.Fatalf("expected no error while finding VM %s but got: %v", path, err)
+	}
+	task, err := vm.Rename(ctx, name)
+	if err != nil {
+		t.Fatalf("expected no error while renaming VM %s but got: %v", path, err)
+	}
+	err = task.Wait(ctx)
+	if err != nil {
+		t.Fatalf("expected no error while renaming VM %s but got: %v", path, err)
+	}
+	return vm
+}
+
+func TestDeleteClusterResources(t *testing.T) {
+	t.Run("when VMs, folders and resource pools are named after the cluster it should delete only them", func(t *testing.T) {
+		simulator.Test(func(ctx context.Context, client *vim25.Client) {
+			finder := find.NewFinder(client)
+			datacenter, err := finder.Datacenter(ctx, "DC0")
+			if err != nil {
+				t.Fatalf("expected no error but got: %v", err)
+			}
+			finder.SetDatacenter(datacenter)
+
+			renameVM(ctx, t, finder, "DC0_H0_VM0", "test-mgmt-1-control-plane-x7rtz")
+			renameVM(ctx, t, finder, "DC0_H0_VM1", "test-mgmt-1-md-0-abcde")
+			renameVM(ctx, t, finder, "DC0_C0_RP0_VM0", "test-mgmt-10-control-plane-x7rtz")
+
+			template := renameVM(ctx, t, finder, "DC0_C0_RP0_VM1", "test-mgmt-1-template")
+			task, err := template.PowerOff(ctx)
+			if err != nil {
+				t.Fatalf("expected no error but got: %v", err)
+			}
+			if err := task.Wait(ctx); err != nil {
+				t.Fatalf("expected no error but got: %v", err)
+			}
+			if err := template.MarkAsTemplate(ctx); err != nil {
+				t.Fatalf("expected no error but got: %v", err)
+			}
+
+			folders, err := datacenter.Folders(ctx)
+			if err != nil {
+				t.Fatalf("expected no error but got: %v", err)
+			}
+			if _, err := folders.VmFolder.CreateFolder(ctx, "test-mgmt-1"); err != nil {
+				t.Fatalf("expected no error but got: %v", err)
+			}
+
+			pool, err := finder.ResourcePool(ctx, "DC0_C0/Resources")
+			if err != nil {
+				t.Fatalf("expected no error but got: %v", err)
+			}
+			if _, err := pool.Create(ctx, "test-mgmt-1", types.DefaultResourceConfigSpec()); err != nil {
+				t.Fatalf("expected no error but got: %v", err)
+			}
+
+			deleted, leftovers, err := vsphere.DeleteClusterResources(ctx, client, "DC0", "test-mgmt-1")
+			if err != nil {
+				t.Fatalf("expected no error but got: %v", err)
+			}
+
+			expectedDeleted := vsphere.ClusterResources{
+				VMs:           []string{"/DC0/vm/test-mgmt-1-control-plane-x7rtz", "/DC0/vm/test-mgmt-1-md-0-abcde"},
+				Folders:       []string{"/DC0/vm/test-mgmt-1"},
+				ResourcePools: []string{"/DC0/host/DC0_C0/Resources/test-mgmt-1"},
+			}
+			if !reflect.DeepEqual(deleted, expectedDeleted) {
+				t.Errorf("expected deleted resources to be %v but got %v", expectedDeleted, deleted)
+			}
+			if leftovers.Count() != 0 {
+				t.Errorf("expected no leftovers but got %v", leftovers)
+			}
+
+			for _, name := range []string{"test-mgmt-10-control-plane-x7rtz", "test-mgmt-1-template"} {
+				if _, err := finder.VirtualMachine(ctx, name); err != nil {
+					t.Errorf("expected VM %s to not be deleted but got: %v", name, err)
+				}
+			}
+		})
+	})
+
+	t.Run("when there are no resources for the cluster it should delete nothing", func(t *testing.T) {
+		simulator.Test(func(ctx context.Context, client *vim25.Client) {
+			deleted, leftovers, err := vsphere.DeleteClusterResources(ctx, client, "", "test-wkld-1")
+			if err != nil {
+				t.Fatalf("expected no error but got: %v", err)
+			}
+			if deleted.Count() != 0 || leftovers.Count() != 0 {
+				t.Errorf("expected nothing to be deleted or left behind but got deleted %v and leftovers %v", deleted, leftovers)
+			}
+		})
+	})
+
+	t.Run("when the datacenter doesn't exist it should return an error", func(t *testing.T) {
+		simulator.Test(func(ctx context.Context, client *vim25.Client) {
+			_, _, err := vsphere.DeleteClusterResources(ctx, client, "DC404", "test-mgmt-1")
+			if err == nil {
+				t.Errorf("expected an error but got none")
+			}
+		})
+	})
+}
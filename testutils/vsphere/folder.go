@@ -0,0 +1,48 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/soap"
+)
+
+// folderCleanupTimeout is the maximum time given to delete the VM folder during cleanup
+const folderCleanupTimeout = 10 * time.Minute
+
+// ResourceKindFolder is the kind of the VM folder in the state of a test run
+const ResourceKindFolder = "vsphere-folder"
+
+// newClient logs into the vSphere server of the test secrets. The caller logs out of the session with Logout once
+// it's done with the client
+func newClient(ctx context.Context, testSecrets TestSecrets) (*govmomi.Client, error) {
+	serverURL, err := soap.ParseURL(testSecrets.Url)
+	if err != nil {
+		return nil, fmt.Errorf("error while parsing vSphere server URL: %v", err)
+	}
+	serverURL.User = url.UserPassword(testSecrets.Username, testSecrets.Password)
+
+	// The clusters are also created with VSPHERE_INSECURE set to true
+	client, err := govmomi.NewClient(ctx, serverURL, true)
+	if err != nil {
+		return nil, fmt.Errorf("error while logging into vSphere: %v", err)
+	}
+	return client, nil
+}
+
+// folderExists checks if the folder exists at the inventory path
+func folderExists(ctx context.Context, client *vim25.Client, folderPath string) (bool, error) {
+	_, err := find.NewFinder(client).Folder(ctx, folderPath)
+	if err != nil {
+		if _, ok := err.(*find.NotFoundError); ok {
+			return false, nil
+		}
+		return false, fmt.Errorf("error while finding folder %s: %v", folderPath, err)
+	}
+	return true, nil
+}
@@ -2,7 +2,10 @@ package vsphere
 
 import (
 	"context"
+	"fmt"
 
+	"github.com/karuppiah7890/tce-e2e-test/testutils/cleanup"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/log"
 	"github.com/karuppiah7890/tce-e2e-test/testutils/tanzu"
 	"github.com/karuppiah7890/tce-e2e-test/testutils/utils"
 )
@@ -10,6 +13,11 @@ import (
 // TODO: Change name?
 type Provider struct {
 	testSecrets TestSecrets
+	cleanups    *cleanup.Stack
+	logger      *log.Logger
+	// controlPlaneEndpoints are the control plane endpoints of the clusters by cluster name, as the control
+	// plane endpoint of a cluster depends on its cluster type
+	controlPlaneEndpoints map[string]string
 }
 
 func (provider *Provider) RequiredEnvVars() []string {
@@ -28,29 +36,127 @@ func (provider *Provider) RequiredEnvVars() []string {
 	}
 }
 
+// SecretEnvVars returns the credentials of the vSphere server
+func (provider *Provider) SecretEnvVars() []string {
+	return []string{
+		Username,
+		Password,
+	}
+}
+
 func (provider *Provider) Name() string {
 	return "vsphere"
 }
 
-func (provider *Provider) Init() error {
+func (provider *Provider) Init(logger *log.Logger, cleanups *cleanup.Stack, tanzuConfig utils.TanzuConfigFunc) error {
 	provider.testSecrets = ExtractVsphereTestSecretsFromEnvVars()
+	provider.cleanups = cleanups
+	provider.logger = logger
 	return nil
 }
 
 func (provider *Provider) PreClusterCreationTasks(clusterName string, clusterType utils.ClusterType) error {
-	// To Update API server IP during runtime with VSPHERE_MANAGEMENT_CLUSTER_ENDPOINT to VSPHERE_CONTROL_PLANE_ENDPOINT as that is needed for cluster
-	utils.UpdateVars(provider.Name(), clusterType)
+	// The cluster is created with VSPHERE_MANAGEMENT_CLUSTER_ENDPOINT or VSPHERE_WORKLOAD_CLUSTER_ENDPOINT as its
+	// VSPHERE_CONTROL_PLANE_ENDPOINT, depending on the cluster type
+	provider.setControlPlaneEndpoint(clusterName, clusterType)
+
+	if clusterType == utils.ManagementClusterType {
+		err := provider.createVmFolderIfNotExists()
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func (provider *Provider) CleanupCluster(ctx context.Context, clusterName string) error {
-	// TODO: Implement using govmomi golang library
+// setControlPlaneEndpoint sets the control plane endpoint in the tanzu config of the cluster to the API server
+// endpoint of its cluster type
+func (provider *Provider) setControlPlaneEndpoint(clusterName string, clusterType utils.ClusterType) {
+	if provider.controlPlaneEndpoints == nil {
+		provider.controlPlaneEndpoints = map[string]string{}
+	}
+	if clusterType == utils.ManagementClusterType {
+		provider.controlPlaneEndpoints[clusterName] = provider.testSecrets.ManagementApiServerEndpoint
+	} else if clusterType == utils.WorkloadClusterType {
+		provider.controlPlaneEndpoints[clusterName] = provider.testSecrets.WorkloadApiServerEndpoint
+	}
+}
+
+// createVmFolderIfNotExists creates the VM folder of the clusters if it doesn't exist, and registers
+// its deletion as a cleanup step. A VM folder which already exists is not deleted during cleanup
+func (provider *Provider) createVmFolderIfNotExists() error {
+	ctx := context.Background()
+	client, err := newClient(ctx, provider.testSecrets)
+	if err != nil {
+		return err
+	}
+	defer client.Logout(ctx)
+
+	folderPath := provider.testSecrets.VmFolder
+	exists, err := folderExists(ctx, client.Client, folderPath)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	err = CreateFolder(client.Client, folderPath)
+	if err != nil {
+		return fmt.Errorf("error while creating VM folder %s: %v", folderPath, err)
+	}
+
+	folder := cleanup.Resource{Kind: ResourceKindFolder, Name: folderPath}
+	provider.cleanups.PushResource(fmt.Sprintf("delete vSphere folder %s", folderPath), folder, folderCleanupTimeout, func(ctx context.Context) error {
+		return provider.deleteFolder(ctx, folderPath)
+	})
+
 	return nil
 }
 
-//TODO: Maybe make use of https://github.com/spf13/viper to set env vars and make some values as default and parameterised.
+func (provider *Provider) deleteFolder(ctx context.Context, folderPath string) error {
+	client, err := newClient(ctx, provider.testSecrets)
+	if err != nil {
+		return err
+	}
+	defer client.Logout(ctx)
+	return DeleteFolder(client.Client, folderPath)
+}
+
+// TeardownResource deletes the VM folder created by a test run, using only the state of the test run
+func (provider *Provider) TeardownResource(ctx context.Context, resource cleanup.Resource) error {
+	if resource.Kind != ResourceKindFolder {
+		return fmt.Errorf("%s provider can't tear down %s", provider.Name(), resource)
+	}
+	return provider.deleteFolder(ctx, resource.Name)
+}
+
+// CleanupCluster powers off and destroys the VMs left behind by the cluster, along with any folder or resource
+// pool created for it, and logs what it deleted and what was left behind
+func (provider *Provider) CleanupCluster(ctx context.Context, clusterName string) error {
+	client, err := newClient(ctx, provider.testSecrets)
+	if err != nil {
+		return err
+	}
+	defer client.Logout(ctx)
+
+	deleted, leftovers, err := DeleteClusterResources(ctx, client.Client, provider.testSecrets.Datacenter, clusterName)
+	if deleted.Count() == 0 {
+		provider.logger.Infof("No vSphere resources found for cluster %s", clusterName)
+	} else {
+		provider.logger.Infof("Deleted vSphere resources of cluster %s: %s", clusterName, deleted)
+	}
+	if leftovers.Count() != 0 {
+		provider.logger.Errorf("vSphere resources of cluster %s left behind: %s", clusterName, leftovers)
+	}
+	return err
+}
+
+// GetTanzuConfig returns the default tanzu config of the clusters, with the control plane endpoint of the cluster
+// once it's about to be created. A scenario and environment variables can override any of the values, see
+// utils.ClusterTanzuConfig
 func (provider *Provider) GetTanzuConfig(clusterName string) tanzu.TanzuConfig {
-	return tanzu.TanzuConfig{
+	tanzuConfig := tanzu.TanzuConfig{
 		"CLUSTER_NAME":                   clusterName,
 		"INFRASTRUCTURE_PROVIDER":        provider.Name(),
 		"CLUSTER_PLAN":                   "dev",
@@ -72,7 +178,19 @@ func (provider *Provider) GetTanzuConfig(clusterName string) tanzu.TanzuConfig {
 		"ENABLE_MHC":                     "true",
 		"IDENTITY_MANAGEMENT_TYPE":       "none",
 	}
+
+	controlPlaneEndpoint, ok := provider.controlPlaneEndpoints[clusterName]
+	if ok {
+		tanzuConfig["VSPHERE_CONTROL_PLANE_ENDPOINT"] = controlPlaneEndpoint
+	}
+	return tanzuConfig
 }
 
 // TODO: Change name?
 var PROVIDER utils.Provider = &Provider{}
+
+func init() {
+	utils.RegisterProvider("VMware vSphere", func() utils.Provider {
+		return &Provider{}
+	})
+}
@@ -0,0 +1,41 @@
+package vsphere_test
+
+import (
+	"testing"
+
+	"github.com/karuppiah7890/tce-e2e-test/testutils/cleanup"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/log"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/utils"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/vsphere"
+)
+
+func TestProviderGetTanzuConfig(t *testing.T) {
+	t.Run("when a workload cluster is about to be created it should have the workload cluster endpoint as its control plane endpoint", func(t *testing.T) {
+		t.Setenv(vsphere.ManagementApiServerEndpoint, "10.0.0.10")
+		t.Setenv(vsphere.WorkloadApiServerEndpoint, "10.0.0.20")
+		t.Setenv("VSPHERE_CONTROL_PLANE_ENDPOINT", "")
+
+		provider := &vsphere.Provider{}
+		err := provider.Init(log.NewTestLogger(t, "vsphere-e2e"), cleanup.NewStack(nil), nil)
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+
+		if _, ok := provider.GetTanzuConfig("test-wkld")["VSPHERE_CONTROL_PLANE_ENDPOINT"]; ok {
+			t.Errorf("expected no control plane endpoint before the cluster is about to be created")
+		}
+
+		err = provider.PreClusterCreationTasks("test-wkld", utils.WorkloadClusterType)
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+
+		controlPlaneEndpoint := provider.GetTanzuConfig("test-wkld")["VSPHERE_CONTROL_PLANE_ENDPOINT"]
+		if controlPlaneEndpoint != "10.0.0.20" {
+			t.Errorf("expected control plane endpoint 10.0.0.20 but got %q", controlPlaneEndpoint)
+		}
+		if _, ok := provider.GetTanzuConfig("test-mgmt")["VSPHERE_CONTROL_PLANE_ENDPOINT"]; ok {
+			t.Errorf("expected no control plane endpoint for the other cluster")
+		}
+	})
+}
@@ -52,7 +52,7 @@ TODO: Idea for OVA upload
 */
 
 // NewClient creates a vim25.Client
-func GetGovmomiClient() *vim25.Client {
+func GetGovmomiClient() (*vim25.Client, error) {
 	//TODO: To make use of common creds function or struct to avoid redundant vars
 	envUserName := os.Getenv(envUserName)
 	envPassword := os.Getenv(envPassword)
@@ -65,14 +65,13 @@ func GetGovmomiClient() *vim25.Client {
 	u.User = url.UserPassword(envUserName, envPassword)
 	client, err := govmomi.NewClient(ctx, u, true)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Login to vsphere failed, %v", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("login to vsphere failed: %v", err)
 	}
-	return client.Client
+	return client.Client, nil
 }
 
 // Rest Client this is being used by library module
-func GetRestClient(client *vim25.Client) *rest.Client {
+func GetRestClient(client *vim25.Client) (*rest.Client, error) {
 	//TODO: To make use of common creds function or struct to avoid redundant vars
 	envUserName := os.Getenv(envUserName)
 	envPassword := os.Getenv(envPassword)
@@ -85,10 +84,9 @@ func GetRestClient(client *vim25.Client) *rest.Client {
 	u.User = url.UserPassword(envUserName, envPassword)
 	rc := rest.NewClient(client)
 	if err := rc.Login(ctx, u.User); err != nil {
-		fmt.Fprintf(os.Stderr, "rc.Login failed, %v", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("login to vsphere rest API failed: %v", err)
 	}
-	return rc
+	return rc, nil
 }
 
 func ListVmsTemplates(client *vim25.Client) []string {
@@ -153,12 +151,12 @@ func GetLibraryItem(rc *rest.Client) ([]string, error) {
 	return libraries, nil
 }
 
-func CreateLibrary(libraryName string, rc *rest.Client, client *vim25.Client) {
+func CreateLibrary(libraryName string, rc *rest.Client, client *vim25.Client) error {
 	m := library.NewManager(rc)
 	envDataStore := os.Getenv(envDataStore)
 	ds, err := find.NewFinder(client).Datastore(ctx, envDataStore)
 	if err != nil {
-		log.Info(err)
+		return fmt.Errorf("unable to find datastore %s: %v", envDataStore, err)
 	}
 	res, err := m.CreateLibrary(ctx, library.Library{
 		Name: libraryName,
@@ -173,15 +171,15 @@ func CreateLibrary(libraryName string, rc *rest.Client, client *vim25.Client) {
 		},
 	})
 	if err != nil {
-		log.Fatalf("Unable to create Library %s", err)
+		return fmt.Errorf("unable to create library %s: %v", libraryName, err)
 	}
 	l, err := m.GetLibraryByID(ctx, res)
 	if err != nil {
-		log.Fatalf("Unable to create Library %s", err)
+		return fmt.Errorf("unable to get created library %s: %v", libraryName, err)
 	}
 
-	log.Infof("Library created Name : %s and ID :", l.Name, res)
-
+	log.Infof("Library created Name : %s and ID : %s", l.Name, res)
+	return nil
 }
 
 // To Get Library as Library Struct
@@ -281,26 +279,22 @@ func DeployVmFromLibrary(rc *rest.Client, client *vim25.Client, lib *library.Lib
 	finder := find.NewFinder(client)
 	resourcePools, err := finder.ResourcePoolList(ctx, envResourcePool)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to list resource pool at vc  %v", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("failed to list resource pool at vc: %v", err)
 	}
 	//hosts, err := finder.HostSystemList(ctx, "*")
 	datastores, err := finder.DatastoreList(ctx, envDataStore)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to list datastore at vc , %v", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("failed to list datastore at vc: %v", err)
 	}
 
 	networks, err := finder.NetworkList(ctx, envNetwork)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to list network at vc  %v", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("failed to list network at vc: %v", err)
 	}
 
 	folders, err := finder.FolderList(ctx, envFolder)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to list folder at vc  %v", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("failed to list folder at vc: %v", err)
 	}
 
 	m := vcenter.NewManager(rc)
@@ -310,10 +304,12 @@ func DeployVmFromLibrary(rc *rest.Client, client *vim25.Client, lib *library.Lib
 	},
 	}
 	item, err := libm.GetLibraryItem(ctx, lib.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get library item: %v", err)
+	}
 	r, err := m.FilterLibraryItem(ctx, item.ID, fr)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "FilterLibraryItem error, %v\n", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("failed to filter library item: %v", err)
 	}
 	networkKey := r.Networks[0]
 	storageKey := r.StorageGroups[0]
@@ -352,8 +348,7 @@ func DeployVmFromLibrary(rc *rest.Client, client *vim25.Client, lib *library.Lib
 	f := find.NewFinder(client)
 	obj, err := f.ObjectReference(ctx, *ref)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Find vm failed, %v\n", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("failed to find deployed vm: %v", err)
 	}
 	vm := obj.(*object.VirtualMachine)
 	return vm, nil
@@ -492,28 +487,22 @@ func CreateFolder(client *vim25.Client, folderName string) error {
 	return nil
 }
 
-// Can be used for dynamic folder Deletion
-// To be tested
+// Can be used for dynamic folder Deletion. It deletes the folder along with everything in it
 func DeleteFolder(client *vim25.Client, folderName string) error {
-	dir := path.Dir(folderName)
-	base := path.Base(folderName)
-	if dir == "" {
-		dir = "/"
-	}
 	finder := find.NewFinder(client)
-	folder, err := finder.Folder(ctx, dir)
+	folder, err := finder.Folder(ctx, folderName)
 	if err != nil {
 		if _, ok := err.(*find.NotFoundError); ok {
-			return fmt.Errorf("cannot create resource pool '%s': parent not found", base)
+			return fmt.Errorf("cannot delete folder '%s': folder not found", folderName)
 		}
 		return err
 	}
-	_, err = folder.Destroy(ctx)
+	task, err := folder.Destroy(ctx)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return task.Wait(ctx)
 }
 
 // To Delete Libary after create OVF templates
@@ -2,66 +2,48 @@ package main
 
 import (
 	"context"
-	"fmt"
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"flag"
+	"os"
+
 	"github.com/karuppiah7890/tce-e2e-test/testutils/aws"
 	"github.com/karuppiah7890/tce-e2e-test/testutils/log"
-	"os"
 )
 
-// S3ListBucketsAPI defines the interface for the ListBuckets function.
-// We use this interface to test the function using a mocked service.
-type S3ListBucketsAPI interface {
-	ListBuckets(ctx context.Context,
-		params *s3.ListBucketsInput,
-		optFns ...func(*s3.Options)) (*s3.ListBucketsOutput, error)
-}
-
-// GetAllBuckets retrieves a list of your Amazon Simple Storage Service (Amazon S3) buckets.
-// Inputs:
-//     c is the context of the method call, which includes the AWS Region.
-//     api is the interface that defines the method call.
-//     input defines the input arguments to the service call.
-// Output:
-//     If success, a ListBucketsOutput object containing the result of the service call and nil.
-//     Otherwise, nil and an error from the call to ListBuckets.
-func GetAllBuckets(c context.Context, api S3ListBucketsAPI, input *s3.ListBucketsInput) (*s3.ListBucketsOutput, error) {
-	return api.ListBuckets(c, input)
-}
-
+// Deletes the AWS resources created by CAPA for a cluster in the AWS_REGION region, or lists them with -dry-run.
+// For example:
+//
+//	go run ./tools/cleanup/awscl -dry-run test-mgmt-1652000000
 func main() {
+	log.InitLogger("awscl")
 
-	log.InitLogger("aws")
+	dryRun := flag.Bool("dry-run", false, "only list the resources of the cluster which would be deleted")
+	flag.Parse()
 
-	// TODO: Support providing multiple resource group names to delete.
-	// TODO: Support running delete on multiple resource group names concurrently / parallely vs sequentially based on the order of
-	// occurrence in the list in the CLI command.
-	// Use urfave/cli for handling variadic arguments? or use plain golang std library as usual?
-
-	if len(os.Args) != 2 {
-		log.Fatal("Usage: ./aws <resource-group-name>")
+	// TODO: Support providing multiple cluster names to clean up.
+	if flag.NArg() != 1 {
+		log.Fatal("Usage: ./awscl [-dry-run] <cluster-name>")
 	}
 
-	awsTestSecrets := aws.ExtractAwsTestSecretsFromEnvVars()
-	log.Infof("%s", awsTestSecrets)
-	cfg, err := config.LoadDefaultConfig(context.TODO())
-	if err != nil {
-		panic("configuration error, " + err.Error())
+	clusterName := flag.Arg(0)
+	region := os.Getenv(aws.Region)
+	if region == "" {
+		log.Fatalf("%s environment variable is required", aws.Region)
 	}
 
-	client := s3.NewFromConfig(cfg)
-
-	input := &s3.ListBucketsInput{}
-
-	result, err := GetAllBuckets(context.TODO(), client, input)
+	cleaner, err := aws.NewClusterCleaner(region, nil)
 	if err != nil {
-		fmt.Println("Got an error retrieving buckets:")
-		fmt.Println(err)
-		return
+		log.Fatalf("%v", err)
 	}
-
-	for _, bucket := range result.Buckets {
-		fmt.Println(*bucket.Name + ": " + bucket.CreationDate.Format("2006-01-02 15:04:05 Monday"))
+	cleaner.DryRun = *dryRun
+
+	// The cleaner logs each resource it deletes, or would delete in dry run mode
+	resources, err := cleaner.Cleanup(context.TODO(), clusterName)
+	if *dryRun {
+		log.Infof("%d AWS resources of cluster %s would be deleted", len(resources), clusterName)
+	} else {
+		log.Infof("Deleted %d AWS resources of cluster %s", len(resources), clusterName)
+	}
+	if err != nil {
+		log.Fatalf("failed to clean up AWS resources of cluster %s: %v", clusterName, err)
 	}
 }
@@ -10,7 +10,13 @@ import (
 
 func main() {
 	log.InitLogger("dockerctl")
-	docker.CheckDockerInstallation()
-	docker.ForceRemoveAllRunningContainers()
+	err := docker.CheckDockerInstallation()
+	if err != nil {
+		log.Fatalf("error while checking docker installation: %v", err)
+	}
+	err = docker.ForceRemoveAllRunningContainers()
+	if err != nil {
+		log.Errorf("error while removing all running containers: %v", err)
+	}
 	docker.ForceRemoveRunningContainer("kind")
 }
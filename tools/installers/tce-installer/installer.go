@@ -32,7 +32,7 @@ func main() {
 	log.InitLogger("tce-install")
 	// TODO: Get version from flags (--version) or arguments
 
-	err := utils.CheckTanzuCLIInstallation()
+	err := utils.CheckTanzuCLIInstallation(nil)
 	if err != nil {
 		log.Info("tanzu CLI is not installed")
 		err = tce.Install(*version, *buildType)
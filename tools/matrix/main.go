@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/karuppiah7890/tce-e2e-test/testutils/log"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/matrix"
+	_ "github.com/karuppiah7890/tce-e2e-test/testutils/providers"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/tce"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/utils"
+)
+
+// Runs the provider tests of all the combinations of the given providers, cluster plans, TCE versions and packages.
+// For example:
+//
+//	go run ./tools/matrix -providers aws,azure -plans dev,prod -packages velero@1.8.0
+func main() {
+	providers := flag.String("providers", "", "comma separated providers to test, like aws,azure. Use -list-providers to see all the providers")
+	plans := flag.String("plans", "", "comma separated cluster plans, like dev,prod. The provider's default plan is used when empty")
+	tceVersions := flag.String("tce-versions", "", "comma separated TCE versions to install and test one after the other. The installed TCE is tested when empty")
+	packages := flag.String("packages", "", "comma separated packages to test, as <name>@<version>, like velero@1.8.0")
+	concurrency := flag.Int("concurrency", 1, "maximum number of combinations of a TCE version to run at the same time")
+	junitReportDir := flag.String("junit-report-dir", os.Getenv(utils.JUnitReportDirEnvVarName), "directory to write the JUnit XML reports to")
+	dryRun := flag.Bool("dry-run", utils.DefaultRunOptions().DryRun, "only render and validate the manifests of the clusters of each combination, without creating them")
+	existingManagementCluster := flag.String("existing-management-cluster", utils.DefaultRunOptions().ExistingManagementCluster, "name or kube context of an existing management cluster to create the workload clusters in, instead of creating management clusters")
+	upgradeTCEVersion := flag.String("upgrade-tce-version", utils.DefaultRunOptions().Lifecycle.UpgradeTCEVersion, "TCE version to install over the TCE version of each combination, to upgrade its clusters with")
+	listProviders := flag.Bool("list-providers", false, "list the available providers along with their required environment variables and exit")
+	flag.Parse()
+
+	if *listProviders {
+		printProviders()
+		return
+	}
+
+	log.InitLogger("matrix")
+
+	if *providers == "" {
+		fmt.Fprintln(os.Stderr, "-providers is required")
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	packageList, err := parsePackages(splitList(*packages))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	testMatrix := matrix.Matrix{
+		Providers:   splitList(*providers),
+		Plans:       splitList(*plans),
+		TCEVersions: splitList(*tceVersions),
+		Packages:    packageList,
+	}
+
+	runOptions := utils.DefaultRunOptions()
+	runOptions.JUnitReportDir = *junitReportDir
+	runOptions.DryRun = *dryRun
+	runOptions.ExistingManagementCluster = *existingManagementCluster
+	runOptions.Lifecycle.UpgradeTCEVersion = *upgradeTCEVersion
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	results := matrix.Run(ctx, testMatrix, matrix.Options{
+		Concurrency: *concurrency,
+		RunOptions:  runOptions,
+	})
+
+	err = matrix.WriteSummary(os.Stdout, results)
+	if err != nil {
+		log.Errorf("error while writing summary: %v", err)
+	}
+
+	for _, result := range results {
+		if result.Err != nil {
+			os.Exit(1)
+		}
+	}
+}
+
+func printProviders() {
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "NAME\tDESCRIPTION\tREQUIRED ENVIRONMENT VARIABLES")
+	for _, provider := range utils.RegisteredProviders() {
+		fmt.Fprintf(table, "%s\t%s\t%s\n", provider.Name, provider.Description, strings.Join(provider.RequiredEnvVars, ","))
+	}
+	table.Flush()
+}
+
+func splitList(list string) []string {
+	values := []string{}
+	for _, value := range strings.Split(list, ",") {
+		value = strings.TrimSpace(value)
+		if value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func parsePackages(packages []string) ([]tce.Package, error) {
+	packageList := []tce.Package{}
+	for _, packageNameAndVersion := range packages {
+		parts := strings.SplitN(packageNameAndVersion, "@", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid package %s, expected <name>@<version>", packageNameAndVersion)
+		}
+		packageList = append(packageList, tce.Package{Name: parts[0], Version: parts[1], ManualCreate: true})
+	}
+	return packageList, nil
+}
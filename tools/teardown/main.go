@@ -0,0 +1,60 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/karuppiah7890/tce-e2e-test/testutils/log"
+	_ "github.com/karuppiah7890/tce-e2e-test/testutils/providers"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/state"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/utils"
+)
+
+// Tears down the resources created by a provider test run which died partway through, using the state file written
+// next to the log file of the test run. Only the resources the test run created and did not delete are torn down.
+// For example:
+//
+//	go run ./tools/teardown -state logs/aws-e2e-1650000000.state.json
+func main() {
+	stateFilePath := flag.String("state", "", "state file of the test run to tear down")
+	homeDir := flag.String("home-dir", "", "home directory of the test run, like the one next to the log file of a test run of the matrix tool. It's the home directory of the user when not set")
+	flag.Parse()
+
+	log.InitLogger("teardown")
+
+	if *stateFilePath == "" {
+		fmt.Fprintln(os.Stderr, "-state is required")
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	runState, err := state.ReadFile(*stateFilePath)
+	if err != nil {
+		exitWithError("%v", err)
+	}
+
+	provider, err := utils.LookupProvider(runState.Provider)
+	if err != nil {
+		exitWithError("%v", err)
+	}
+
+	logger, err := log.NewLogger(fmt.Sprintf("%s-teardown", runState.Provider))
+	if err != nil {
+		exitWithError("error while creating logger: %v", err)
+	}
+	defer logger.Close()
+
+	err = utils.TeardownFromState(provider, utils.DefaultClusterTestRunner{Logger: logger, HomeDir: *homeDir}, *stateFilePath)
+	if err != nil {
+		logger.Errorf("error while tearing down the test run of clusters %s and %s: %v", runState.ManagementClusterName, runState.WorkloadClusterName, err)
+		logger.Close()
+		os.Exit(1)
+	}
+}
+
+// exitWithError prints the error to the standard error and exits with a non-zero exit code
+func exitWithError(template string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, template+"\n", args...)
+	os.Exit(1)
+}
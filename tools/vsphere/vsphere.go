@@ -13,12 +13,24 @@ func main() {
 	//Setting Vsphere Clients
 	dir := "/tmp/"
 	fileName := ""
-	client := vsphere.GetGovmomiClient()
-	rs := vsphere.GetRestClient(client)
-	requiredOvaFile := vsphere.GetOvaFileNameFromTanzuFramework()
+	client, err := vsphere.GetGovmomiClient()
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	rs, err := vsphere.GetRestClient(client)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	requiredOvaFile, err := vsphere.GetOvaFileNameFromTanzuFramework()
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
 	log.Info(requiredOvaFile)
 	vmTemplates := vsphere.ListVmsTemplates(client)
-	filesAvailableToDownload := vsphere.RetriveVersion(tce)
+	filesAvailableToDownload, err := vsphere.RetriveVersion(tce)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
 	for _, file := range requiredOvaFile {
 		for _, template := range vmTemplates {
 			if file == template {
@@ -26,7 +38,10 @@ func main() {
 			} else {
 				for _, download := range filesAvailableToDownload {
 					if file == download {
-						vsphere.RetrieveAndDownload(tce, dir, file)
+						err := vsphere.RetrieveAndDownload(tce, dir, file)
+						if err != nil {
+							log.Fatalf("%v", err)
+						}
 						fileName = file
 					}
 				}
@@ -42,13 +57,19 @@ func main() {
 	//for _, y := range vmTemplates {
 	//	log.Info(y)
 	//}
-	vsphere.CreateLibrary("test", rs, client)
+	err = vsphere.CreateLibrary("test", rs, client)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
 	lib := vsphere.GetLibrary("test", rs)
-	err := vsphere.ImportOVAFromLibrary(rs, client, lib, filepath.Join(dir+fileName))
+	err = vsphere.ImportOVAFromLibrary(rs, client, lib, filepath.Join(dir+fileName))
 	if err != nil {
 		log.Errorf("%s", err)
 	}
 	vm, err := vsphere.DeployVmFromLibrary(rs, client, lib)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
 	vm.Name()
 	vsphere.MarkAsTemplate(client, "testing")
 	for _, vm := range vsphere.ListVms(client) {
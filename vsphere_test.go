@@ -8,13 +8,22 @@ import (
 	"github.com/karuppiah7890/tce-e2e-test/testutils/vsphere"
 
 	"github.com/karuppiah7890/tce-e2e-test/testutils/log"
+	"github.com/karuppiah7890/tce-e2e-test/testutils/scenario"
 )
 
 func TestManagementAndWorkloadCluster(t *testing.T) {
-	log.InitLogger("vsphere-mgmt-wkld-e2e")
+	logger := log.NewTestLogger(t, "vsphere-mgmt-wkld-e2e")
 
-	r := utils.DefaultClusterTestRunner{}
-	err := utils.RunProviderTest(vsphere.PROVIDER, r, tce.Package{})
+	ctx, cancel := utils.ContextForTest(t, utils.DefaultCleanupMargin)
+	defer cancel()
+
+	testScenario, err := scenario.FromEnv()
+	if err != nil {
+		t.Fatalf("Error while reading the test scenario: %v", err)
+	}
+
+	r := utils.DefaultClusterTestRunner{Logger: logger, Scenario: testScenario}
+	err = utils.RunProviderTest(ctx, vsphere.PROVIDER, r, tce.Package{})
 	if err != nil {
 		t.Errorf("Error while running E2E test for managed and workload cluster on vSphere: %v", err)
 	}